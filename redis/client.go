@@ -2,23 +2,73 @@ package redis
 
 import (
 	"context"
-	"errors"
+	crand "crypto/rand"
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"net"
 	"reflect"
+	"time"
 
 	"github.com/nilchaosky/go-nexus/nexus_utils"
 	"github.com/nilchaosky/go-nexus/serialize"
 	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
 )
 
 // Client 客户端包装结构体
-// 实现了 Generic、String、List、Set、Hash、ZSet 接口
+// 实现了 Generic、String、List、Set、Hash、ZSet、HyperLogLog、Bitmap、Geo 接口
 type Client struct {
 	redis.UniversalClient
+	serializer serialize.Serializer // 结构体序列化器（默认：serialize.JSONIter）
+	flight     *singleflight.Group  // 合并同一进程内相同key的并发缓存加载
+	prefix     string               // 键前缀，通过 WithPrefix 设置
+	codec      *valueCodec          // 值编码（头部与压缩），通过 CodecOption 启用
+	// decompressLimit 解压后数据的最大长度（字节），通过 DecompressLimitOption 设置
+	decompressLimit int64
+	// sessionLimits 每种设备类型允许同时在线的会话数量，通过 SessionLimitOption 设置
+	sessionLimits map[string]int
+}
+
+// ClientOption 客户端选项
+type ClientOption func(*Client)
+
+// SerializerOption 设置客户端使用的序列化器
+func SerializerOption(s serialize.Serializer) ClientOption {
+	return func(c *Client) {
+		if s != nil {
+			c.serializer = s
+		}
+	}
 }
 
 // NewClient 创建客户端
-func NewClient(client redis.UniversalClient) *Client {
-	return &Client{UniversalClient: client}
+func NewClient(client redis.UniversalClient, opts ...ClientOption) *Client {
+	c := &Client{
+		UniversalClient: client,
+		serializer:      serialize.JSONIter,
+		flight:          &singleflight.Group{},
+		decompressLimit: defaultDecompressLimit,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithSerializer 返回使用指定序列化器的客户端视图，与原客户端共享连接
+// 用于单次调用覆盖序列化器，例如：c.WithSerializer(serialize.Protobuf).GetStruct(ctx, key, &msg)
+func (c *Client) WithSerializer(s serialize.Serializer) *Client {
+	view := *c
+	if s != nil {
+		view.serializer = s
+	}
+	return &view
+}
+
+// Serializer 获取客户端使用的序列化器
+func (c *Client) Serializer() serialize.Serializer {
+	return c.serializer
 }
 
 // GetRawClient 获取原始客户端
@@ -49,16 +99,21 @@ func (c *Client) unmarshalValue(value interface{}, fn func() (string, error)) er
 	}
 
 	if data == "" {
-		return errors.New("键值为空")
+		return ErrEmptyValue
 	}
 
-	if err := serialize.JSONIter.Unmarshal([]byte(data), value); err != nil {
+	if err := c.decode([]byte(data), value); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// decode 使用客户端序列化器反序列化（自动识别值编码头部），失败时返回 ErrDecode
+func (c *Client) decode(data []byte, value interface{}) error {
+	return decodeError(c.unmarshal(data, value))
+}
+
 // unmarshalSlice 获取字符串切片并反序列化到结构体切片
 func (c *Client) unmarshalSlice(value interface{}, fn func() ([]string, error)) error {
 	rv, err := nexus_utils.IsSlice(value)
@@ -81,7 +136,7 @@ func (c *Client) unmarshalSlice(value interface{}, fn func() ([]string, error))
 		}
 
 		elemValue := reflect.New(elemType).Interface()
-		if err := serialize.JSONIter.Unmarshal([]byte(result), elemValue); err != nil {
+		if err := c.decode([]byte(result), elemValue); err != nil {
 			return err
 		}
 
@@ -92,3 +147,67 @@ func (c *Client) unmarshalSlice(value interface{}, fn func() ([]string, error))
 	rv.Set(newSlice)
 	return nil
 }
+
+// marshalValue 序列化写入值
+// 字符串、数字、布尔、时间等 go-redis 可直接写入的值原样返回，其余值（结构体、map、切片等）使用序列化器编码
+func (c *Client) marshalValue(value interface{}) (interface{}, error) {
+	switch value.(type) {
+	case nil, string, *string, []byte,
+		int, int8, int16, int32, int64, *int, *int8, *int16, *int32, *int64,
+		uint, uint8, uint16, uint32, uint64, *uint, *uint8, *uint16, *uint32, *uint64,
+		float32, float64, *float32, *float64, bool, *bool,
+		time.Time, *time.Time, time.Duration, *time.Duration,
+		encoding.BinaryMarshaler, net.IP:
+		return value, nil
+	}
+
+	data, err := c.marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("序列化失败: %w", err)
+	}
+	return data, nil
+}
+
+// marshalValues 批量序列化写入值
+func (c *Client) marshalValues(values []interface{}) ([]interface{}, error) {
+	results := make([]interface{}, len(values))
+	for i, value := range values {
+		data, err := c.marshalValue(value)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = data
+	}
+	return results, nil
+}
+
+// marshalPairs 序列化键值对参数中的值（奇数位置）
+// 仅有一个参数时（map、结构体等整体写入形式）原样返回，由 go-redis 处理
+func (c *Client) marshalPairs(values []interface{}) ([]interface{}, error) {
+	if len(values) <= 1 {
+		return values, nil
+	}
+
+	results := make([]interface{}, len(values))
+	for i, value := range values {
+		if i%2 == 0 {
+			results[i] = value
+			continue
+		}
+		data, err := c.marshalValue(value)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = data
+	}
+	return results, nil
+}
+
+// randomToken 生成随机令牌（32位十六进制）
+func randomToken() string {
+	b := make([]byte, 16)
+	if _, err := crand.Read(b); err != nil {
+		panic("生成随机令牌失败: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
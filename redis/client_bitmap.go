@@ -0,0 +1,65 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Bitmap 位图操作接口
+type Bitmap interface {
+	SetBit(ctx context.Context, key string, offset int64, value int) (int64, error)
+	GetBit(ctx context.Context, key string, offset int64) (int64, error)
+	BitCount(ctx context.Context, key string, bitCount *redis.BitCount) (int64, error)
+	BitPos(ctx context.Context, key string, bit int64, pos ...int64) (int64, error)
+	BitOpAnd(ctx context.Context, destination string, keys ...string) (int64, error)
+	BitOpOr(ctx context.Context, destination string, keys ...string) (int64, error)
+	BitOpXor(ctx context.Context, destination string, keys ...string) (int64, error)
+	BitOpNot(ctx context.Context, destination string, key string) (int64, error)
+	BitField(ctx context.Context, key string, values ...interface{}) ([]int64, error)
+}
+
+// SetBit 设置位图指定偏移量的位，返回原来的值
+func (c *Client) SetBit(ctx context.Context, key string, offset int64, value int) (int64, error) {
+	return wrapResult(c.UniversalClient.SetBit(ctx, c.key(key), offset, value).Result())
+}
+
+// GetBit 获取位图指定偏移量的位
+func (c *Client) GetBit(ctx context.Context, key string, offset int64) (int64, error) {
+	return wrapResult(c.UniversalClient.GetBit(ctx, c.key(key), offset).Result())
+}
+
+// BitCount 统计位图中值为1的位数量，bitCount 为nil时统计整个位图
+func (c *Client) BitCount(ctx context.Context, key string, bitCount *redis.BitCount) (int64, error) {
+	return wrapResult(c.UniversalClient.BitCount(ctx, c.key(key), bitCount).Result())
+}
+
+// BitPos 获取位图中第一个值为 bit 的位的偏移量，pos 为可选的起止字节位置
+func (c *Client) BitPos(ctx context.Context, key string, bit int64, pos ...int64) (int64, error) {
+	return wrapResult(c.UniversalClient.BitPos(ctx, c.key(key), bit, pos...).Result())
+}
+
+// BitOpAnd 对多个位图执行按位与，结果存储到目标键
+func (c *Client) BitOpAnd(ctx context.Context, destination string, keys ...string) (int64, error) {
+	return wrapResult(c.UniversalClient.BitOpAnd(ctx, c.key(destination), c.keys(keys)...).Result())
+}
+
+// BitOpOr 对多个位图执行按位或，结果存储到目标键
+func (c *Client) BitOpOr(ctx context.Context, destination string, keys ...string) (int64, error) {
+	return wrapResult(c.UniversalClient.BitOpOr(ctx, c.key(destination), c.keys(keys)...).Result())
+}
+
+// BitOpXor 对多个位图执行按位异或，结果存储到目标键
+func (c *Client) BitOpXor(ctx context.Context, destination string, keys ...string) (int64, error) {
+	return wrapResult(c.UniversalClient.BitOpXor(ctx, c.key(destination), c.keys(keys)...).Result())
+}
+
+// BitOpNot 对位图执行按位取反，结果存储到目标键
+func (c *Client) BitOpNot(ctx context.Context, destination string, key string) (int64, error) {
+	return wrapResult(c.UniversalClient.BitOpNot(ctx, c.key(destination), c.key(key)).Result())
+}
+
+// BitField 对位图执行 BITFIELD 子命令，如 "INCRBY", "u8", 0, 1
+func (c *Client) BitField(ctx context.Context, key string, values ...interface{}) ([]int64, error) {
+	return wrapResult(c.UniversalClient.BitField(ctx, c.key(key), values...).Result())
+}
@@ -0,0 +1,167 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"math"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// bloomMaxBits 单个位图的最大位数（Redis 字符串最大 512MB）
+const bloomMaxBits = 1 << 32
+
+// BloomFilter 基于位图的布隆过滤器，不依赖 RedisBloom 模块
+// 位数组大小与哈希函数数量根据预期元素数量和误判率计算，元素位置使用双重哈希生成
+// 同一过滤器的所有实例必须使用相同的预期元素数量和误判率
+type BloomFilter struct {
+	client *Client
+	key    string
+	bits   uint64 // 位数组大小
+	hashes uint64 // 哈希函数数量
+}
+
+// NewBloomFilter 创建布隆过滤器
+// expectedItems 为预期元素数量，falsePositiveRate 为期望误判率（0-1之间，如 0.01）
+func (c *Client) NewBloomFilter(key string, expectedItems uint64, falsePositiveRate float64) (*BloomFilter, error) {
+	if key == "" {
+		return nil, errors.New("键名不能为空")
+	}
+	if expectedItems == 0 {
+		return nil, errors.New("预期元素数量必须大于0")
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		return nil, errors.New("误判率必须在0和1之间")
+	}
+
+	bits, hashes := bloomSize(expectedItems, falsePositiveRate)
+	if bits > bloomMaxBits {
+		return nil, errors.New("位数组超过512MB，请降低预期元素数量或提高误判率")
+	}
+
+	return &BloomFilter{
+		client: c,
+		key:    key,
+		bits:   bits,
+		hashes: hashes,
+	}, nil
+}
+
+// bloomSize 计算位数组大小 m = -n·ln(p)/(ln2)² 与哈希函数数量 k = m/n·ln2
+func bloomSize(n uint64, p float64) (uint64, uint64) {
+	m := math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2))
+	k := math.Round(m / float64(n) * math.Ln2)
+	return uint64(m), uint64(max(k, 1))
+}
+
+// Bits 获取位数组大小
+func (b *BloomFilter) Bits() uint64 {
+	return b.bits
+}
+
+// Hashes 获取哈希函数数量
+func (b *BloomFilter) Hashes() uint64 {
+	return b.hashes
+}
+
+// Add 添加元素，元素此前可能不存在时返回true（存在误判，见 Exists）
+func (b *BloomFilter) Add(ctx context.Context, item string) (bool, error) {
+	added, err := b.AddMany(ctx, item)
+	if err != nil {
+		return false, err
+	}
+	return added[0], nil
+}
+
+// AddMany 批量添加元素，返回每个元素此前是否可能不存在
+func (b *BloomFilter) AddMany(ctx context.Context, items ...string) ([]bool, error) {
+	return b.exec(ctx, items, func(pipe redis.Pipeliner, key string, offset int64) *redis.IntCmd {
+		return pipe.SetBit(ctx, key, offset, 1)
+	}, func(old int64) bool {
+		return old == 0
+	})
+}
+
+// Exists 判断元素是否可能存在：返回false时一定不存在，返回true时按误判率可能不存在
+func (b *BloomFilter) Exists(ctx context.Context, item string) (bool, error) {
+	exists, err := b.ExistsMany(ctx, item)
+	if err != nil {
+		return false, err
+	}
+	return exists[0], nil
+}
+
+// ExistsMany 批量判断元素是否可能存在
+func (b *BloomFilter) ExistsMany(ctx context.Context, items ...string) ([]bool, error) {
+	results, err := b.exec(ctx, items, func(pipe redis.Pipeliner, key string, offset int64) *redis.IntCmd {
+		return pipe.GetBit(ctx, key, offset)
+	}, func(bit int64) bool {
+		return bit == 0
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		results[i] = !results[i]
+	}
+	return results, nil
+}
+
+// Clear 删除过滤器
+func (b *BloomFilter) Clear(ctx context.Context) error {
+	_, err := b.client.Del(ctx, b.key)
+	return err
+}
+
+// exec 在管道中对每个元素的所有位执行命令，任一位满足 match 时该元素结果为true
+func (b *BloomFilter) exec(ctx context.Context, items []string, fn func(pipe redis.Pipeliner, key string, offset int64) *redis.IntCmd, match func(int64) bool) ([]bool, error) {
+	if len(items) == 0 {
+		return nil, errors.New("元素不能为空")
+	}
+
+	key := b.client.key(b.key)
+	pipe := b.client.UniversalClient.Pipeline()
+	cmds := make([][]*redis.IntCmd, len(items))
+	for i, item := range items {
+		cmds[i] = make([]*redis.IntCmd, 0, b.hashes)
+		for _, offset := range b.offsets(item) {
+			cmds[i] = append(cmds[i], fn(pipe, key, offset))
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	results := make([]bool, len(items))
+	for i, itemCmds := range cmds {
+		for _, cmd := range itemCmds {
+			if match(cmd.Val()) {
+				results[i] = true
+				break
+			}
+		}
+	}
+	return results, nil
+}
+
+// offsets 使用双重哈希计算元素对应的位偏移量：h1 + i·h2 (mod m)
+func (b *BloomFilter) offsets(item string) []int64 {
+	h := fnv.New128a()
+	_, _ = h.Write([]byte(item))
+	sum := h.Sum(nil)
+
+	var h1, h2 uint64
+	for i := 0; i < 8; i++ {
+		h1 = h1<<8 | uint64(sum[i])
+		h2 = h2<<8 | uint64(sum[i+8])
+	}
+	h2 |= 1
+
+	offsets := make([]int64, b.hashes)
+	for i := uint64(0); i < b.hashes; i++ {
+		offsets[i] = int64((h1 + i*h2) % b.bits)
+	}
+	return offsets
+}
@@ -0,0 +1,80 @@
+package redis_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/nilchaosky/go-nexus/redis/redistest"
+)
+
+// TestBloomFilter 测试布隆过滤器的参数计算、添加、判断与误判率
+func TestBloomFilter(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+
+	if _, err := client.NewBloomFilter("bf", 0, 0.01); err == nil {
+		t.Error("期望预期元素数量为0时返回错误")
+	}
+	if _, err := client.NewBloomFilter("bf", 100, 1); err == nil {
+		t.Error("期望误判率不在0和1之间时返回错误")
+	}
+
+	bf, err := client.NewBloomFilter("bf", 1000, 0.01)
+	if err != nil {
+		t.Fatalf("NewBloomFilter失败: %v", err)
+	}
+	if bf.Bits() != 9586 || bf.Hashes() != 7 {
+		t.Errorf("期望位数9586、哈希函数7个，实际: %d, %d", bf.Bits(), bf.Hashes())
+	}
+
+	if added, err := bf.Add(ctx, "a"); err != nil || !added {
+		t.Fatalf("期望首次添加返回true，实际: %v, %v", added, err)
+	}
+	if added, _ := bf.Add(ctx, "a"); added {
+		t.Error("期望重复添加返回false")
+	}
+
+	items := make([]string, 1000)
+	for i := range items {
+		items[i] = "user:" + strconv.Itoa(i)
+	}
+	if _, err := bf.AddMany(ctx, items...); err != nil {
+		t.Fatalf("AddMany失败: %v", err)
+	}
+	exists, err := bf.ExistsMany(ctx, items...)
+	if err != nil {
+		t.Fatalf("ExistsMany失败: %v", err)
+	}
+	for i, ok := range exists {
+		if !ok {
+			t.Fatalf("已添加的元素 %s 判断为不存在", items[i])
+		}
+	}
+
+	// 未添加的元素误判率应接近1%
+	others := make([]string, 1000)
+	for i := range others {
+		others[i] = "guest:" + strconv.Itoa(i)
+	}
+	exists, _ = bf.ExistsMany(ctx, others...)
+	falsePositives := 0
+	for _, ok := range exists {
+		if ok {
+			falsePositives++
+		}
+	}
+	if falsePositives > 30 {
+		t.Errorf("误判数量过多: %d/1000", falsePositives)
+	}
+
+	if err := bf.Clear(ctx); err != nil {
+		t.Fatalf("Clear失败: %v", err)
+	}
+	if ok, _ := bf.Exists(ctx, "a"); ok {
+		t.Error("期望清空后元素不存在")
+	}
+	if _, err := bf.ExistsMany(ctx); err == nil {
+		t.Error("期望元素为空时返回错误")
+	}
+}
@@ -0,0 +1,250 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"reflect"
+	"time"
+
+	"github.com/nilchaosky/go-nexus/logz"
+	"github.com/nilchaosky/go-nexus/nexus_utils"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// cacheNotFoundValue 空值标记，表示数据源中不存在该数据
+const cacheNotFoundValue = "\x00NEXUS:NOT_FOUND"
+
+// cacheWaitInterval 等待其他进程加载时的轮询间隔
+const cacheWaitInterval = 50 * time.Millisecond
+
+// cacheUnlockScript 释放加载锁：仅在锁仍由当前加载者持有时删除
+// KEYS[1] 加载锁键 ARGV[1] 加载者令牌
+var cacheUnlockScript = redis.NewScript(`
+if redis.call('get', KEYS[1]) == ARGV[1] then
+	return redis.call('del', KEYS[1])
+end
+return 0
+`)
+
+// CacheOption 缓存选项
+type CacheOption func(*cacheOptions)
+
+// cacheOptions 缓存选项
+type cacheOptions struct {
+	jitter      float64       // 过期时间随机抖动比例
+	notFoundTTL time.Duration // 空值缓存时间
+	lockTTL     time.Duration // 跨进程加载锁过期时间
+}
+
+// defaultCacheOptions 返回默认缓存选项
+func defaultCacheOptions() cacheOptions {
+	return cacheOptions{
+		jitter:      0.1,
+		notFoundTTL: 30 * time.Second,
+	}
+}
+
+// CacheJitter 设置过期时间随机抖动比例，实际过期时间为 expiration*(1+[0,ratio))（默认：0.1，0为不抖动）
+func CacheJitter(ratio float64) CacheOption {
+	return func(o *cacheOptions) {
+		if ratio >= 0 {
+			o.jitter = ratio
+		}
+	}
+}
+
+// CacheNotFoundTTL 设置数据不存在时空值标记的缓存时间（默认：30秒，0为不缓存空值）
+func CacheNotFoundTTL(ttl time.Duration) CacheOption {
+	return func(o *cacheOptions) {
+		if ttl >= 0 {
+			o.notFoundTTL = ttl
+		}
+	}
+}
+
+// CacheLock 启用跨进程加载锁，同一时刻只有一个进程执行fn，其余进程等待结果（默认：不启用）
+// ttl 为加载锁（key + ":loading"，SET NX PX）的过期时间，也是其他进程的最长等待时间，超时后自行加载
+func CacheLock(ttl time.Duration) CacheOption {
+	return func(o *cacheOptions) {
+		if ttl > 0 {
+			o.lockTTL = ttl
+		}
+	}
+}
+
+// cacheLoaded 加载结果
+type cacheLoaded struct {
+	data     []byte // 序列化后的数据
+	notFound bool   // 数据是否不存在
+	hit      bool   // 是否从缓存读取
+}
+
+// Cache 缓存方法：先从缓存获取，如果不存在则执行fn函数获取数据并缓存
+// 返回值 hit 为 true 表示从缓存读取，false 表示执行了fn加载
+// 同一进程内相同key的并发加载只执行一次fn；fn返回 ErrNotFound 或 nil 时缓存空值标记并返回 ErrNotFound
+// Redis异常时降级为直接加载：读取失败时执行fn，写入缓存失败时记录日志并返回加载的数据
+// 等待加载期间 ctx 结束时立即返回 ctx 的错误，加载本身不受影响，结果仍会写入缓存
+func (c *Client) Cache(ctx context.Context, key string, value interface{}, expiration time.Duration, fn func() (interface{}, error), opts ...CacheOption) (bool, error) {
+	if _, err := nexus_utils.IsPointer(value); err != nil {
+		return false, err
+	}
+
+	loaded, err := c.cacheFetch(ctx, key, expiration, fn, opts)
+	if err != nil {
+		return false, err
+	}
+
+	return loaded.hit, c.cacheDecode(loaded, value)
+}
+
+// cacheFetch 从缓存获取数据，不存在时加载并写入缓存，返回序列化后的数据
+func (c *Client) cacheFetch(ctx context.Context, key string, expiration time.Duration, fn func() (interface{}, error), opts []CacheOption) (*cacheLoaded, error) {
+	o := defaultCacheOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// 先从缓存获取，Redis异常时降级为直接加载
+	data, err := c.UniversalClient.Get(ctx, c.key(key)).Bytes()
+	if err == nil {
+		return &cacheLoaded{data: data, notFound: string(data) == cacheNotFoundValue, hit: true}, nil
+	}
+
+	// 缓存不存在，合并同一进程内的并发加载；加载不受单个调用方取消的影响
+	loadCtx := context.WithoutCancel(ctx)
+	ch := c.flight.DoChan(c.key(key), func() (interface{}, error) {
+		return c.cacheLoad(loadCtx, key, expiration, fn, &o)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-ch:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*cacheLoaded), nil
+	}
+}
+
+// cacheLoad 加载数据并写入缓存
+func (c *Client) cacheLoad(ctx context.Context, key string, expiration time.Duration, fn func() (interface{}, error), o *cacheOptions) (*cacheLoaded, error) {
+	// 跨进程加载锁：未获取到锁时等待其他进程写入缓存
+	if o.lockTTL > 0 {
+		lockKey, token := c.key(key+":loading"), randomToken()
+		ok, err := c.UniversalClient.SetNX(ctx, lockKey, token, o.lockTTL).Result()
+		if err == nil && ok {
+			defer func() {
+				_ = cacheUnlockScript.Run(ctx, c.UniversalClient, []string{lockKey}, token).Err()
+			}()
+			// 获取锁后再次检查缓存，避免重复加载
+			if data, err := c.UniversalClient.Get(ctx, c.key(key)).Bytes(); err == nil {
+				return &cacheLoaded{data: data, notFound: string(data) == cacheNotFoundValue, hit: true}, nil
+			}
+		} else if err == nil {
+			if loaded := c.cacheWait(ctx, key, o.lockTTL); loaded != nil {
+				return loaded, nil
+			}
+		}
+	}
+
+	result, err := fn()
+	if errors.Is(err, ErrNotFound) || (err == nil && isNilValue(result)) {
+		if o.notFoundTTL > 0 {
+			if err := c.UniversalClient.Set(ctx, c.key(key), cacheNotFoundValue, o.notFoundTTL).Err(); err != nil {
+				cacheSetFailed(c.key(key), err)
+			}
+		}
+		return &cacheLoaded{notFound: true}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 序列化并缓存数据
+	data, err := c.marshal(result)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.UniversalClient.Set(ctx, c.key(key), data, jitterTTL(expiration, o.jitter)).Err(); err != nil {
+		cacheSetFailed(c.key(key), err)
+	}
+
+	return &cacheLoaded{data: data}, nil
+}
+
+// cacheSetFailed 记录写入缓存失败，加载的数据仍返回给调用方
+func cacheSetFailed(key string, err error) {
+	logz.Logger.Warn("写入缓存失败",
+		zap.String("key", key),
+		zap.Error(err),
+	)
+}
+
+// cacheWait 等待其他进程写入缓存，超时、ctx结束或加载锁已释放但未写入缓存时返回nil
+func (c *Client) cacheWait(ctx context.Context, key string, timeout time.Duration) *cacheLoaded {
+	lockKey := c.key(key + ":loading")
+	deadline := time.Now().Add(timeout)
+	timer := time.NewTimer(cacheWaitInterval)
+	defer timer.Stop()
+
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timer.C:
+		}
+		timer.Reset(cacheWaitInterval)
+
+		data, err := c.UniversalClient.Get(ctx, c.key(key)).Bytes()
+		if err == nil {
+			return &cacheLoaded{data: data, notFound: string(data) == cacheNotFoundValue, hit: true}
+		}
+		if !errors.Is(err, redis.Nil) {
+			return nil
+		}
+
+		// 加载锁已释放但缓存仍不存在（加载失败或数据不存在且未缓存空值），不再等待
+		if n, err := c.UniversalClient.Exists(ctx, lockKey).Result(); err != nil || n == 0 {
+			// 持有者可能在两次读取之间写入缓存并释放锁，再读取一次
+			if data, err := c.UniversalClient.Get(ctx, c.key(key)).Bytes(); err == nil {
+				return &cacheLoaded{data: data, notFound: string(data) == cacheNotFoundValue, hit: true}
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// cacheDecode 将加载结果反序列化到value
+func (c *Client) cacheDecode(loaded *cacheLoaded, value interface{}) error {
+	if loaded.notFound {
+		return ErrNotFound
+	}
+	return c.decode(loaded.data, value)
+}
+
+// jitterTTL 为过期时间增加随机抖动，避免大量键同时过期
+func jitterTTL(expiration time.Duration, ratio float64) time.Duration {
+	if expiration <= 0 || ratio <= 0 {
+		return expiration
+	}
+	return expiration + time.Duration(rand.Float64()*ratio*float64(expiration))
+}
+
+// isNilValue 判断值是否为nil（包括nil指针、map、切片、接口）
+func isNilValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return false
+	}
+}
@@ -0,0 +1,447 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nilchaosky/go-nexus/logz"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// delayQueueKeyPrefix 延迟队列键前缀
+const delayQueueKeyPrefix = "DELAYQUEUE:"
+
+// ErrJobNotClaimed 任务未被认领，或已因可见性超时被重新投递
+var ErrJobNotClaimed = errors.New("任务未被认领或已超时")
+
+var (
+	// delayClaimScript 认领到期任务
+	// 先将可见性超时的处理中任务放回就绪集合，再将到期任务移入处理中集合并递增尝试次数
+	// 尝试次数已用尽的超时任务、递增后超过最大尝试次数的任务直接转入死信集合
+	// KEYS[1] 就绪集合 KEYS[2] 处理中集合 KEYS[3] 任务内容哈希 KEYS[4] 尝试次数哈希 KEYS[5] 死信集合
+	// ARGV[1] 当前时间（毫秒） ARGV[2] 认领数量 ARGV[3] 可见性超时（毫秒） ARGV[4] 最大尝试次数（0为不限制）
+	// 返回 {id1, payload1, attempts1, id2, ...}
+	delayClaimScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local maxAttempts = tonumber(ARGV[4])
+local expired = redis.call('zrangebyscore', KEYS[2], '-inf', now, 'limit', 0, 100)
+for _, id in ipairs(expired) do
+	redis.call('zrem', KEYS[2], id)
+	local attempts = tonumber(redis.call('hget', KEYS[4], id) or '0')
+	if maxAttempts > 0 and attempts >= maxAttempts then
+		redis.call('zadd', KEYS[5], now, id)
+	else
+		redis.call('zadd', KEYS[1], now, id)
+	end
+end
+local ids = redis.call('zrangebyscore', KEYS[1], '-inf', now, 'limit', 0, tonumber(ARGV[2]))
+local result = {}
+for _, id in ipairs(ids) do
+	redis.call('zrem', KEYS[1], id)
+	local payload = redis.call('hget', KEYS[3], id)
+	if payload then
+		local attempts = redis.call('hincrby', KEYS[4], id, 1)
+		if maxAttempts > 0 and attempts > maxAttempts then
+			redis.call('zadd', KEYS[5], now, id)
+		else
+			redis.call('zadd', KEYS[2], now + tonumber(ARGV[3]), id)
+			table.insert(result, id)
+			table.insert(result, payload)
+			table.insert(result, attempts)
+		end
+	end
+end
+return result
+`)
+
+	// delayAckScript 确认任务并删除任务数据
+	// KEYS[1] 处理中集合 KEYS[2] 任务内容哈希 KEYS[3] 尝试次数哈希 ARGV[1] 任务ID
+	delayAckScript = redis.NewScript(`
+if redis.call('zrem', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('hdel', KEYS[2], ARGV[1])
+redis.call('hdel', KEYS[3], ARGV[1])
+return 1
+`)
+
+	// delayCancelScript 取消就绪集合中的任务，仅在任务确实被移除时删除任务数据
+	// KEYS[1] 就绪集合 KEYS[2] 任务内容哈希 KEYS[3] 尝试次数哈希 ARGV[1] 任务ID
+	delayCancelScript = redis.NewScript(`
+if redis.call('zrem', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('hdel', KEYS[2], ARGV[1])
+redis.call('hdel', KEYS[3], ARGV[1])
+return 1
+`)
+
+	// delayRetryScript 将处理中的任务移回就绪集合或死信集合
+	// KEYS[1] 处理中集合 KEYS[2] 目标集合 ARGV[1] 任务ID ARGV[2] 目标分数（毫秒）
+	delayRetryScript = redis.NewScript(`
+if redis.call('zrem', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('zadd', KEYS[2], ARGV[2], ARGV[1])
+return 1
+`)
+)
+
+// DelayQueueOption 延迟队列选项
+type DelayQueueOption func(*delayQueueOptions)
+
+// delayQueueOptions 延迟队列选项
+type delayQueueOptions struct {
+	visibility   time.Duration // 可见性超时
+	minBackoff   time.Duration // 最小重试间隔
+	maxBackoff   time.Duration // 最大重试间隔
+	maxAttempts  int64         // 最大尝试次数
+	pollInterval time.Duration // 无任务时的轮询间隔
+	concurrency  int           // 并发处理的协程数
+	batch        int           // 单次认领数量
+}
+
+// DelayQueueVisibility 设置可见性超时（默认：30秒），认领后超时未确认的任务会被重新投递
+func DelayQueueVisibility(d time.Duration) DelayQueueOption {
+	return func(o *delayQueueOptions) {
+		if d > 0 {
+			o.visibility = d
+		}
+	}
+}
+
+// DelayQueueBackoff 设置失败重试的指数退避范围（默认：1秒 - 10分钟）
+func DelayQueueBackoff(minBackoff, maxBackoff time.Duration) DelayQueueOption {
+	return func(o *delayQueueOptions) {
+		if minBackoff > 0 {
+			o.minBackoff = minBackoff
+		}
+		if maxBackoff >= o.minBackoff {
+			o.maxBackoff = maxBackoff
+		}
+	}
+}
+
+// DelayQueueMaxAttempts 设置最大尝试次数，超过后任务转入死信集合（默认：10，0为不限制）
+// 处理失败与可见性超时均计入尝试次数
+func DelayQueueMaxAttempts(n int64) DelayQueueOption {
+	return func(o *delayQueueOptions) {
+		if n >= 0 {
+			o.maxAttempts = n
+		}
+	}
+}
+
+// DelayQueuePollInterval 设置无到期任务时的轮询间隔（默认：1秒）
+func DelayQueuePollInterval(d time.Duration) DelayQueueOption {
+	return func(o *delayQueueOptions) {
+		if d > 0 {
+			o.pollInterval = d
+		}
+	}
+}
+
+// DelayQueueConcurrency 设置 Run 并发处理任务的协程数（默认：4）
+func DelayQueueConcurrency(n int) DelayQueueOption {
+	return func(o *delayQueueOptions) {
+		if n > 0 {
+			o.concurrency = n
+		}
+	}
+}
+
+// DelayJob 延迟任务
+type DelayJob[T any] struct {
+	ID       string // 任务ID
+	Payload  T      // 反序列化后的任务内容
+	Attempts int64  // 尝试次数（含本次）
+}
+
+// DelayHandler 延迟任务处理函数，返回nil时确认任务，返回错误时按退避时间重试
+type DelayHandler[T any] func(ctx context.Context, job *DelayJob[T]) error
+
+// DelayQueue 基于有序集合的延迟队列
+// 任务按执行时间存入就绪集合，到期后通过Lua脚本原子认领并移入处理中集合（分数为可见性截止时间）；
+// 确认前超时的任务会被重新投递，失败的任务按指数退避重新调度，超过最大尝试次数后转入死信集合
+// 所有键使用 {name} 哈希标签，集群模式下位于同一槽位
+type DelayQueue[T any] struct {
+	client     *Client
+	name       string
+	ready      string
+	processing string
+	dead       string
+	jobs       string
+	attempts   string
+	opts       delayQueueOptions
+}
+
+// NewDelayQueue 创建延迟队列
+func NewDelayQueue[T any](c *Client, name string, opts ...DelayQueueOption) (*DelayQueue[T], error) {
+	if name == "" {
+		return nil, errors.New("队列名称不能为空")
+	}
+
+	o := delayQueueOptions{
+		visibility:   30 * time.Second,
+		minBackoff:   time.Second,
+		maxBackoff:   10 * time.Minute,
+		maxAttempts:  10,
+		pollInterval: time.Second,
+		concurrency:  4,
+		batch:        10,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	prefix := c.key(delayQueueKeyPrefix + "{" + name + "}:")
+	return &DelayQueue[T]{
+		client:     c,
+		name:       name,
+		ready:      prefix + "ready",
+		processing: prefix + "processing",
+		dead:       prefix + "dead",
+		jobs:       prefix + "jobs",
+		attempts:   prefix + "attempts",
+		opts:       o,
+	}, nil
+}
+
+// Schedule 调度任务在指定时间执行，返回任务ID
+func (q *DelayQueue[T]) Schedule(ctx context.Context, payload T, at time.Time) (string, error) {
+	data, err := encodeAs(q.client, payload)
+	if err != nil {
+		return "", err
+	}
+
+	id := randomToken()
+	err = q.client.TxPipeline(ctx, func(p *Pipe) error {
+		p.Raw().HSet(ctx, q.jobs, id, data)
+		p.Raw().ZAdd(ctx, q.ready, redis.Z{Score: float64(at.UnixMilli()), Member: id})
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("调度任务失败: %w", err)
+	}
+
+	return id, nil
+}
+
+// Delay 调度任务在delay之后执行，返回任务ID
+func (q *DelayQueue[T]) Delay(ctx context.Context, payload T, delay time.Duration) (string, error) {
+	return q.Schedule(ctx, payload, time.Now().Add(delay))
+}
+
+// Cancel 取消尚未认领的任务，任务不存在或已被认领时返回false
+func (q *DelayQueue[T]) Cancel(ctx context.Context, id string) (bool, error) {
+	return delayCancelScript.Run(ctx, q.client.UniversalClient,
+		[]string{q.ready, q.jobs, q.attempts}, id).Bool()
+}
+
+// Claim 认领最多n个到期任务，任务在可见性超时内需通过 Ack 确认或 Retry 重新调度
+// 无法反序列化的任务、因可见性超时耗尽尝试次数的任务直接转入死信集合
+func (q *DelayQueue[T]) Claim(ctx context.Context, n int) ([]*DelayJob[T], error) {
+	if n <= 0 {
+		return nil, errors.New("认领数量必须大于0")
+	}
+
+	keys := []string{q.ready, q.processing, q.jobs, q.attempts, q.dead}
+	values, err := delayClaimScript.Run(ctx, q.client.UniversalClient, keys,
+		time.Now().UnixMilli(), n, q.opts.visibility.Milliseconds(), q.opts.maxAttempts).Slice()
+	if err != nil {
+		return nil, fmt.Errorf("认领任务失败: %w", err)
+	}
+
+	jobs := make([]*DelayJob[T], 0, len(values)/3)
+	for i := 0; i+2 < len(values); i += 3 {
+		id, _ := values[i].(string)
+		data, _ := values[i+1].(string)
+		attempts, _ := values[i+2].(int64)
+
+		payload, err := decodeAs[T](q.client, data)
+		if err != nil {
+			logz.Logger.Warn("延迟任务反序列化失败，已转入死信集合",
+				zap.String("queue", q.name),
+				zap.String("id", id),
+				zap.Error(err),
+			)
+			_ = q.bury(ctx, id)
+			continue
+		}
+
+		jobs = append(jobs, &DelayJob[T]{ID: id, Payload: payload, Attempts: attempts})
+	}
+
+	return jobs, nil
+}
+
+// Ack 确认任务完成并删除任务数据
+// 任务已因可见性超时被重新投递时返回 ErrJobNotClaimed
+func (q *DelayQueue[T]) Ack(ctx context.Context, id string) error {
+	ok, err := delayAckScript.Run(ctx, q.client.UniversalClient,
+		[]string{q.processing, q.jobs, q.attempts}, id).Bool()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrJobNotClaimed
+	}
+	return nil
+}
+
+// Retry 按指数退避重新调度处理失败的任务，超过最大尝试次数时转入死信集合
+func (q *DelayQueue[T]) Retry(ctx context.Context, job *DelayJob[T]) error {
+	if q.opts.maxAttempts > 0 && job.Attempts >= q.opts.maxAttempts {
+		return q.bury(ctx, job.ID)
+	}
+
+	at := time.Now().Add(q.backoff(job.Attempts))
+	return q.move(ctx, job.ID, q.ready, at)
+}
+
+// Len 获取等待中（含未到期）的任务数量
+func (q *DelayQueue[T]) Len(ctx context.Context) (int64, error) {
+	return q.client.UniversalClient.ZCard(ctx, q.ready).Result()
+}
+
+// Dead 获取死信集合中的任务ID
+func (q *DelayQueue[T]) Dead(ctx context.Context) ([]string, error) {
+	return q.client.UniversalClient.ZRange(ctx, q.dead, 0, -1).Result()
+}
+
+// Run 循环认领并处理到期任务，阻塞直到ctx结束；返回前等待正在处理的任务完成
+// 处理函数返回错误或panic时任务按退避时间重试
+func (q *DelayQueue[T]) Run(ctx context.Context, handler DelayHandler[T]) error {
+	if handler == nil {
+		return errors.New("任务处理函数不能为空")
+	}
+
+	// 处理函数不受停止信号影响，停止时等待其完成
+	handlerCtx := context.WithoutCancel(ctx)
+	slots := make(chan struct{}, q.opts.concurrency)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for ctx.Err() == nil {
+		free := q.opts.concurrency - len(slots)
+		if free == 0 {
+			// 等待空闲协程
+			select {
+			case slots <- struct{}{}:
+				<-slots
+			case <-ctx.Done():
+			}
+			continue
+		}
+
+		jobs, err := q.Claim(ctx, min(free, q.opts.batch))
+		if err != nil && ctx.Err() == nil {
+			logz.Logger.Warn("认领延迟任务失败",
+				zap.String("queue", q.name),
+				zap.Error(err),
+			)
+		}
+		if len(jobs) == 0 {
+			q.sleep(ctx, q.opts.pollInterval)
+			continue
+		}
+
+		for _, job := range jobs {
+			slots <- struct{}{}
+			wg.Add(1)
+			go func(job *DelayJob[T]) {
+				defer wg.Done()
+				defer func() { <-slots }()
+				q.handle(handlerCtx, job, handler)
+			}(job)
+		}
+	}
+
+	return nil
+}
+
+// handle 执行处理函数，成功时确认任务，失败或panic时重试
+func (q *DelayQueue[T]) handle(ctx context.Context, job *DelayJob[T], handler DelayHandler[T]) {
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logz.Logger.Error("延迟任务处理异常",
+					zap.String("queue", q.name),
+					zap.String("id", job.ID),
+					zap.Any("panic", r),
+					zap.Stack("stack"),
+				)
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return handler(ctx, job)
+	}()
+
+	if err == nil {
+		if err := q.Ack(ctx, job.ID); err != nil {
+			logz.Logger.Warn("确认延迟任务失败",
+				zap.String("queue", q.name),
+				zap.String("id", job.ID),
+				zap.Error(err),
+			)
+		}
+		return
+	}
+
+	logz.Logger.Warn("延迟任务处理失败",
+		zap.String("queue", q.name),
+		zap.String("id", job.ID),
+		zap.Int64("attempts", job.Attempts),
+		zap.Error(err),
+	)
+	if err := q.Retry(ctx, job); err != nil {
+		logz.Logger.Warn("重试延迟任务失败",
+			zap.String("queue", q.name),
+			zap.String("id", job.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+// bury 将处理中的任务转入死信集合，任务数据保留
+func (q *DelayQueue[T]) bury(ctx context.Context, id string) error {
+	return q.move(ctx, id, q.dead, time.Now())
+}
+
+// move 将处理中的任务移入目标集合
+func (q *DelayQueue[T]) move(ctx context.Context, id, target string, at time.Time) error {
+	ok, err := delayRetryScript.Run(ctx, q.client.UniversalClient,
+		[]string{q.processing, target}, id, strconv.FormatInt(at.UnixMilli(), 10)).Bool()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrJobNotClaimed
+	}
+	return nil
+}
+
+// backoff 计算第attempts次失败后的重试间隔
+func (q *DelayQueue[T]) backoff(attempts int64) time.Duration {
+	backoff := q.opts.minBackoff
+	for i := int64(1); i < attempts && backoff < q.opts.maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, q.opts.maxBackoff)
+}
+
+// sleep 等待指定时间或ctx结束
+func (q *DelayQueue[T]) sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
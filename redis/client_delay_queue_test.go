@@ -0,0 +1,205 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nilchaosky/go-nexus/redis"
+	"github.com/nilchaosky/go-nexus/redis/redistest"
+)
+
+// delayTask 延迟队列测试任务
+type delayTask struct {
+	Order string `json:"order"`
+}
+
+// TestDelayQueueClaim 测试只认领到期任务、取消未认领任务以及重复确认
+func TestDelayQueueClaim(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+
+	queue, err := redis.NewDelayQueue[delayTask](client, "orders")
+	if err != nil {
+		t.Fatalf("NewDelayQueue失败: %v", err)
+	}
+
+	due, err := queue.Schedule(ctx, delayTask{Order: "A"}, time.Now().Add(-time.Second))
+	if err != nil {
+		t.Fatalf("Schedule失败: %v", err)
+	}
+	later, err := queue.Delay(ctx, delayTask{Order: "B"}, time.Hour)
+	if err != nil {
+		t.Fatalf("Delay失败: %v", err)
+	}
+
+	jobs, err := queue.Claim(ctx, 10)
+	if err != nil {
+		t.Fatalf("Claim失败: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != due || jobs[0].Payload.Order != "A" || jobs[0].Attempts != 1 {
+		t.Fatalf("期望只认领到期任务A，实际: %+v", jobs)
+	}
+	if n, _ := queue.Len(ctx); n != 1 {
+		t.Errorf("期望剩余1个等待任务，实际: %d", n)
+	}
+
+	// 已认领的任务不能取消，未认领的任务可以取消
+	if ok, err := queue.Cancel(ctx, due); err != nil || ok {
+		t.Errorf("期望已认领任务取消失败，实际: %v, %v", ok, err)
+	}
+	if ok, err := queue.Cancel(ctx, later); err != nil || !ok {
+		t.Errorf("期望取消任务B成功，实际: %v, %v", ok, err)
+	}
+	if n, _ := queue.Len(ctx); n != 0 {
+		t.Errorf("期望取消后没有等待任务，实际: %d", n)
+	}
+
+	if err := queue.Ack(ctx, due); err != nil {
+		t.Fatalf("Ack失败: %v", err)
+	}
+	if err := queue.Ack(ctx, due); !errors.Is(err, redis.ErrJobNotClaimed) {
+		t.Errorf("期望重复确认返回ErrJobNotClaimed，实际: %v", err)
+	}
+	if _, err := queue.Claim(ctx, 0); err == nil {
+		t.Error("期望认领数量为0时返回错误")
+	}
+}
+
+// TestDelayQueueRetry 测试失败任务按退避时间重新调度，尝试次数用尽后转入死信集合
+func TestDelayQueueRetry(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+
+	queue, _ := redis.NewDelayQueue[delayTask](client, "retry",
+		redis.DelayQueueMaxAttempts(2),
+		redis.DelayQueueBackoff(50*time.Millisecond, 50*time.Millisecond),
+	)
+	id, err := queue.Delay(ctx, delayTask{Order: "A"}, 0)
+	if err != nil {
+		t.Fatalf("Delay失败: %v", err)
+	}
+
+	jobs, _ := queue.Claim(ctx, 1)
+	if len(jobs) != 1 {
+		t.Fatalf("期望认领1个任务，实际: %d", len(jobs))
+	}
+	if err := queue.Retry(ctx, jobs[0]); err != nil {
+		t.Fatalf("Retry失败: %v", err)
+	}
+
+	// 退避期间不可认领
+	if jobs, _ := queue.Claim(ctx, 1); len(jobs) != 0 {
+		t.Fatalf("期望退避期间没有到期任务，实际: %d", len(jobs))
+	}
+	time.Sleep(60 * time.Millisecond)
+	jobs, _ = queue.Claim(ctx, 1)
+	if len(jobs) != 1 || jobs[0].Attempts != 2 {
+		t.Fatalf("期望第2次认领，实际: %+v", jobs)
+	}
+
+	if err := queue.Retry(ctx, jobs[0]); err != nil {
+		t.Fatalf("Retry失败: %v", err)
+	}
+	if dead, _ := queue.Dead(ctx); len(dead) != 1 || dead[0] != id {
+		t.Errorf("期望尝试次数用尽后转入死信集合，实际: %v", dead)
+	}
+	if n, _ := queue.Len(ctx); n != 0 {
+		t.Errorf("期望没有等待任务，实际: %d", n)
+	}
+}
+
+// TestDelayQueueVisibility 测试可见性超时后重新投递，超时同样计入尝试次数
+func TestDelayQueueVisibility(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+
+	queue, _ := redis.NewDelayQueue[delayTask](client, "stuck",
+		redis.DelayQueueMaxAttempts(2),
+		redis.DelayQueueVisibility(30*time.Millisecond),
+	)
+	id, _ := queue.Delay(ctx, delayTask{Order: "A"}, 0)
+
+	if jobs, _ := queue.Claim(ctx, 1); len(jobs) != 1 || jobs[0].Attempts != 1 {
+		t.Fatalf("期望第1次认领，实际: %+v", jobs)
+	}
+	if jobs, _ := queue.Claim(ctx, 1); len(jobs) != 0 {
+		t.Fatal("期望可见性超时前不会重新投递")
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if jobs, _ := queue.Claim(ctx, 1); len(jobs) != 1 || jobs[0].ID != id || jobs[0].Attempts != 2 {
+		t.Fatalf("期望超时后重新投递，实际: %+v", jobs)
+	}
+
+	// 第2次同样超时，尝试次数已用尽
+	time.Sleep(40 * time.Millisecond)
+	if jobs, _ := queue.Claim(ctx, 1); len(jobs) != 0 {
+		t.Fatalf("期望尝试次数用尽后不再投递，实际: %+v", jobs)
+	}
+	if dead, _ := queue.Dead(ctx); len(dead) != 1 || dead[0] != id {
+		t.Errorf("期望转入死信集合，实际: %v", dead)
+	}
+	if err := queue.Ack(ctx, id); !errors.Is(err, redis.ErrJobNotClaimed) {
+		t.Errorf("期望超时任务确认返回ErrJobNotClaimed，实际: %v", err)
+	}
+}
+
+// TestDelayQueueRun 测试 Run 处理任务，panic 后按退避时间重试并最终确认
+func TestDelayQueueRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client, _ := redistest.NewClient(t)
+
+	queue, _ := redis.NewDelayQueue[delayTask](client, "run",
+		redis.DelayQueueBackoff(10*time.Millisecond, 10*time.Millisecond),
+		redis.DelayQueuePollInterval(10*time.Millisecond),
+	)
+	if _, err := queue.Delay(ctx, delayTask{Order: "A"}, 0); err != nil {
+		t.Fatalf("Delay失败: %v", err)
+	}
+
+	var mu sync.Mutex
+	var attempts []int64
+	done := make(chan struct{})
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- queue.Run(ctx, func(_ context.Context, job *redis.DelayJob[delayTask]) error {
+			mu.Lock()
+			attempts = append(attempts, job.Attempts)
+			mu.Unlock()
+			switch job.Attempts {
+			case 1:
+				panic("boom")
+			case 2:
+				return errors.New("temporary")
+			}
+			close(done)
+			return nil
+		})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("等待任务处理成功超时")
+	}
+	cancel()
+	if err := <-stopped; err != nil {
+		t.Errorf("Run返回错误: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(attempts) != 3 || attempts[2] != 3 {
+		t.Errorf("期望第3次尝试成功，实际: %v", attempts)
+	}
+	if n, err := queue.Len(context.Background()); err != nil || n != 0 {
+		t.Errorf("期望没有等待任务，实际: %d, %v", n, err)
+	}
+	if dead, err := queue.Dead(context.Background()); err != nil || len(dead) != 0 {
+		t.Errorf("期望死信集合为空，实际: %v, %v", dead, err)
+	}
+}
@@ -2,9 +2,14 @@ package redis
 
 import (
 	"context"
+	"iter"
+	"strings"
 	"time"
 )
 
+// randomKeyAttempts 带前缀的视图中 RandomKey 随机尝试的次数
+const randomKeyAttempts = 16
+
 // Generic 通用操作接口
 type Generic interface {
 	Del(ctx context.Context, keys ...string) (int64, error)
@@ -16,6 +21,10 @@ type Generic interface {
 	Persist(ctx context.Context, key string) (bool, error)
 	Keys(ctx context.Context, pattern string) ([]string, error)
 	Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error)
+	ScanIter(ctx context.Context, opts ...ScanOption) iter.Seq2[string, error]
+	ScanAll(ctx context.Context, opts ...ScanOption) ([]string, error)
+	DeleteByPattern(ctx context.Context, pattern string, opts ...ScanOption) (int64, error)
+	ExpireByPattern(ctx context.Context, pattern string, expiration time.Duration, opts ...ScanOption) (int64, error)
 	Type(ctx context.Context, key string) (string, error)
 	Rename(ctx context.Context, key, newKey string) error
 	RenameNX(ctx context.Context, key, newKey string) (bool, error)
@@ -28,85 +37,105 @@ type Generic interface {
 
 // Del 删除一个或多个键
 func (c *Client) Del(ctx context.Context, keys ...string) (int64, error) {
-	return c.UniversalClient.Del(ctx, keys...).Result()
+	return wrapResult(c.UniversalClient.Del(ctx, c.keys(keys)...).Result())
 }
 
 // Exists 检查一个或多个键是否存在
 func (c *Client) Exists(ctx context.Context, keys ...string) (int64, error) {
-	return c.UniversalClient.Exists(ctx, keys...).Result()
+	return wrapResult(c.UniversalClient.Exists(ctx, c.keys(keys)...).Result())
 }
 
 // Expire 设置键的过期时间（秒）
 func (c *Client) Expire(ctx context.Context, key string, expiration time.Duration) (bool, error) {
-	return c.UniversalClient.Expire(ctx, key, expiration).Result()
+	return wrapResult(c.UniversalClient.Expire(ctx, c.key(key), expiration).Result())
 }
 
 // ExpireAt 设置键的过期时间（Unix时间戳）
 func (c *Client) ExpireAt(ctx context.Context, key string, tm time.Time) (bool, error) {
-	return c.UniversalClient.ExpireAt(ctx, key, tm).Result()
+	return wrapResult(c.UniversalClient.ExpireAt(ctx, c.key(key), tm).Result())
 }
 
 // TTL 获取键的剩余过期时间（秒）
 func (c *Client) TTL(ctx context.Context, key string) (time.Duration, error) {
-	return c.UniversalClient.TTL(ctx, key).Result()
+	return wrapResult(c.UniversalClient.TTL(ctx, c.key(key)).Result())
 }
 
 // PTTL 获取键的剩余过期时间（毫秒）
 func (c *Client) PTTL(ctx context.Context, key string) (time.Duration, error) {
-	return c.UniversalClient.PTTL(ctx, key).Result()
+	return wrapResult(c.UniversalClient.PTTL(ctx, c.key(key)).Result())
 }
 
 // Persist 移除键的过期时间，使其永久存在
 func (c *Client) Persist(ctx context.Context, key string) (bool, error) {
-	return c.UniversalClient.Persist(ctx, key).Result()
+	return wrapResult(c.UniversalClient.Persist(ctx, c.key(key)).Result())
 }
 
-// Keys 获取所有匹配模式的键
+// Keys 获取所有匹配模式的键（KEYS 会阻塞服务器，生产环境请使用 ScanIter）
 func (c *Client) Keys(ctx context.Context, pattern string) ([]string, error) {
-	return c.UniversalClient.Keys(ctx, pattern).Result()
+	keys, err := wrapResult(c.UniversalClient.Keys(ctx, c.pattern(pattern)).Result())
+	return c.stripKeys(keys), err
 }
 
-// Scan 扫描键（推荐使用，避免阻塞）
+// Scan 扫描键（单页，遍历全部键推荐使用 ScanIter）
 func (c *Client) Scan(ctx context.Context, cursor uint64, match string, count int64) ([]string, uint64, error) {
-	return c.UniversalClient.Scan(ctx, cursor, match, count).Result()
+	keys, next, err := c.UniversalClient.Scan(ctx, cursor, c.pattern(match), count).Result()
+	return c.stripKeys(keys), next, err
 }
 
 // Type 获取键的类型
 func (c *Client) Type(ctx context.Context, key string) (string, error) {
-	return c.UniversalClient.Type(ctx, key).Result()
+	return wrapResult(c.UniversalClient.Type(ctx, c.key(key)).Result())
 }
 
 // Rename 重命名键
 func (c *Client) Rename(ctx context.Context, key, newKey string) error {
-	return c.UniversalClient.Rename(ctx, key, newKey).Err()
+	return wrapError(c.UniversalClient.Rename(ctx, c.key(key), c.key(newKey)).Err())
 }
 
 // RenameNX 仅在新键不存在时重命名键
 func (c *Client) RenameNX(ctx context.Context, key, newKey string) (bool, error) {
-	return c.UniversalClient.RenameNX(ctx, key, newKey).Result()
+	return wrapResult(c.UniversalClient.RenameNX(ctx, c.key(key), c.key(newKey)).Result())
 }
 
 // Move 将键移动到指定数据库
 func (c *Client) Move(ctx context.Context, key string, db int) (bool, error) {
-	return c.UniversalClient.Move(ctx, key, db).Result()
+	return wrapResult(c.UniversalClient.Move(ctx, c.key(key), db).Result())
 }
 
-// RandomKey 随机返回一个键
+// RandomKey 随机返回一个键，没有键时返回 ErrNotFound
+// 带前缀的视图中只返回前缀下的键：随机尝试 randomKeyAttempts 次仍未命中时，通过 SCAN 返回前缀下遇到的第一个键
 func (c *Client) RandomKey(ctx context.Context) (string, error) {
-	return c.UniversalClient.RandomKey(ctx).Result()
+	if c.prefix == "" {
+		return wrapResult(c.UniversalClient.RandomKey(ctx).Result())
+	}
+
+	for range randomKeyAttempts {
+		key, err := wrapResult(c.UniversalClient.RandomKey(ctx).Result())
+		if err != nil {
+			return "", err
+		}
+		if strings.HasPrefix(key, c.prefix) {
+			return c.stripKey(key), nil
+		}
+	}
+
+	for key, err := range c.ScanIter(ctx) {
+		return key, err
+	}
+	return "", ErrNotFound
 }
 
 // Dump 序列化键的值
 func (c *Client) Dump(ctx context.Context, key string) (string, error) {
-	return c.UniversalClient.Dump(ctx, key).Result()
+	return wrapResult(c.UniversalClient.Dump(ctx, c.key(key)).Result())
 }
 
 // Restore 反序列化并恢复键的值
 func (c *Client) Restore(ctx context.Context, key string, ttl time.Duration, value string) error {
-	return c.UniversalClient.Restore(ctx, key, ttl, value).Err()
+	return wrapError(c.UniversalClient.Restore(ctx, c.key(key), ttl, value).Err())
 }
 
 // RestoreReplace 反序列化并恢复键的值（如果键已存在则替换）
 func (c *Client) RestoreReplace(ctx context.Context, key string, ttl time.Duration, value string) error {
-	return c.UniversalClient.RestoreReplace(ctx, key, ttl, value).Err()
+	return wrapError(c.UniversalClient.RestoreReplace(ctx, c.key(key), ttl, value).Err())
 }
@@ -0,0 +1,53 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Geo 地理位置操作接口
+type Geo interface {
+	GeoAdd(ctx context.Context, key string, locations ...*redis.GeoLocation) (int64, error)
+	GeoPos(ctx context.Context, key string, members ...string) ([]*redis.GeoPos, error)
+	GeoDist(ctx context.Context, key, member1, member2, unit string) (float64, error)
+	GeoHash(ctx context.Context, key string, members ...string) ([]string, error)
+	GeoSearch(ctx context.Context, key string, query *redis.GeoSearchQuery) ([]string, error)
+	GeoSearchLocation(ctx context.Context, key string, query *redis.GeoSearchLocationQuery) ([]redis.GeoLocation, error)
+	GeoSearchStore(ctx context.Context, key, destination string, query *redis.GeoSearchStoreQuery) (int64, error)
+}
+
+// GeoAdd 添加地理位置成员
+func (c *Client) GeoAdd(ctx context.Context, key string, locations ...*redis.GeoLocation) (int64, error) {
+	return wrapResult(c.UniversalClient.GeoAdd(ctx, c.key(key), locations...).Result())
+}
+
+// GeoPos 获取成员的经纬度，不存在的成员对应位置为nil
+func (c *Client) GeoPos(ctx context.Context, key string, members ...string) ([]*redis.GeoPos, error) {
+	return wrapResult(c.UniversalClient.GeoPos(ctx, c.key(key), members...).Result())
+}
+
+// GeoDist 获取两个成员之间的距离，unit 为 m、km、mi、ft
+func (c *Client) GeoDist(ctx context.Context, key, member1, member2, unit string) (float64, error) {
+	return wrapResult(c.UniversalClient.GeoDist(ctx, c.key(key), member1, member2, unit).Result())
+}
+
+// GeoHash 获取成员的 Geohash 字符串
+func (c *Client) GeoHash(ctx context.Context, key string, members ...string) ([]string, error) {
+	return wrapResult(c.UniversalClient.GeoHash(ctx, c.key(key), members...).Result())
+}
+
+// GeoSearch 按圆形或矩形范围搜索成员（需要 Redis 6.2+）
+func (c *Client) GeoSearch(ctx context.Context, key string, query *redis.GeoSearchQuery) ([]string, error) {
+	return wrapResult(c.UniversalClient.GeoSearch(ctx, c.key(key), query).Result())
+}
+
+// GeoSearchLocation 按范围搜索成员，可同时返回坐标、距离和 Geohash
+func (c *Client) GeoSearchLocation(ctx context.Context, key string, query *redis.GeoSearchLocationQuery) ([]redis.GeoLocation, error) {
+	return wrapResult(c.UniversalClient.GeoSearchLocation(ctx, c.key(key), query).Result())
+}
+
+// GeoSearchStore 按范围搜索成员并存储到目标键
+func (c *Client) GeoSearchStore(ctx context.Context, key, destination string, query *redis.GeoSearchStoreQuery) (int64, error) {
+	return wrapResult(c.UniversalClient.GeoSearchStore(ctx, c.key(key), c.key(destination), query).Result())
+}
@@ -0,0 +1,348 @@
+package redis
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nilchaosky/go-nexus/nexus_utils"
+)
+
+// hashField 结构体字段与哈希表字段的映射
+type hashField struct {
+	name      string // 哈希表字段名
+	index     []int  // 结构体字段索引（含匿名嵌入路径）
+	omitEmpty bool   // 零值时不写入
+}
+
+var (
+	// hashFieldsCache 结构体类型到字段映射的缓存
+	hashFieldsCache sync.Map
+	// errNoHashFields 结构体没有可写入的字段
+	errNoHashFields = errors.New("结构体没有可写入的字段")
+)
+
+// HSetStruct 将结构体按 `redis:"name"` 标签映射为哈希表字段并写入，返回新增字段数量
+// 标签为 "-" 的字段忽略；`redis:"name,omitempty"` 的字段为零值时不写入；nil 指针字段不写入
+// 字符串、数字直接写入，布尔写入 1/0（数字字段可继续使用 HIncrBy/HIncrByFloat），
+// 实现 encoding.TextMarshaler 的类型（time.Time、variant.SerializeTime 等）写入文本形式，
+// 嵌套结构体、map、切片等编码为普通JSON（不经过客户端序列化器与值编码），便于其他语言或 redis-cli 直接读取
+// 仅写入映射的字段，哈希表中其他字段保持不变
+func (c *Client) HSetStruct(ctx context.Context, key string, value interface{}) (int64, error) {
+	values, err := c.structToHash(value)
+	if err != nil {
+		return 0, err
+	}
+	if len(values) == 0 {
+		return 0, errNoHashFields
+	}
+	return wrapResult(c.UniversalClient.HSet(ctx, c.key(key), values...).Result())
+}
+
+// HGetAllStruct 获取哈希表中所有字段，按 `redis:"name"` 标签填充到结构体
+// 哈希表不存在时返回 ErrNotFound；哈希表中缺少的字段保持原值
+func (c *Client) HGetAllStruct(ctx context.Context, key string, value interface{}) error {
+	rv, err := nexus_utils.IsPointer(value)
+	if err != nil {
+		return err
+	}
+
+	results, err := c.HGetAll(ctx, key)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return ErrNotFound
+	}
+
+	return c.hashToStruct(rv, results)
+}
+
+// HMGetFields 获取哈希表中指定字段，按 `redis:"name"` 标签填充到结构体
+// fields 为哈希表字段名，为空时获取结构体映射的所有字段；不存在的字段保持原值
+func (c *Client) HMGetFields(ctx context.Context, key string, value interface{}, fields ...string) error {
+	rv, err := nexus_utils.IsPointer(value)
+	if err != nil {
+		return err
+	}
+
+	mapping, err := hashFieldsOf(rv.Type().Elem())
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 {
+		fields = make([]string, len(mapping))
+		for i, f := range mapping {
+			fields[i] = f.name
+		}
+	}
+
+	results, err := c.HMGet(ctx, key, fields...)
+	if err != nil {
+		return err
+	}
+
+	values := make(map[string]string, len(fields))
+	for i, result := range results {
+		if str, ok := result.(string); ok {
+			values[fields[i]] = str
+		}
+	}
+
+	return c.hashToStruct(rv, values)
+}
+
+// structToHash 将结构体转换为 HSET 参数（字段、值交替）
+func (c *Client) structToHash(value interface{}) ([]interface{}, error) {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, errors.New("结构体不能为nil")
+		}
+		rv = rv.Elem()
+	}
+
+	mapping, err := hashFieldsOf(rv.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, 0, len(mapping)*2)
+	for _, f := range mapping {
+		fv, ok := fieldByIndex(rv, f.index)
+		if !ok || (fv.Kind() == reflect.Ptr && fv.IsNil()) {
+			continue
+		}
+		if f.omitEmpty && fv.IsZero() {
+			continue
+		}
+
+		data, err := c.encodeField(fv)
+		if err != nil {
+			return nil, fmt.Errorf("字段 %s: %w", f.name, err)
+		}
+		values = append(values, f.name, data)
+	}
+
+	return values, nil
+}
+
+// hashToStruct 将哈希表字段填充到结构体
+func (c *Client) hashToStruct(rv reflect.Value, values map[string]string) error {
+	mapping, err := hashFieldsOf(rv.Type().Elem())
+	if err != nil {
+		return err
+	}
+
+	elem := rv.Elem()
+	for _, f := range mapping {
+		data, ok := values[f.name]
+		if !ok {
+			continue
+		}
+		if err := c.decodeField(allocFieldByIndex(elem, f.index), data); err != nil {
+			return fmt.Errorf("%w: 字段 %s: %w", ErrDecode, f.name, err)
+		}
+	}
+
+	return nil
+}
+
+// encodeField 编码单个字段值
+func (c *Client) encodeField(fv reflect.Value) (string, error) {
+	for fv.Kind() == reflect.Ptr {
+		fv = fv.Elem()
+	}
+
+	if m, ok := fv.Interface().(encoding.TextMarshaler); ok {
+		data, err := m.MarshalText()
+		return string(data), err
+	}
+	if fv.CanAddr() {
+		if m, ok := fv.Addr().Interface().(encoding.TextMarshaler); ok {
+			data, err := m.MarshalText()
+			return string(data), err
+		}
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		return fv.String(), nil
+	case reflect.Bool:
+		if fv.Bool() {
+			return "1", nil
+		}
+		return "0", nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(fv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(fv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(fv.Float(), 'f', -1, fv.Type().Bits()), nil
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() == reflect.Uint8 {
+			return string(fv.Bytes()), nil
+		}
+	}
+
+	data, err := json.Marshal(fv.Interface())
+	if err != nil {
+		return "", fmt.Errorf("序列化失败: %w", err)
+	}
+	return string(data), nil
+}
+
+// decodeField 解码单个字段值，空字符串解码为零值
+func (c *Client) decodeField(fv reflect.Value, data string) error {
+	if data == "" {
+		fv.Set(reflect.Zero(fv.Type()))
+		return nil
+	}
+
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return c.decodeField(fv.Elem(), data)
+	}
+
+	if u, ok := fv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(data))
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(data)
+		return nil
+	case reflect.Bool:
+		v, err := strconv.ParseBool(data)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(v)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(data, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(v)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(data, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(v)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(data, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(v)
+		return nil
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() == reflect.Uint8 {
+			fv.SetBytes([]byte(data))
+			return nil
+		}
+	}
+
+	// 兼容旧版本使用客户端序列化器写入的值
+	if err := json.Unmarshal([]byte(data), fv.Addr().Interface()); err != nil {
+		return c.unmarshal([]byte(data), fv.Addr().Interface())
+	}
+	return nil
+}
+
+// hashFieldsOf 获取结构体类型的字段映射（带缓存）
+func hashFieldsOf(t reflect.Type) ([]hashField, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("值必须是结构体，实际为 %s", t.Kind())
+	}
+
+	if cached, ok := hashFieldsCache.Load(t); ok {
+		return cached.([]hashField), nil
+	}
+
+	fields := collectHashFields(t, nil)
+	hashFieldsCache.Store(t, fields)
+	return fields, nil
+}
+
+// collectHashFields 收集带 redis 标签的字段，未加标签的匿名嵌入结构体展开处理
+func collectHashFields(t reflect.Type, parent []int) []hashField {
+	var fields []hashField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		index := append(append([]int(nil), parent...), i)
+
+		tag, hasTag := sf.Tag.Lookup("redis")
+		if tag == "-" {
+			continue
+		}
+
+		if !hasTag {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if sf.Anonymous && ft.Kind() == reflect.Struct && (sf.IsExported() || sf.Type.Kind() != reflect.Ptr) {
+				fields = append(fields, collectHashFields(ft, index)...)
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, hashField{
+			name:      name,
+			index:     index,
+			omitEmpty: opts == "omitempty",
+		})
+	}
+	return fields
+}
+
+// fieldByIndex 按索引路径获取字段，经过 nil 指针时返回 false
+func fieldByIndex(rv reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				return reflect.Value{}, false
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv, true
+}
+
+// allocFieldByIndex 按索引路径获取字段，经过 nil 指针时自动分配
+func allocFieldByIndex(rv reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Ptr {
+			if rv.IsNil() {
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+		rv = rv.Field(x)
+	}
+	return rv
+}
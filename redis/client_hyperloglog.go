@@ -0,0 +1,31 @@
+package redis
+
+import (
+	"context"
+)
+
+// HyperLogLog 基数统计操作接口
+type HyperLogLog interface {
+	PFAdd(ctx context.Context, key string, elements ...interface{}) (int64, error)
+	PFCount(ctx context.Context, keys ...string) (int64, error)
+	PFMerge(ctx context.Context, destination string, keys ...string) error
+}
+
+// PFAdd 向 HyperLogLog 添加元素，内部寄存器被修改时返回1
+func (c *Client) PFAdd(ctx context.Context, key string, elements ...interface{}) (int64, error) {
+	elements, err := c.marshalValues(elements)
+	if err != nil {
+		return 0, err
+	}
+	return wrapResult(c.UniversalClient.PFAdd(ctx, c.key(key), elements...).Result())
+}
+
+// PFCount 获取 HyperLogLog 的近似基数，多个键时返回并集的基数
+func (c *Client) PFCount(ctx context.Context, keys ...string) (int64, error) {
+	return wrapResult(c.UniversalClient.PFCount(ctx, c.keys(keys)...).Result())
+}
+
+// PFMerge 将多个 HyperLogLog 合并到目标键
+func (c *Client) PFMerge(ctx context.Context, destination string, keys ...string) error {
+	return wrapError(c.UniversalClient.PFMerge(ctx, c.key(destination), c.keys(keys)...).Err())
+}
@@ -0,0 +1,449 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// leaderboardKeyPrefix 排行榜键前缀
+const leaderboardKeyPrefix = "LEADERBOARD:"
+
+// leaderboardTieSpan 时间排序可表示的时间跨度（秒），从 leaderboardTieEpoch 起约136年
+const leaderboardTieSpan = 1 << 32
+
+// leaderboardTieEpoch 时间排序的起始时间
+var leaderboardTieEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// leaderboardSubmitScript 按提交模式更新总榜和周期榜
+// 启用时间排序时，分数整数部分为实际分数，小数部分（0-0.5）编码提交时间，越早提交小数部分越大
+// KEYS 为各榜单键 ARGV[1] 提交模式 ARGV[2] 成员 ARGV[3] 分数 ARGV[4] 时间小数（-1为不启用）
+// ARGV[4+i] 为 KEYS[i] 的过期时间戳（毫秒，0为不过期）
+// 返回各榜单更新后的实际分数
+var leaderboardSubmitScript = redis.NewScript(`
+local mode = ARGV[1]
+local member = ARGV[2]
+local score = tonumber(ARGV[3])
+local tie = tonumber(ARGV[4])
+local result = {}
+for i, key in ipairs(KEYS) do
+	local value = score
+	local write = true
+	local current = redis.call('zscore', key, member)
+	if current then
+		current = tonumber(current)
+		if tie >= 0 then
+			current = math.floor(current)
+		end
+		if mode == 'sum' then
+			value = current + score
+		elseif mode == 'max' and current >= score then
+			value = current
+			write = false
+		end
+	end
+	if write then
+		local composite = value
+		if tie >= 0 then
+			composite = value + tie
+		end
+		redis.call('zadd', key, string.format('%.17g', composite), member)
+	end
+	local expireAt = tonumber(ARGV[4 + i])
+	if expireAt > 0 then
+		redis.call('pexpireat', key, expireAt)
+	end
+	table.insert(result, string.format('%.17g', value))
+end
+return result
+`)
+
+// LeaderboardMode 分数提交模式
+type LeaderboardMode string
+
+const (
+	// LeaderboardModeMax 保留最高分
+	LeaderboardModeMax LeaderboardMode = "max"
+	// LeaderboardModeSum 累加分数
+	LeaderboardModeSum LeaderboardMode = "sum"
+	// LeaderboardModeLatest 使用最近一次提交的分数
+	LeaderboardModeLatest LeaderboardMode = "latest"
+)
+
+// LeaderboardPeriod 周期榜类型
+type LeaderboardPeriod string
+
+const (
+	// LeaderboardDaily 日榜
+	LeaderboardDaily LeaderboardPeriod = "day"
+	// LeaderboardWeekly 周榜（ISO周，周一开始）
+	LeaderboardWeekly LeaderboardPeriod = "week"
+	// LeaderboardMonthly 月榜
+	LeaderboardMonthly LeaderboardPeriod = "month"
+)
+
+// LeaderboardOption 排行榜选项
+type LeaderboardOption func(*leaderboardOptions)
+
+// leaderboardOptions 排行榜选项
+type leaderboardOptions struct {
+	mode      LeaderboardMode     // 提交模式
+	tieBreak  bool                // 同分时按提交时间排序
+	periods   []LeaderboardPeriod // 同时维护的周期榜
+	retention time.Duration       // 周期结束后的保留时间
+	location  *time.Location      // 周期划分使用的时区
+}
+
+// LeaderboardSubmitMode 设置分数提交模式（默认：LeaderboardModeMax）
+func LeaderboardSubmitMode(mode LeaderboardMode) LeaderboardOption {
+	return func(o *leaderboardOptions) {
+		switch mode {
+		case LeaderboardModeMax, LeaderboardModeSum, LeaderboardModeLatest:
+			o.mode = mode
+		}
+	}
+}
+
+// LeaderboardTieBreak 启用同分时按提交时间排序，先达到该分数的成员排名靠前
+// 启用后分数必须为整数，时间精度为秒；分数绝对值超过 2^20 后可区分的时间精度逐渐降低
+func LeaderboardTieBreak() LeaderboardOption {
+	return func(o *leaderboardOptions) {
+		o.tieBreak = true
+	}
+}
+
+// LeaderboardPeriods 设置提交分数时同时维护的周期榜
+func LeaderboardPeriods(periods ...LeaderboardPeriod) LeaderboardOption {
+	return func(o *leaderboardOptions) {
+		o.periods = periods
+	}
+}
+
+// LeaderboardRetention 设置周期榜在周期结束后的保留时间，到期自动删除（默认：7天）
+func LeaderboardRetention(d time.Duration) LeaderboardOption {
+	return func(o *leaderboardOptions) {
+		if d >= 0 {
+			o.retention = d
+		}
+	}
+}
+
+// LeaderboardLocation 设置周期划分使用的时区（默认：time.Local）
+func LeaderboardLocation(loc *time.Location) LeaderboardOption {
+	return func(o *leaderboardOptions) {
+		if loc != nil {
+			o.location = loc
+		}
+	}
+}
+
+// RankEntry 排行榜条目
+type RankEntry[T any] struct {
+	Member T       // 反序列化后的成员
+	Rank   int64   // 名次，从1开始
+	Score  float64 // 分数（不含时间排序的小数部分）
+}
+
+// Leaderboard 基于有序集合的排行榜
+// 提交分数时通过Lua脚本原子更新总榜和配置的周期榜，周期榜在周期结束后按保留时间自动过期
+// 所有键使用 {name} 哈希标签，集群模式下可在榜单之间执行 ZUNIONSTORE
+type Leaderboard[T any] struct {
+	client *Client
+	name   string
+	prefix string
+	opts   leaderboardOptions
+}
+
+// NewLeaderboard 创建排行榜
+func NewLeaderboard[T any](c *Client, name string, opts ...LeaderboardOption) (*Leaderboard[T], error) {
+	if name == "" {
+		return nil, errors.New("排行榜名称不能为空")
+	}
+
+	o := leaderboardOptions{
+		mode:      LeaderboardModeMax,
+		retention: 7 * 24 * time.Hour,
+		location:  time.Local,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	for _, period := range o.periods {
+		if _, err := periodStart(period, time.Now().In(o.location)); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Leaderboard[T]{
+		client: c,
+		name:   name,
+		prefix: leaderboardKeyPrefix + "{" + name + "}:",
+		opts:   o,
+	}, nil
+}
+
+// Submit 提交分数，按提交模式更新总榜和周期榜，返回总榜中更新后的分数
+func (l *Leaderboard[T]) Submit(ctx context.Context, member T, score float64) (float64, error) {
+	return l.SubmitAt(ctx, member, score, time.Now())
+}
+
+// SubmitAt 以指定时间提交分数，时间决定写入的周期榜及同分排序
+func (l *Leaderboard[T]) SubmitAt(ctx context.Context, member T, score float64, at time.Time) (float64, error) {
+	if l.opts.tieBreak && score != math.Trunc(score) {
+		return 0, errors.New("启用时间排序时分数必须为整数")
+	}
+
+	data, err := encodeAs(l.client, member)
+	if err != nil {
+		return 0, err
+	}
+
+	tie := -1.0
+	if l.opts.tieBreak {
+		elapsed := min(max(at.Sub(leaderboardTieEpoch)/time.Second, 0), leaderboardTieSpan)
+		tie = float64(leaderboardTieSpan-elapsed) / leaderboardTieSpan / 2
+	}
+
+	keys := make([]string, 0, len(l.opts.periods)+1)
+	expireAts := make([]interface{}, 0, len(l.opts.periods)+1)
+	keys = append(keys, l.client.key(l.prefix+"total"))
+	expireAts = append(expireAts, 0)
+	for _, period := range l.opts.periods {
+		board, end, err := l.periodBoard(period, at)
+		if err != nil {
+			return 0, err
+		}
+		keys = append(keys, l.client.key(board))
+		expireAts = append(expireAts, end.Add(l.opts.retention).UnixMilli())
+	}
+
+	args := append([]interface{}{string(l.opts.mode), data, strconv.FormatFloat(score, 'f', -1, 64), strconv.FormatFloat(tie, 'f', -1, 64)}, expireAts...)
+	results, err := leaderboardSubmitScript.Run(ctx, l.client.UniversalClient, keys, args...).StringSlice()
+	if err != nil {
+		return 0, wrapError(err)
+	}
+
+	return strconv.ParseFloat(results[0], 64)
+}
+
+// Total 获取总榜
+func (l *Leaderboard[T]) Total() *RankBoard[T] {
+	return &RankBoard[T]{lb: l, key: l.prefix + "total"}
+}
+
+// Board 获取指定时间所在周期的周期榜
+func (l *Leaderboard[T]) Board(period LeaderboardPeriod, at time.Time) (*RankBoard[T], error) {
+	key, _, err := l.periodBoard(period, at)
+	if err != nil {
+		return nil, err
+	}
+	return &RankBoard[T]{lb: l, key: key}, nil
+}
+
+// Range 获取时间范围内（含首尾所在周期）的所有周期榜，如最近7天的日榜
+func (l *Leaderboard[T]) Range(period LeaderboardPeriod, from, to time.Time) ([]*RankBoard[T], error) {
+	var boards []*RankBoard[T]
+	for at := from; !at.After(to); {
+		key, end, err := l.periodBoard(period, at)
+		if err != nil {
+			return nil, err
+		}
+		boards = append(boards, &RankBoard[T]{lb: l, key: key})
+		at = end
+	}
+	return boards, nil
+}
+
+// Union 通过 ZUNIONSTORE 将多个榜单合并为新榜单，expiration 大于0时设置过期时间
+// 累加模式使用 SUM 聚合，其他模式使用 MAX 聚合；累加模式启用时间排序时不支持合并
+// 注意：榜单不记录成员的提交时间，最新模式同样按 MAX 聚合，合并结果是成员在各榜单中的最高分而不是最近一次提交的分数
+func (l *Leaderboard[T]) Union(ctx context.Context, name string, boards []*RankBoard[T], expiration time.Duration) (*RankBoard[T], error) {
+	if name == "" {
+		return nil, errors.New("榜单名称不能为空")
+	}
+	if len(boards) == 0 {
+		return nil, errors.New("合并的榜单不能为空")
+	}
+
+	aggregate := "MAX"
+	if l.opts.mode == LeaderboardModeSum {
+		if l.opts.tieBreak {
+			return nil, errors.New("累加模式启用时间排序时不支持合并")
+		}
+		aggregate = "SUM"
+	}
+
+	dest := &RankBoard[T]{lb: l, key: l.prefix + "union:" + name}
+	keys := make([]string, len(boards))
+	for i, board := range boards {
+		keys[i] = board.key
+	}
+
+	err := l.client.TxPipeline(ctx, func(p *Pipe) error {
+		p.Raw().ZUnionStore(ctx, l.client.key(dest.key), l.client.zstore(&redis.ZStore{Keys: keys, Aggregate: aggregate}))
+		if expiration > 0 {
+			p.Expire(ctx, dest.key, expiration)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+// periodBoard 获取周期榜键名及周期结束时间
+func (l *Leaderboard[T]) periodBoard(period LeaderboardPeriod, at time.Time) (string, time.Time, error) {
+	at = at.In(l.opts.location)
+	start, err := periodStart(period, at)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	switch period {
+	case LeaderboardDaily:
+		return l.prefix + "day:" + start.Format("20060102"), start.AddDate(0, 0, 1), nil
+	case LeaderboardWeekly:
+		year, week := start.ISOWeek()
+		return l.prefix + fmt.Sprintf("week:%04dW%02d", year, week), start.AddDate(0, 0, 7), nil
+	default:
+		return l.prefix + "month:" + start.Format("200601"), start.AddDate(0, 1, 0), nil
+	}
+}
+
+// periodStart 获取时间所在周期的开始时间
+func periodStart(period LeaderboardPeriod, at time.Time) (time.Time, error) {
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location())
+	switch period {
+	case LeaderboardDaily:
+		return day, nil
+	case LeaderboardWeekly:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7)), nil
+	case LeaderboardMonthly:
+		return day.AddDate(0, 0, 1-day.Day()), nil
+	default:
+		return time.Time{}, fmt.Errorf("不支持的周期类型: %s", period)
+	}
+}
+
+// RankBoard 排行榜中的单个榜单（总榜、周期榜或合并榜），按分数从高到低排名
+type RankBoard[T any] struct {
+	lb  *Leaderboard[T]
+	key string
+}
+
+// Key 获取榜单键名（不含客户端前缀）
+func (b *RankBoard[T]) Key() string {
+	return b.key
+}
+
+// Count 获取榜单成员数量
+func (b *RankBoard[T]) Count(ctx context.Context) (int64, error) {
+	return b.lb.client.ZCard(ctx, b.key)
+}
+
+// Top 分页获取排名，page 从1开始
+func (b *RankBoard[T]) Top(ctx context.Context, page, size int64) ([]RankEntry[T], error) {
+	if page < 1 || size < 1 {
+		return nil, errors.New("页码和每页数量必须大于0")
+	}
+	start := (page - 1) * size
+	return b.rangeEntries(ctx, start, start+size-1)
+}
+
+// Rank 获取成员的名次和分数，成员不在榜单中时返回 ErrNotFound
+func (b *RankBoard[T]) Rank(ctx context.Context, member T) (RankEntry[T], error) {
+	data, err := encodeAs(b.lb.client, member)
+	if err != nil {
+		return RankEntry[T]{}, err
+	}
+
+	var rank *redis.IntCmd
+	var score *redis.FloatCmd
+	err = b.lb.client.Pipeline(ctx, func(p *Pipe) error {
+		rank = p.Raw().ZRevRank(ctx, b.lb.client.key(b.key), data)
+		score = p.Raw().ZScore(ctx, b.lb.client.key(b.key), data)
+		return nil
+	})
+	if err != nil {
+		return RankEntry[T]{}, err
+	}
+	if err := rank.Err(); err != nil {
+		return RankEntry[T]{}, wrapError(err)
+	}
+
+	return RankEntry[T]{Member: member, Rank: rank.Val() + 1, Score: b.lb.score(score.Val())}, nil
+}
+
+// Score 获取成员分数，成员不在榜单中时返回 ErrNotFound
+func (b *RankBoard[T]) Score(ctx context.Context, member T) (float64, error) {
+	data, err := encodeAs(b.lb.client, member)
+	if err != nil {
+		return 0, err
+	}
+
+	score, err := b.lb.client.ZScore(ctx, b.key, data)
+	if err != nil {
+		return 0, err
+	}
+	return b.lb.score(score), nil
+}
+
+// Around 获取成员前后各 n 名的排名（含成员自身），成员不在榜单中时返回 ErrNotFound
+func (b *RankBoard[T]) Around(ctx context.Context, member T, n int64) ([]RankEntry[T], error) {
+	data, err := encodeAs(b.lb.client, member)
+	if err != nil {
+		return nil, err
+	}
+
+	rank, err := b.lb.client.ZRevRank(ctx, b.key, data)
+	if err != nil {
+		return nil, err
+	}
+	return b.rangeEntries(ctx, max(rank-n, 0), rank+n)
+}
+
+// Remove 从榜单中移除成员
+func (b *RankBoard[T]) Remove(ctx context.Context, members ...T) (int64, error) {
+	values := make([]interface{}, len(members))
+	for i, member := range members {
+		data, err := encodeAs(b.lb.client, member)
+		if err != nil {
+			return 0, err
+		}
+		values[i] = data
+	}
+	return b.lb.client.ZRem(ctx, b.key, values...)
+}
+
+// rangeEntries 获取名次区间（从0开始，含首尾）的条目
+func (b *RankBoard[T]) rangeEntries(ctx context.Context, start, stop int64) ([]RankEntry[T], error) {
+	zs, err := b.lb.client.ZRevRangeWithScores(ctx, b.key, start, stop)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]RankEntry[T], len(zs))
+	for i, z := range zs {
+		member, err := decodeAs[T](b.lb.client, z.Member.(string))
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = RankEntry[T]{Member: member, Rank: start + int64(i) + 1, Score: b.lb.score(z.Score)}
+	}
+	return entries, nil
+}
+
+// score 去除时间排序的小数部分
+func (l *Leaderboard[T]) score(composite float64) float64 {
+	if l.opts.tieBreak {
+		return math.Floor(composite)
+	}
+	return composite
+}
@@ -0,0 +1,186 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nilchaosky/go-nexus/redis"
+	"github.com/nilchaosky/go-nexus/redis/redistest"
+)
+
+// TestLeaderboardModes 测试最高分、累加、最新三种提交模式
+func TestLeaderboardModes(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+
+	tests := []struct {
+		mode redis.LeaderboardMode
+		want float64
+	}{
+		{redis.LeaderboardModeMax, 10},
+		{redis.LeaderboardModeSum, 15},
+		{redis.LeaderboardModeLatest, 5},
+	}
+	for _, tt := range tests {
+		lb, err := redis.NewLeaderboard[string](client, string(tt.mode), redis.LeaderboardSubmitMode(tt.mode))
+		if err != nil {
+			t.Fatalf("NewLeaderboard失败: %v", err)
+		}
+		if _, err := lb.Submit(ctx, "tom", 10); err != nil {
+			t.Fatalf("Submit失败: %v", err)
+		}
+		score, err := lb.Submit(ctx, "tom", 5)
+		if err != nil || score != tt.want {
+			t.Errorf("%s: 期望提交后分数为%v，实际: %v, %v", tt.mode, tt.want, score, err)
+		}
+		if score, _ := lb.Total().Score(ctx, "tom"); score != tt.want {
+			t.Errorf("%s: 期望榜单分数为%v，实际: %v", tt.mode, tt.want, score)
+		}
+	}
+}
+
+// TestLeaderboardRanking 测试同分按提交时间排序、分页、名次和前后名次
+func TestLeaderboardRanking(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+
+	lb, err := redis.NewLeaderboard[string](client, "arena", redis.LeaderboardTieBreak())
+	if err != nil {
+		t.Fatalf("NewLeaderboard失败: %v", err)
+	}
+	if _, err := lb.Submit(ctx, "x", 1.5); err == nil {
+		t.Error("期望启用时间排序时小数分数返回错误")
+	}
+
+	now := time.Now()
+	submits := []struct {
+		member string
+		score  float64
+		at     time.Time
+	}{
+		{"a", 100, now},
+		{"b", 100, now.Add(-time.Hour)}, // 同分先提交，排在a之前
+		{"c", 101, now},
+		{"d", 50, now},
+		{"b", 100, now.Add(time.Hour)}, // 最高分模式下未超过原分数，保留原提交时间
+	}
+	for _, s := range submits {
+		if _, err := lb.SubmitAt(ctx, s.member, s.score, s.at); err != nil {
+			t.Fatalf("SubmitAt失败: %v", err)
+		}
+	}
+
+	board := lb.Total()
+	entries, err := board.Top(ctx, 1, 3)
+	if err != nil {
+		t.Fatalf("Top失败: %v", err)
+	}
+	want := []redis.RankEntry[string]{{Member: "c", Rank: 1, Score: 101}, {Member: "b", Rank: 2, Score: 100}, {Member: "a", Rank: 3, Score: 100}}
+	if len(entries) != len(want) {
+		t.Fatalf("期望3条排名，实际: %+v", entries)
+	}
+	for i := range want {
+		if entries[i] != want[i] {
+			t.Errorf("第%d名期望 %+v，实际: %+v", i+1, want[i], entries[i])
+		}
+	}
+
+	if entries, _ := board.Top(ctx, 2, 3); len(entries) != 1 || entries[0].Member != "d" || entries[0].Rank != 4 {
+		t.Errorf("第2页期望只有d，实际: %+v", entries)
+	}
+	if entry, err := board.Rank(ctx, "a"); err != nil || entry.Rank != 3 || entry.Score != 100 {
+		t.Errorf("期望a排名第3，实际: %+v, %v", entry, err)
+	}
+	if _, err := board.Rank(ctx, "nobody"); !redis.IsNotFound(err) {
+		t.Errorf("期望不在榜单中返回ErrNotFound，实际: %v", err)
+	}
+
+	around, err := board.Around(ctx, "c", 1)
+	if err != nil || len(around) != 2 || around[0].Member != "c" || around[1].Member != "b" {
+		t.Errorf("期望c前后1名为[c b]，实际: %+v, %v", around, err)
+	}
+	if n, _ := board.Remove(ctx, "c"); n != 1 {
+		t.Errorf("期望移除1个成员，实际: %d", n)
+	}
+	if entry, _ := board.Rank(ctx, "b"); entry.Rank != 1 {
+		t.Errorf("期望移除c后b排名第1，实际: %+v", entry)
+	}
+}
+
+// TestLeaderboardPeriods 测试周期榜的写入、过期与合并
+func TestLeaderboardPeriods(t *testing.T) {
+	ctx := context.Background()
+	client, server := redistest.NewClient(t)
+
+	lb, err := redis.NewLeaderboard[string](client, "game",
+		redis.LeaderboardSubmitMode(redis.LeaderboardModeSum),
+		redis.LeaderboardPeriods(redis.LeaderboardDaily, redis.LeaderboardMonthly),
+		redis.LeaderboardRetention(0),
+		redis.LeaderboardLocation(time.UTC),
+	)
+	if err != nil {
+		t.Fatalf("NewLeaderboard失败: %v", err)
+	}
+	if _, err := redis.NewLeaderboard[string](client, "bad", redis.LeaderboardPeriods("year")); err == nil {
+		t.Error("期望不支持的周期类型返回错误")
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	tomorrow := today.Add(24 * time.Hour)
+	if _, err := lb.SubmitAt(ctx, "tom", 10, today.Add(time.Hour)); err != nil {
+		t.Fatalf("SubmitAt失败: %v", err)
+	}
+	if _, err := lb.SubmitAt(ctx, "tom", 5, tomorrow.Add(time.Hour)); err != nil {
+		t.Fatalf("SubmitAt失败: %v", err)
+	}
+	if _, err := lb.SubmitAt(ctx, "amy", 12, tomorrow.Add(time.Hour)); err != nil {
+		t.Fatalf("SubmitAt失败: %v", err)
+	}
+
+	day, _ := lb.Board(redis.LeaderboardDaily, today)
+	if want := "LEADERBOARD:{game}:day:" + today.Format("20060102"); day.Key() != want {
+		t.Errorf("期望日榜键名为%s，实际: %s", want, day.Key())
+	}
+	if score, _ := day.Score(ctx, "tom"); score != 10 {
+		t.Errorf("期望今日分数为10，实际: %v", score)
+	}
+	if score, _ := lb.Total().Score(ctx, "tom"); score != 15 {
+		t.Errorf("期望总分为15，实际: %v", score)
+	}
+
+	// 合并今明两日的日榜，累加模式按 SUM 聚合
+	boards, err := lb.Range(redis.LeaderboardDaily, today, tomorrow)
+	if err != nil || len(boards) != 2 {
+		t.Fatalf("期望2个日榜，实际: %d, %v", len(boards), err)
+	}
+	union, err := lb.Union(ctx, "2days", boards, time.Minute)
+	if err != nil {
+		t.Fatalf("Union失败: %v", err)
+	}
+	entries, _ := union.Top(ctx, 1, 10)
+	if len(entries) != 2 || entries[0].Member != "tom" || entries[0].Score != 15 || entries[1].Score != 12 {
+		t.Errorf("合并结果不符: %+v", entries)
+	}
+	if ttl, _ := client.TTL(ctx, union.Key()); ttl != time.Minute {
+		t.Errorf("期望合并榜单过期时间为1分钟，实际: %v", ttl)
+	}
+
+	// 保留时间为0时周期结束即过期，总榜不过期
+	server.Clock().Set(tomorrow.Add(time.Second))
+	if n, _ := day.Count(ctx); n != 0 {
+		t.Errorf("期望今日日榜已过期，实际成员数: %d", n)
+	}
+	next, _ := lb.Board(redis.LeaderboardDaily, tomorrow)
+	if n, _ := next.Count(ctx); n != 2 {
+		t.Errorf("期望明日日榜有2个成员，实际: %d", n)
+	}
+	if n, _ := lb.Total().Count(ctx); n != 2 {
+		t.Errorf("期望总榜有2个成员，实际: %d", n)
+	}
+
+	tied, _ := redis.NewLeaderboard[string](client, "tied", redis.LeaderboardSubmitMode(redis.LeaderboardModeSum), redis.LeaderboardTieBreak())
+	if _, err := tied.Union(ctx, "x", []*redis.RankBoard[string]{tied.Total()}, 0); err == nil {
+		t.Error("期望累加模式启用时间排序时合并返回错误")
+	}
+}
@@ -0,0 +1,253 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// LimitFixedWindow 固定窗口算法（计数器）
+	LimitFixedWindow = "fixed_window"
+	// LimitSlidingWindow 滑动窗口日志算法（有序集合）
+	LimitSlidingWindow = "sliding_window"
+	// LimitTokenBucket 令牌桶算法
+	LimitTokenBucket = "token_bucket"
+)
+
+// limiterKeyPrefix 限流键前缀
+const limiterKeyPrefix = "RATELIMIT:"
+
+var (
+	// limitFixedWindowScript 固定窗口限流，被拒绝的请求不计数
+	// KEYS[1] 计数键 ARGV[1] 窗口请求数 ARGV[2] 窗口时长（毫秒） ARGV[3] 本次请求数
+	// 返回 {是否允许, 剩余请求数, 重试等待（毫秒）, 窗口重置时间（毫秒）}
+	limitFixedWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local current = redis.call('incrby', KEYS[1], n)
+local ttl = redis.call('pttl', KEYS[1])
+if ttl < 0 then
+	redis.call('pexpire', KEYS[1], period)
+	ttl = period
+end
+if current > limit then
+	redis.call('decrby', KEYS[1], n)
+	return {0, math.max(limit - current + n, 0), ttl, ttl}
+end
+return {1, limit - current, 0, ttl}
+`)
+
+	// limitSlidingWindowScript 滑动窗口日志限流，使用服务器时间
+	// KEYS[1] 日志键 ARGV[1] 窗口请求数 ARGV[2] 窗口时长（毫秒） ARGV[3] 本次请求数 ARGV[4] 成员唯一标识
+	// 返回 {是否允许, 剩余请求数, 重试等待（毫秒）, 窗口重置时间（毫秒）}
+	limitSlidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local t = redis.call('time')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+redis.call('zremrangebyscore', KEYS[1], '-inf', now - period)
+local count = redis.call('zcard', KEYS[1])
+if count + n <= limit then
+	for i = 1, n do
+		redis.call('zadd', KEYS[1], now, ARGV[4] .. ':' .. i)
+	end
+	redis.call('pexpire', KEYS[1], period)
+	return {1, limit - count - n, 0, period}
+end
+local idx = count + n - limit - 1
+local entry = redis.call('zrange', KEYS[1], idx, idx, 'withscores')
+local retry = period
+if entry[2] then
+	retry = tonumber(entry[2]) + period - now
+end
+local oldest = redis.call('zrange', KEYS[1], 0, 0, 'withscores')
+local reset = period
+if oldest[2] then
+	reset = tonumber(oldest[2]) + period - now
+end
+return {0, limit - count, retry, reset}
+`)
+
+	// limitTokenBucketScript 令牌桶限流，使用服务器时间
+	// KEYS[1] 令牌桶键 ARGV[1] 每周期补充令牌数 ARGV[2] 周期时长（毫秒） ARGV[3] 本次请求数 ARGV[4] 桶容量
+	// 返回 {是否允许, 剩余令牌数, 重试等待（毫秒）, 桶填满时间（毫秒）}
+	limitTokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1]) / tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local capacity = tonumber(ARGV[4])
+local t = redis.call('time')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local data = redis.call('hmget', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(data[1]) or capacity
+local ts = tonumber(data[2]) or now
+tokens = math.min(capacity, tokens + math.max(now - ts, 0) * rate)
+local allowed = 0
+local retry = 0
+if tokens >= n then
+	tokens = tokens - n
+	allowed = 1
+else
+	retry = math.ceil((n - tokens) / rate)
+end
+local reset = math.ceil((capacity - tokens) / rate)
+redis.call('hset', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('pexpire', KEYS[1], math.max(reset, 1))
+return {allowed, math.floor(tokens), retry, reset}
+`)
+)
+
+// RateLimiter 限流操作接口
+type RateLimiter interface {
+	NewLimiter(name string, limit Limit) (*Limiter, error)
+}
+
+// Limit 限流规则
+type Limit struct {
+	Algorithm string        // 限流算法：fixed_window, sliding_window, token_bucket（默认：fixed_window）
+	Rate      int64         // 每个周期允许的请求数（令牌桶为每个周期补充的令牌数）
+	Period    time.Duration // 周期时长（最小1毫秒）
+	Burst     int64         // 令牌桶容量（默认：Rate，仅令牌桶使用）
+}
+
+// PerSecond 创建每秒允许rate个请求的固定窗口规则
+func PerSecond(rate int64) Limit {
+	return Limit{Algorithm: LimitFixedWindow, Rate: rate, Period: time.Second}
+}
+
+// PerMinute 创建每分钟允许rate个请求的固定窗口规则
+func PerMinute(rate int64) Limit {
+	return Limit{Algorithm: LimitFixedWindow, Rate: rate, Period: time.Minute}
+}
+
+// PerHour 创建每小时允许rate个请求的固定窗口规则
+func PerHour(rate int64) Limit {
+	return Limit{Algorithm: LimitFixedWindow, Rate: rate, Period: time.Hour}
+}
+
+// validate 验证限流规则
+func (l *Limit) validate() error {
+	if l.Rate <= 0 {
+		return errors.New("限流请求数必须大于0")
+	}
+	if l.Period < time.Millisecond {
+		return errors.New("限流周期不能小于1毫秒")
+	}
+	if l.Burst < 0 {
+		return errors.New("令牌桶容量不能为负数")
+	}
+	switch l.Algorithm {
+	case "", LimitFixedWindow, LimitSlidingWindow, LimitTokenBucket:
+	default:
+		return fmt.Errorf("不支持的限流算法: %s", l.Algorithm)
+	}
+	return nil
+}
+
+// capacity 返回单次请求允许的最大数量
+func (l *Limit) capacity() int64 {
+	if l.Algorithm == LimitTokenBucket && l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Rate
+}
+
+// LimitResult 限流结果
+type LimitResult struct {
+	Allowed    bool          // 是否允许
+	Limit      int64         // 周期请求数上限（令牌桶为容量）
+	Remaining  int64         // 剩余请求数
+	RetryAfter time.Duration // 被拒绝时建议的重试等待时间（允许时为0）
+	ResetAfter time.Duration // 限流状态完全恢复的剩余时间
+}
+
+// Limiter 分布式限流器
+// 每个身份标识（用户ID、IP、API Key等）使用独立的键：RATELIMIT:{name}:{identity}
+// 每次判断通过一个Lua脚本在单次往返中原子完成
+type Limiter struct {
+	client *Client
+	name   string
+	limit  Limit
+}
+
+// NewLimiter 创建限流器
+// name 为限流器名称，用于区分不同的限流场景（如 "api:login"）
+func (c *Client) NewLimiter(name string, limit Limit) (*Limiter, error) {
+	if name == "" {
+		return nil, errors.New("限流器名称不能为空")
+	}
+	if err := limit.validate(); err != nil {
+		return nil, err
+	}
+	if limit.Algorithm == "" {
+		limit.Algorithm = LimitFixedWindow
+	}
+	return &Limiter{client: c, name: name, limit: limit}, nil
+}
+
+// Key 获取身份标识对应的限流键
+func (l *Limiter) Key(identity string) string {
+	return l.client.key(limiterKeyPrefix + l.name + ":" + identity)
+}
+
+// Allow 判断身份标识的一次请求是否允许
+func (l *Limiter) Allow(ctx context.Context, identity string) (*LimitResult, error) {
+	return l.AllowN(ctx, identity, 1)
+}
+
+// AllowN 判断身份标识的n次请求是否允许，拒绝时不消耗配额
+func (l *Limiter) AllowN(ctx context.Context, identity string, n int64) (*LimitResult, error) {
+	if identity == "" {
+		return nil, errors.New("身份标识不能为空")
+	}
+	if n <= 0 {
+		return nil, errors.New("请求数必须大于0")
+	}
+
+	capacity := l.limit.capacity()
+	if n > capacity {
+		return nil, fmt.Errorf("请求数不能超过上限%d", capacity)
+	}
+
+	key := l.Key(identity)
+	period := l.limit.Period.Milliseconds()
+
+	var cmd *redis.Cmd
+	switch l.limit.Algorithm {
+	case LimitSlidingWindow:
+		cmd = limitSlidingWindowScript.Run(ctx, l.client.UniversalClient, []string{key}, l.limit.Rate, period, n, randomToken())
+	case LimitTokenBucket:
+		cmd = limitTokenBucketScript.Run(ctx, l.client.UniversalClient, []string{key}, l.limit.Rate, period, n, capacity)
+	default:
+		cmd = limitFixedWindowScript.Run(ctx, l.client.UniversalClient, []string{key}, l.limit.Rate, period, n)
+	}
+
+	values, err := cmd.Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("限流判断失败: %w", err)
+	}
+	if len(values) != 4 {
+		return nil, errors.New("结果格式错误")
+	}
+
+	return &LimitResult{
+		Allowed:    values[0] == 1,
+		Limit:      capacity,
+		Remaining:  values[1],
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+		ResetAfter: time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
+
+// Reset 重置身份标识的限流状态
+func (l *Limiter) Reset(ctx context.Context, identity string) error {
+	if identity == "" {
+		return errors.New("身份标识不能为空")
+	}
+	return l.client.UniversalClient.Del(ctx, l.Key(identity)).Err()
+}
@@ -0,0 +1,150 @@
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nilchaosky/go-nexus/redis"
+	"github.com/nilchaosky/go-nexus/redis/redistest"
+)
+
+// TestLimiterValidate 测试限流规则校验
+func TestLimiterValidate(t *testing.T) {
+	client, _ := redistest.NewClient(t)
+
+	tests := []struct {
+		name  string
+		limit redis.Limit
+	}{
+		{"请求数为0", redis.Limit{Rate: 0, Period: time.Second}},
+		{"周期过短", redis.Limit{Rate: 1, Period: time.Microsecond}},
+		{"容量为负数", redis.Limit{Algorithm: redis.LimitTokenBucket, Rate: 1, Period: time.Second, Burst: -1}},
+		{"未知算法", redis.Limit{Algorithm: "leaky", Rate: 1, Period: time.Second}},
+	}
+	for _, tt := range tests {
+		if _, err := client.NewLimiter("api", tt.limit); err == nil {
+			t.Errorf("%s: 期望校验失败", tt.name)
+		}
+	}
+	if _, err := client.NewLimiter("", redis.PerSecond(1)); err == nil {
+		t.Error("期望名称为空时失败")
+	}
+}
+
+// TestLimiterFixedWindow 测试固定窗口：超出上限被拒绝且不计数，窗口结束后恢复
+func TestLimiterFixedWindow(t *testing.T) {
+	ctx := context.Background()
+	client, server := redistest.NewClient(t)
+
+	limiter, err := client.NewLimiter("login", redis.PerSecond(3))
+	if err != nil {
+		t.Fatalf("NewLimiter失败: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		result, err := limiter.Allow(ctx, "u1")
+		if err != nil || !result.Allowed || result.Remaining != int64(2-i) {
+			t.Fatalf("第%d次请求期望允许且剩余%d，实际: %+v, %v", i+1, 2-i, result, err)
+		}
+	}
+
+	server.Clock().Advance(400 * time.Millisecond)
+	result, err := limiter.Allow(ctx, "u1")
+	if err != nil || result.Allowed || result.Remaining != 0 || result.RetryAfter != 600*time.Millisecond {
+		t.Fatalf("期望被拒绝且600ms后重试，实际: %+v, %v", result, err)
+	}
+	if result.Limit != 3 || result.ResetAfter != 600*time.Millisecond {
+		t.Errorf("上限或重置时间不符: %+v", result)
+	}
+
+	// 不同身份标识互不影响
+	if result, _ := limiter.Allow(ctx, "u2"); !result.Allowed {
+		t.Error("期望其他身份标识的请求被允许")
+	}
+	if _, err := limiter.AllowN(ctx, "u1", 4); err == nil {
+		t.Error("期望请求数超过上限时返回错误")
+	}
+
+	server.Clock().Advance(600 * time.Millisecond)
+	if result, err := limiter.AllowN(ctx, "u1", 3); err != nil || !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("期望新窗口允许3个请求，实际: %+v, %v", result, err)
+	}
+
+	if err := limiter.Reset(ctx, "u1"); err != nil {
+		t.Fatalf("Reset失败: %v", err)
+	}
+	if result, _ := limiter.Allow(ctx, "u1"); !result.Allowed || result.Remaining != 2 {
+		t.Errorf("期望重置后恢复配额，实际: %+v", result)
+	}
+}
+
+// TestLimiterSlidingWindow 测试滑动窗口：按最早请求的时间计算重试等待
+func TestLimiterSlidingWindow(t *testing.T) {
+	ctx := context.Background()
+	client, server := redistest.NewClient(t)
+
+	limiter, err := client.NewLimiter("search", redis.Limit{Algorithm: redis.LimitSlidingWindow, Rate: 2, Period: 10 * time.Second})
+	if err != nil {
+		t.Fatalf("NewLimiter失败: %v", err)
+	}
+
+	if result, err := limiter.Allow(ctx, "ip"); err != nil || !result.Allowed || result.Remaining != 1 {
+		t.Fatalf("期望第1次请求被允许，实际: %+v, %v", result, err)
+	}
+	server.Clock().Advance(4 * time.Second)
+	if result, err := limiter.Allow(ctx, "ip"); err != nil || !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("期望第2次请求被允许，实际: %+v, %v", result, err)
+	}
+
+	// 最早的请求在6s后移出窗口
+	server.Clock().Advance(4 * time.Second)
+	result, err := limiter.Allow(ctx, "ip")
+	if err != nil || result.Allowed || result.RetryAfter != 2*time.Second || result.ResetAfter != 2*time.Second {
+		t.Fatalf("期望被拒绝且2s后重试，实际: %+v, %v", result, err)
+	}
+
+	// 需要2个名额时等待第2个请求移出窗口
+	result, err = limiter.AllowN(ctx, "ip", 2)
+	if err != nil || result.Allowed || result.RetryAfter != 6*time.Second {
+		t.Fatalf("期望被拒绝且6s后重试，实际: %+v, %v", result, err)
+	}
+
+	server.Clock().Advance(2 * time.Second)
+	if result, err := limiter.Allow(ctx, "ip"); err != nil || !result.Allowed || result.Remaining != 0 {
+		t.Errorf("期望最早请求移出窗口后被允许，实际: %+v, %v", result, err)
+	}
+}
+
+// TestLimiterTokenBucket 测试令牌桶：按速率补充令牌，容量限制突发请求
+func TestLimiterTokenBucket(t *testing.T) {
+	ctx := context.Background()
+	client, server := redistest.NewClient(t)
+
+	limiter, err := client.NewLimiter("upload", redis.Limit{Algorithm: redis.LimitTokenBucket, Rate: 1, Period: time.Second, Burst: 3})
+	if err != nil {
+		t.Fatalf("NewLimiter失败: %v", err)
+	}
+
+	if result, err := limiter.AllowN(ctx, "u1", 3); err != nil || !result.Allowed || result.Remaining != 0 || result.Limit != 3 {
+		t.Fatalf("期望突发3个请求被允许，实际: %+v, %v", result, err)
+	}
+	result, err := limiter.Allow(ctx, "u1")
+	if err != nil || result.Allowed || result.RetryAfter != time.Second || result.ResetAfter != 3*time.Second {
+		t.Fatalf("期望被拒绝且1s后重试，实际: %+v, %v", result, err)
+	}
+
+	server.Clock().Advance(2500 * time.Millisecond)
+	if result, err := limiter.AllowN(ctx, "u1", 2); err != nil || !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("期望补充2.5个令牌后允许2个请求，实际: %+v, %v", result, err)
+	}
+	if result, _ := limiter.Allow(ctx, "u1"); result.Allowed || result.RetryAfter != 500*time.Millisecond {
+		t.Errorf("期望剩余半个令牌时500ms后重试，实际: %+v", result)
+	}
+
+	// 长时间空闲后令牌数不超过容量
+	server.Clock().Advance(time.Hour)
+	if result, _ := limiter.Allow(ctx, "u1"); !result.Allowed || result.Remaining != 2 {
+		t.Errorf("期望令牌桶已填满，实际: %+v", result)
+	}
+}
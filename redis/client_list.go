@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"github.com/nilchaosky/go-nexus/nexus_utils"
-	"github.com/nilchaosky/go-nexus/serialize"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -48,7 +47,11 @@ type List interface {
 
 // BLPop 阻塞式从列表左侧弹出元素
 func (c *Client) BLPop(ctx context.Context, timeout time.Duration, keys ...string) ([]string, error) {
-	return c.UniversalClient.BLPop(ctx, timeout, keys...).Result()
+	results, err := wrapResult(c.UniversalClient.BLPop(ctx, timeout, c.keys(keys)...).Result())
+	if len(results) > 0 {
+		results[0] = c.stripKey(results[0])
+	}
+	return results, err
 }
 
 // BLPopStruct 阻塞式从列表左侧弹出元素，反序列化到结构体
@@ -69,10 +72,10 @@ func (c *Client) BLPopStruct(ctx context.Context, timeout time.Duration, value i
 
 	data := results[1]
 	if data == "" {
-		return errors.New("键值为空")
+		return ErrEmptyValue
 	}
 
-	if err := serialize.JSONIter.Unmarshal([]byte(data), value); err != nil {
+	if err := c.decode([]byte(data), value); err != nil {
 		return err
 	}
 
@@ -81,7 +84,11 @@ func (c *Client) BLPopStruct(ctx context.Context, timeout time.Duration, value i
 
 // BRPop 阻塞式从列表右侧弹出元素
 func (c *Client) BRPop(ctx context.Context, timeout time.Duration, keys ...string) ([]string, error) {
-	return c.UniversalClient.BRPop(ctx, timeout, keys...).Result()
+	results, err := wrapResult(c.UniversalClient.BRPop(ctx, timeout, c.keys(keys)...).Result())
+	if len(results) > 0 {
+		results[0] = c.stripKey(results[0])
+	}
+	return results, err
 }
 
 // BRPopStruct 阻塞式从列表右侧弹出元素，反序列化到结构体
@@ -102,10 +109,10 @@ func (c *Client) BRPopStruct(ctx context.Context, timeout time.Duration, value i
 
 	data := results[1]
 	if data == "" {
-		return errors.New("键值为空")
+		return ErrEmptyValue
 	}
 
-	if err := serialize.JSONIter.Unmarshal([]byte(data), value); err != nil {
+	if err := c.decode([]byte(data), value); err != nil {
 		return err
 	}
 
@@ -114,7 +121,7 @@ func (c *Client) BRPopStruct(ctx context.Context, timeout time.Duration, value i
 
 // BRPopLPush 阻塞式从源列表右侧弹出元素并推入目标列表左侧
 func (c *Client) BRPopLPush(ctx context.Context, source, destination string, timeout time.Duration) (string, error) {
-	return c.UniversalClient.BRPopLPush(ctx, source, destination, timeout).Result()
+	return wrapResult(c.UniversalClient.BRPopLPush(ctx, c.key(source), c.key(destination), timeout).Result())
 }
 
 // BRPopLPushStruct 阻塞式从源列表右侧弹出元素并推入目标列表左侧，反序列化到结构体
@@ -126,7 +133,7 @@ func (c *Client) BRPopLPushStruct(ctx context.Context, source, destination strin
 
 // LIndex 获取列表中指定索引的元素
 func (c *Client) LIndex(ctx context.Context, key string, index int64) (string, error) {
-	return c.UniversalClient.LIndex(ctx, key, index).Result()
+	return wrapResult(c.UniversalClient.LIndex(ctx, c.key(key), index).Result())
 }
 
 // LIndexStruct 获取列表中指定索引的元素并反序列化到结构体
@@ -138,7 +145,11 @@ func (c *Client) LIndexStruct(ctx context.Context, key string, index int64, valu
 
 // LInsert 在列表的指定元素前或后插入新元素
 func (c *Client) LInsert(ctx context.Context, key, op string, pivot, value interface{}) (int64, error) {
-	return c.UniversalClient.LInsert(ctx, key, op, pivot, value).Result()
+	values, err := c.marshalValues([]interface{}{pivot, value})
+	if err != nil {
+		return 0, err
+	}
+	return wrapResult(c.UniversalClient.LInsert(ctx, c.key(key), op, values[0], values[1]).Result())
 }
 
 // LInsertBefore 在列表的指定元素前插入新元素
@@ -153,12 +164,12 @@ func (c *Client) LInsertAfter(ctx context.Context, key string, pivot, value inte
 
 // LLen 获取列表长度
 func (c *Client) LLen(ctx context.Context, key string) (int64, error) {
-	return c.UniversalClient.LLen(ctx, key).Result()
+	return wrapResult(c.UniversalClient.LLen(ctx, c.key(key)).Result())
 }
 
 // LPop 从列表左侧弹出元素
 func (c *Client) LPop(ctx context.Context, key string) (string, error) {
-	return c.UniversalClient.LPop(ctx, key).Result()
+	return wrapResult(c.UniversalClient.LPop(ctx, c.key(key)).Result())
 }
 
 // LPopStruct 从列表左侧弹出元素并反序列化到结构体
@@ -170,7 +181,7 @@ func (c *Client) LPopStruct(ctx context.Context, key string, value interface{})
 
 // LPopCount 从列表左侧弹出N个元素
 func (c *Client) LPopCount(ctx context.Context, key string, count int) ([]string, error) {
-	return c.UniversalClient.LPopCount(ctx, key, count).Result()
+	return wrapResult(c.UniversalClient.LPopCount(ctx, c.key(key), count).Result())
 }
 
 // LPopCountStruct 从列表左侧弹出N个元素并反序列化到结构体切片
@@ -182,22 +193,30 @@ func (c *Client) LPopCountStruct(ctx context.Context, key string, count int, val
 
 // LPos 获取列表中元素的位置
 func (c *Client) LPos(ctx context.Context, key string, value string, args redis.LPosArgs) (int64, error) {
-	return c.UniversalClient.LPos(ctx, key, value, args).Result()
+	return wrapResult(c.UniversalClient.LPos(ctx, c.key(key), value, args).Result())
 }
 
 // LPush 从列表左侧推入元素
 func (c *Client) LPush(ctx context.Context, key string, values ...interface{}) (int64, error) {
-	return c.UniversalClient.LPush(ctx, key, values...).Result()
+	values, err := c.marshalValues(values)
+	if err != nil {
+		return 0, err
+	}
+	return wrapResult(c.UniversalClient.LPush(ctx, c.key(key), values...).Result())
 }
 
 // LPushX 仅在列表存在时从左侧推入元素
 func (c *Client) LPushX(ctx context.Context, key string, values ...interface{}) (int64, error) {
-	return c.UniversalClient.LPushX(ctx, key, values...).Result()
+	values, err := c.marshalValues(values)
+	if err != nil {
+		return 0, err
+	}
+	return wrapResult(c.UniversalClient.LPushX(ctx, c.key(key), values...).Result())
 }
 
 // LRange 获取列表中指定范围的元素
 func (c *Client) LRange(ctx context.Context, key string, start, end int64) ([]string, error) {
-	return c.UniversalClient.LRange(ctx, key, start, end).Result()
+	return wrapResult(c.UniversalClient.LRange(ctx, c.key(key), start, end).Result())
 }
 
 // LRangeStruct 获取列表中指定范围的元素并反序列化到结构体切片
@@ -209,22 +228,30 @@ func (c *Client) LRangeStruct(ctx context.Context, key string, start, end int64,
 
 // LRem 从列表中移除指定元素
 func (c *Client) LRem(ctx context.Context, key string, count int64, value interface{}) (int64, error) {
-	return c.UniversalClient.LRem(ctx, key, count, value).Result()
+	data, err := c.marshalValue(value)
+	if err != nil {
+		return 0, err
+	}
+	return wrapResult(c.UniversalClient.LRem(ctx, c.key(key), count, data).Result())
 }
 
 // LSet 设置列表中指定索引的元素
 func (c *Client) LSet(ctx context.Context, key string, index int64, value interface{}) error {
-	return c.UniversalClient.LSet(ctx, key, index, value).Err()
+	data, err := c.marshalValue(value)
+	if err != nil {
+		return err
+	}
+	return wrapError(c.UniversalClient.LSet(ctx, c.key(key), index, data).Err())
 }
 
 // LTrim 修剪列表，只保留指定范围的元素
 func (c *Client) LTrim(ctx context.Context, key string, start, end int64) error {
-	return c.UniversalClient.LTrim(ctx, key, start, end).Err()
+	return wrapError(c.UniversalClient.LTrim(ctx, c.key(key), start, end).Err())
 }
 
 // RPop 从列表右侧弹出元素
 func (c *Client) RPop(ctx context.Context, key string) (string, error) {
-	return c.UniversalClient.RPop(ctx, key).Result()
+	return wrapResult(c.UniversalClient.RPop(ctx, c.key(key)).Result())
 }
 
 // RPopStruct 从列表右侧弹出元素并反序列化到结构体
@@ -236,7 +263,7 @@ func (c *Client) RPopStruct(ctx context.Context, key string, value interface{})
 
 // RPopCount 从列表右侧弹出N个元素
 func (c *Client) RPopCount(ctx context.Context, key string, count int) ([]string, error) {
-	return c.UniversalClient.RPopCount(ctx, key, count).Result()
+	return wrapResult(c.UniversalClient.RPopCount(ctx, c.key(key), count).Result())
 }
 
 // RPopCountStruct 从列表右侧弹出N个元素并反序列化到结构体切片
@@ -248,7 +275,7 @@ func (c *Client) RPopCountStruct(ctx context.Context, key string, count int, val
 
 // RPopLPush 从源列表右侧弹出元素并推入目标列表左侧
 func (c *Client) RPopLPush(ctx context.Context, source, destination string) (string, error) {
-	return c.UniversalClient.RPopLPush(ctx, source, destination).Result()
+	return wrapResult(c.UniversalClient.RPopLPush(ctx, c.key(source), c.key(destination)).Result())
 }
 
 // RPopLPushStruct 从源列表右侧弹出元素并推入目标列表左侧，反序列化到结构体
@@ -260,10 +287,18 @@ func (c *Client) RPopLPushStruct(ctx context.Context, source, destination string
 
 // RPush 从列表右侧推入元素
 func (c *Client) RPush(ctx context.Context, key string, values ...interface{}) (int64, error) {
-	return c.UniversalClient.RPush(ctx, key, values...).Result()
+	values, err := c.marshalValues(values)
+	if err != nil {
+		return 0, err
+	}
+	return wrapResult(c.UniversalClient.RPush(ctx, c.key(key), values...).Result())
 }
 
 // RPushX 仅在列表存在时从右侧推入元素
 func (c *Client) RPushX(ctx context.Context, key string, values ...interface{}) (int64, error) {
-	return c.UniversalClient.RPushX(ctx, key, values...).Result()
+	values, err := c.marshalValues(values)
+	if err != nil {
+		return 0, err
+	}
+	return wrapResult(c.UniversalClient.RPushX(ctx, c.key(key), values...).Result())
 }
@@ -0,0 +1,375 @@
+package redis
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nilchaosky/go-nexus/logz"
+	"github.com/nilchaosky/go-nexus/nexus_utils"
+	"github.com/nilchaosky/go-nexus/serialize"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// localCacheChannel 默认失效广播频道
+const localCacheChannel = "NEXUS:CACHE:INVALIDATE"
+
+// LocalCacheOption 二级缓存选项
+type LocalCacheOption func(*LocalCache)
+
+// LocalCacheSize 设置本地缓存最大条目数（默认：10000）
+func LocalCacheSize(size int) LocalCacheOption {
+	return func(l *LocalCache) {
+		if size > 0 {
+			l.size = size
+		}
+	}
+}
+
+// LocalCacheTTL 设置本地缓存过期时间（默认：1分钟），同时是错过失效广播时的最长不一致时间
+func LocalCacheTTL(ttl time.Duration) LocalCacheOption {
+	return func(l *LocalCache) {
+		if ttl > 0 {
+			l.ttl = ttl
+		}
+	}
+}
+
+// LocalCacheChannel 设置失效广播频道（默认：NEXUS:CACHE:INVALIDATE），共享同一频道的实例互相失效
+func LocalCacheChannel(channel string) LocalCacheOption {
+	return func(l *LocalCache) {
+		if channel != "" {
+			l.channel = channel
+		}
+	}
+}
+
+// LocalCacheStats 二级缓存命中统计
+type LocalCacheStats struct {
+	LocalHits     int64 // 本地缓存命中次数
+	LocalMisses   int64 // 本地缓存未命中次数
+	RemoteHits    int64 // Redis缓存命中次数
+	RemoteMisses  int64 // Redis缓存未命中次数
+	Evictions     int64 // 本地缓存淘汰次数
+	Invalidations int64 // 收到的失效广播键数量
+}
+
+// localEntry 本地缓存条目
+type localEntry struct {
+	key      string
+	loaded   *cacheLoaded
+	expireAt time.Time
+}
+
+// invalidateMessage 失效广播消息
+type invalidateMessage struct {
+	Source string   `json:"source"` // 发送方实例标识
+	Keys   []string `json:"keys"`   // 失效的键
+}
+
+// LocalCache 二级缓存：进程内LRU缓存 + Redis缓存
+// 写入或删除时通过 Redis 发布订阅广播失效消息，所有实例删除本地副本
+type LocalCache struct {
+	client  *Client
+	size    int
+	ttl     time.Duration
+	channel string
+	id      string // 实例标识，用于忽略自身发出的失效消息
+
+	mu      sync.Mutex
+	gen     uint64 // 失效代数，每次删除本地缓存时递增
+	items   map[string]*list.Element
+	order   *list.List
+	pubsub  *redis.PubSub
+	stopped chan struct{}
+
+	localHits     atomic.Int64
+	localMisses   atomic.Int64
+	remoteHits    atomic.Int64
+	remoteMisses  atomic.Int64
+	evictions     atomic.Int64
+	invalidations atomic.Int64
+}
+
+// NewLocalCache 创建二级缓存并订阅失效广播
+func (c *Client) NewLocalCache(ctx context.Context, opts ...LocalCacheOption) (*LocalCache, error) {
+	l := &LocalCache{
+		client:  c,
+		size:    10000,
+		ttl:     time.Minute,
+		channel: localCacheChannel,
+		id:      randomToken(),
+		items:   make(map[string]*list.Element),
+		order:   list.New(),
+		stopped: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	pubsub := c.UniversalClient.Subscribe(ctx, l.channel)
+	// 等待订阅确认，确保之后的失效消息不会丢失
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+	l.pubsub = pubsub
+
+	go l.listen()
+
+	return l, nil
+}
+
+// GetStruct 获取键值并反序列化到结构体，优先读取本地缓存
+// 键不存在时返回 ErrNotFound
+func (l *LocalCache) GetStruct(ctx context.Context, key string, value interface{}) error {
+	if _, err := nexus_utils.IsPointer(value); err != nil {
+		return err
+	}
+
+	key = l.client.key(key)
+	if loaded, ok := l.get(key); ok {
+		l.localHits.Add(1)
+		return l.client.cacheDecode(loaded, value)
+	}
+	l.localMisses.Add(1)
+
+	gen := l.generation()
+	data, err := wrapResult(l.client.UniversalClient.Get(ctx, key).Bytes())
+	if IsNotFound(err) {
+		l.remoteMisses.Add(1)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	l.remoteHits.Add(1)
+
+	loaded := &cacheLoaded{data: data, notFound: string(data) == cacheNotFoundValue, hit: true}
+	l.set(key, loaded, gen)
+
+	return l.client.cacheDecode(loaded, value)
+}
+
+// Cache 缓存方法：依次从本地缓存、Redis获取，都不存在时执行fn加载并写入两级缓存，加载后广播失效消息
+// 返回值 hit 为 true 表示从本地缓存或Redis读取，false 表示执行了fn加载
+func (l *LocalCache) Cache(ctx context.Context, key string, value interface{}, expiration time.Duration, fn func() (interface{}, error), opts ...CacheOption) (bool, error) {
+	if _, err := nexus_utils.IsPointer(value); err != nil {
+		return false, err
+	}
+
+	full := l.client.key(key)
+	if loaded, ok := l.get(full); ok {
+		l.localHits.Add(1)
+		return true, l.client.cacheDecode(loaded, value)
+	}
+	l.localMisses.Add(1)
+
+	gen := l.generation()
+	loaded, err := l.client.cacheFetch(ctx, key, expiration, fn, opts)
+	if err != nil {
+		return false, err
+	}
+
+	if loaded.hit {
+		l.remoteHits.Add(1)
+	} else {
+		l.remoteMisses.Add(1)
+		// 加载结果已写入Redis，通知其他实例丢弃旧的本地缓存
+		if err := l.publish(ctx, full); err != nil {
+			logz.Logger.Warn("广播缓存失效消息失败",
+				zap.String("key", full),
+				zap.Error(err),
+			)
+		}
+	}
+	l.set(full, loaded, gen)
+
+	return loaded.hit, l.client.cacheDecode(loaded, value)
+}
+
+// SetEX 写入两级缓存并广播失效消息
+func (l *LocalCache) SetEX(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	data, err := l.client.marshal(value)
+	if err != nil {
+		return err
+	}
+
+	key = l.client.key(key)
+	if err := l.client.UniversalClient.Set(ctx, key, data, expiration).Err(); err != nil {
+		return err
+	}
+
+	gen := l.generation()
+	if err := l.publish(ctx, key); err != nil {
+		return err
+	}
+	l.set(key, &cacheLoaded{data: data}, gen)
+
+	return nil
+}
+
+// Del 删除两级缓存并广播失效消息
+func (l *LocalCache) Del(ctx context.Context, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	keys = l.client.keys(keys)
+	l.remove(keys...)
+	count, err := l.client.UniversalClient.Del(ctx, keys...).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	return count, l.publish(ctx, keys...)
+}
+
+// Invalidate 仅删除所有实例的本地缓存（不删除Redis中的数据）
+func (l *LocalCache) Invalidate(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	keys = l.client.keys(keys)
+	l.remove(keys...)
+	return l.publish(ctx, keys...)
+}
+
+// Stats 获取命中统计
+func (l *LocalCache) Stats() LocalCacheStats {
+	return LocalCacheStats{
+		LocalHits:     l.localHits.Load(),
+		LocalMisses:   l.localMisses.Load(),
+		RemoteHits:    l.remoteHits.Load(),
+		RemoteMisses:  l.remoteMisses.Load(),
+		Evictions:     l.evictions.Load(),
+		Invalidations: l.invalidations.Load(),
+	}
+}
+
+// Len 获取本地缓存条目数
+func (l *LocalCache) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
+
+// Close 取消订阅并清空本地缓存
+func (l *LocalCache) Close() error {
+	err := l.pubsub.Close()
+	<-l.stopped
+
+	l.mu.Lock()
+	l.items = make(map[string]*list.Element)
+	l.order.Init()
+	l.mu.Unlock()
+
+	return err
+}
+
+// listen 处理失效广播消息
+func (l *LocalCache) listen() {
+	defer close(l.stopped)
+
+	for msg := range l.pubsub.Channel() {
+		var message invalidateMessage
+		if err := serialize.JSONIter.Unmarshal([]byte(msg.Payload), &message); err != nil {
+			logz.Logger.Warn("解析缓存失效消息失败",
+				zap.String("channel", msg.Channel),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		if message.Source == l.id {
+			continue
+		}
+
+		l.invalidations.Add(int64(len(message.Keys)))
+		l.remove(message.Keys...)
+	}
+}
+
+// publish 广播失效消息
+func (l *LocalCache) publish(ctx context.Context, keys ...string) error {
+	payload, err := serialize.JSONIter.Marshal(invalidateMessage{Source: l.id, Keys: keys})
+	if err != nil {
+		return err
+	}
+	return l.client.UniversalClient.Publish(ctx, l.channel, payload).Err()
+}
+
+// get 读取本地缓存，过期条目视为未命中并删除
+func (l *LocalCache) get(key string) (*cacheLoaded, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*localEntry)
+	if time.Now().After(entry.expireAt) {
+		l.order.Remove(elem)
+		delete(l.items, key)
+		return nil, false
+	}
+
+	l.order.MoveToFront(elem)
+	return entry.loaded, true
+}
+
+// generation 获取当前失效代数
+func (l *LocalCache) generation() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.gen
+}
+
+// set 写入本地缓存，超出容量时淘汰最久未使用的条目
+// 读取数据期间发生过失效（代数变化）时不写入，避免缓存过期数据
+func (l *LocalCache) set(key string, loaded *cacheLoaded, gen uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.gen != gen {
+		return
+	}
+
+	expireAt := time.Now().Add(l.ttl)
+	if elem, ok := l.items[key]; ok {
+		entry := elem.Value.(*localEntry)
+		entry.loaded = loaded
+		entry.expireAt = expireAt
+		l.order.MoveToFront(elem)
+		return
+	}
+
+	l.items[key] = l.order.PushFront(&localEntry{key: key, loaded: loaded, expireAt: expireAt})
+
+	for l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.items, oldest.Value.(*localEntry).key)
+		l.evictions.Add(1)
+	}
+}
+
+// remove 删除本地缓存
+func (l *LocalCache) remove(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.gen++
+	for _, key := range keys {
+		if elem, ok := l.items[key]; ok {
+			l.order.Remove(elem)
+			delete(l.items, key)
+		}
+	}
+}
@@ -0,0 +1,359 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/nilchaosky/go-nexus/logz"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrLockNotAcquired 获取锁失败（等待超时或锁被占用）
+	ErrLockNotAcquired = errors.New("获取锁失败")
+	// ErrLockNotHeld 锁未被当前持有者持有（已过期或被他人占用）
+	ErrLockNotHeld = errors.New("锁未被持有")
+)
+
+var (
+	// lockAcquireScript 获取锁：锁不存在或由当前持有者持有时重入计数加1
+	// 首次获取时递增防护令牌计数器（计数器永久保存，保证令牌单调递增）
+	// KEYS[1] 锁键 KEYS[2] 防护令牌键 ARGV[1] 过期时间（毫秒） ARGV[2] 持有者令牌
+	// 返回 {1, 防护令牌} 表示成功，{0, 剩余过期时间} 表示锁被占用
+	lockAcquireScript = redis.NewScript(`
+if redis.call('exists', KEYS[1]) == 0 then
+	local fence = redis.call('incr', KEYS[2])
+	redis.call('hset', KEYS[1], ARGV[2], 1, 'fence', fence)
+	redis.call('pexpire', KEYS[1], ARGV[1])
+	return {1, fence}
+end
+if redis.call('hexists', KEYS[1], ARGV[2]) == 1 then
+	redis.call('hincrby', KEYS[1], ARGV[2], 1)
+	redis.call('pexpire', KEYS[1], ARGV[1])
+	return {1, tonumber(redis.call('hget', KEYS[1], 'fence'))}
+end
+return {0, redis.call('pttl', KEYS[1])}
+`)
+
+	// lockReleaseScript 释放锁：仅持有者可释放，重入计数减为0时删除锁
+	// KEYS[1] 锁键 ARGV[1] 过期时间（毫秒） ARGV[2] 持有者令牌
+	// 返回 -1 表示未持有，0 表示已完全释放，大于0表示剩余重入次数
+	lockReleaseScript = redis.NewScript(`
+if redis.call('hexists', KEYS[1], ARGV[2]) == 0 then
+	return -1
+end
+local count = redis.call('hincrby', KEYS[1], ARGV[2], -1)
+if count > 0 then
+	redis.call('pexpire', KEYS[1], ARGV[1])
+	return count
+end
+redis.call('del', KEYS[1])
+return 0
+`)
+
+	// lockRenewScript 续期锁：仅持有者可续期
+	// KEYS[1] 锁键 ARGV[1] 过期时间（毫秒） ARGV[2] 持有者令牌
+	// 返回 1 表示续期成功，0 表示未持有
+	lockRenewScript = redis.NewScript(`
+if redis.call('hexists', KEYS[1], ARGV[2]) == 1 then
+	return redis.call('pexpire', KEYS[1], ARGV[1])
+end
+return 0
+`)
+)
+
+// Locker 分布式锁操作接口
+type Locker interface {
+	NewMutex(key string, opts ...LockOption) *Mutex
+}
+
+// LockOption 分布式锁选项
+type LockOption func(*Mutex)
+
+// LockTTL 设置锁的过期时间（默认：30秒）
+func LockTTL(ttl time.Duration) LockOption {
+	return func(m *Mutex) {
+		if ttl > 0 {
+			m.ttl = ttl
+		}
+	}
+}
+
+// LockWait 设置阻塞获取锁的最长等待时间（默认：0，等待直到ctx结束）
+func LockWait(wait time.Duration) LockOption {
+	return func(m *Mutex) {
+		if wait >= 0 {
+			m.wait = wait
+		}
+	}
+}
+
+// LockRetry 设置重试退避的最小和最大间隔（默认：50毫秒~1秒，指数退避加随机抖动）
+func LockRetry(minBackoff, maxBackoff time.Duration) LockOption {
+	return func(m *Mutex) {
+		if minBackoff > 0 && maxBackoff >= minBackoff {
+			m.minBackoff = minBackoff
+			m.maxBackoff = maxBackoff
+		}
+	}
+}
+
+// LockWatchdog 设置是否启用看门狗自动续期（默认：启用）
+func LockWatchdog(enabled bool) LockOption {
+	return func(m *Mutex) {
+		m.watchdog = enabled
+	}
+}
+
+// Mutex 分布式可重入锁
+// 同一个 Mutex 实例即为同一个持有者，重复 Lock 会增加重入计数，需要对应次数的 Unlock 才会释放
+// 集群模式下锁键和防护令牌键（key + ":fence"）需位于同一槽位，请在key中使用哈希标签，例如 "{order:1}"
+type Mutex struct {
+	client     *Client
+	key        string        // 锁键
+	fenceKey   string        // 防护令牌计数器键
+	token      string        // 持有者令牌
+	ttl        time.Duration // 锁过期时间
+	wait       time.Duration // 最长等待时间
+	minBackoff time.Duration // 最小重试间隔
+	maxBackoff time.Duration // 最大重试间隔
+	watchdog   bool          // 是否启用看门狗
+
+	mu    sync.Mutex
+	count int           // 本地重入计数
+	fence int64         // 当前防护令牌
+	stop  chan struct{} // 停止看门狗
+	lost  chan struct{} // 锁丢失通知
+}
+
+// NewMutex 创建分布式锁
+func (c *Client) NewMutex(key string, opts ...LockOption) *Mutex {
+	key = c.key(key)
+	m := &Mutex{
+		client:     c,
+		key:        key,
+		fenceKey:   key + ":fence",
+		token:      randomToken(),
+		ttl:        30 * time.Second,
+		minBackoff: 50 * time.Millisecond,
+		maxBackoff: time.Second,
+		watchdog:   true,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Key 获取锁键
+func (m *Mutex) Key() string {
+	return m.key
+}
+
+// Token 获取持有者令牌
+func (m *Mutex) Token() string {
+	return m.token
+}
+
+// Fence 获取当前防护令牌
+// 防护令牌随每次首次获取锁单调递增，可传给数据库用于拒绝过期持有者的写入；计数器键不设置过期时间
+func (m *Mutex) Fence() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.fence
+}
+
+// Lost 返回锁丢失通知通道，看门狗续期失败或锁被释放（Unlock 完全释放或返回 ErrLockNotHeld）时关闭
+// 未持有锁时返回nil
+func (m *Mutex) Lost() <-chan struct{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lost
+}
+
+// TryLock 尝试获取锁（不等待）
+func (m *Mutex) TryLock(ctx context.Context) (bool, error) {
+	ok, _, err := m.acquire(ctx)
+	return ok, err
+}
+
+// Lock 阻塞获取锁，直到成功、等待超时或ctx结束
+// 等待超时返回 ErrLockNotAcquired
+func (m *Mutex) Lock(ctx context.Context) error {
+	if m.wait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.wait)
+		defer cancel()
+	}
+
+	backoff := m.minBackoff
+	for {
+		ok, pttl, err := m.acquire(ctx)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return ErrLockNotAcquired
+			}
+			return err
+		}
+		if ok {
+			return nil
+		}
+
+		// 等待时间不超过锁的剩余过期时间
+		delay := backoff/2 + rand.N(backoff/2+1)
+		if pttl > 0 && pttl < delay {
+			delay = pttl
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return ErrLockNotAcquired
+			}
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > m.maxBackoff {
+			backoff = m.maxBackoff
+		}
+	}
+}
+
+// Unlock 释放锁，重入计数减为0时删除锁并停止看门狗
+// 锁已过期或被他人占用时返回 ErrLockNotHeld
+func (m *Mutex) Unlock(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result, err := lockReleaseScript.Run(ctx, m.client.UniversalClient, []string{m.key}, m.ttl.Milliseconds(), m.token).Int64()
+	if err != nil {
+		return fmt.Errorf("释放锁失败: %w", err)
+	}
+
+	if result < 0 {
+		m.reset()
+		return ErrLockNotHeld
+	}
+
+	m.count = int(result)
+	if m.count == 0 {
+		m.reset()
+	}
+
+	return nil
+}
+
+// Refresh 手动续期锁
+// 锁已过期或被他人占用时返回 ErrLockNotHeld
+func (m *Mutex) Refresh(ctx context.Context) error {
+	ok, err := lockRenewScript.Run(ctx, m.client.UniversalClient, []string{m.key}, m.ttl.Milliseconds(), m.token).Bool()
+	if err != nil {
+		return fmt.Errorf("续期锁失败: %w", err)
+	}
+	if !ok {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// acquire 执行一次获取锁，失败时返回锁的剩余过期时间
+func (m *Mutex) acquire(ctx context.Context) (bool, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result, err := lockAcquireScript.Run(ctx, m.client.UniversalClient, []string{m.key, m.fenceKey}, m.ttl.Milliseconds(), m.token).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("获取锁失败: %w", err)
+	}
+
+	if len(result) != 2 {
+		return false, 0, errors.New("结果格式错误")
+	}
+
+	if result[0] == 0 {
+		return false, time.Duration(result[1]) * time.Millisecond, nil
+	}
+
+	m.fence = result[1]
+	m.count++
+	if m.count == 1 {
+		m.lost = make(chan struct{})
+		if m.watchdog {
+			m.stop = make(chan struct{})
+			go m.renew(m.stop, m.lost)
+		}
+	}
+
+	return true, 0, nil
+}
+
+// renew 看门狗：按过期时间的1/3周期续期，续期失败时通知锁丢失
+func (m *Mutex) renew(stop, lost chan struct{}) {
+	ticker := time.NewTicker(m.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), m.ttl/3)
+		err := m.Refresh(ctx)
+		cancel()
+
+		if err == nil {
+			continue
+		}
+
+		// 网络错误时继续重试，锁已被他人占用则停止
+		if !errors.Is(err, ErrLockNotHeld) {
+			logz.Logger.Warn("分布式锁续期失败",
+				zap.String("key", m.key),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		// 已被 Unlock 正常释放时不视为丢失
+		m.mu.Lock()
+		released := m.stop != stop
+		if !released {
+			m.stop = nil
+			m.count = 0
+			m.lost = nil
+		}
+		m.mu.Unlock()
+
+		if !released {
+			logz.Logger.Warn("分布式锁已丢失",
+				zap.String("key", m.key),
+			)
+			close(lost)
+		}
+		return
+	}
+}
+
+// reset 重置本地持有状态，停止看门狗并关闭锁丢失通知通道（调用方需持有m.mu）
+func (m *Mutex) reset() {
+	m.count = 0
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
+	}
+	if m.lost != nil {
+		close(m.lost)
+		m.lost = nil
+	}
+}
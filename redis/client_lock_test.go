@@ -0,0 +1,173 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nilchaosky/go-nexus/redis"
+	"github.com/nilchaosky/go-nexus/redis/redistest"
+)
+
+// TestMutexReentrant 测试重入计数、按次数释放以及防护令牌单调递增
+func TestMutexReentrant(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+
+	m := client.NewMutex("order:1", redis.LockWatchdog(false))
+	other := client.NewMutex("order:1", redis.LockWatchdog(false))
+
+	for i := 0; i < 2; i++ {
+		if err := m.Lock(ctx); err != nil {
+			t.Fatalf("第%d次Lock失败: %v", i+1, err)
+		}
+	}
+	if m.Fence() != 1 {
+		t.Errorf("期望重入不改变防护令牌1，实际: %d", m.Fence())
+	}
+	if ok, err := other.TryLock(ctx); err != nil || ok {
+		t.Fatalf("期望其他持有者获取失败，实际: %v, %v", ok, err)
+	}
+
+	// 重入两次需要释放两次
+	if err := m.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock失败: %v", err)
+	}
+	if m.Lost() == nil {
+		t.Error("期望仍持有锁")
+	}
+	if ok, _ := other.TryLock(ctx); ok {
+		t.Fatal("期望释放一次后锁仍被持有")
+	}
+	lost := m.Lost()
+	if err := m.Unlock(ctx); err != nil {
+		t.Fatalf("Unlock失败: %v", err)
+	}
+	select {
+	case <-lost:
+	default:
+		t.Error("期望完全释放后关闭Lost通道")
+	}
+	if err := m.Unlock(ctx); !errors.Is(err, redis.ErrLockNotHeld) {
+		t.Errorf("期望ErrLockNotHeld，实际: %v", err)
+	}
+
+	if ok, err := other.TryLock(ctx); err != nil || !ok {
+		t.Fatalf("期望释放后其他持有者获取成功，实际: %v, %v", ok, err)
+	}
+	if other.Fence() != 2 {
+		t.Errorf("期望防护令牌递增为2，实际: %d", other.Fence())
+	}
+}
+
+// TestMutexExpire 测试锁过期后被他人获取，原持有者释放时返回 ErrLockNotHeld
+func TestMutexExpire(t *testing.T) {
+	ctx := context.Background()
+	client, server := redistest.NewClient(t)
+
+	m := client.NewMutex("job", redis.LockTTL(10*time.Second), redis.LockWatchdog(false))
+	other := client.NewMutex("job", redis.LockWatchdog(false))
+
+	if err := m.Lock(ctx); err != nil {
+		t.Fatalf("Lock失败: %v", err)
+	}
+	if ttl, _ := client.PTTL(ctx, "job"); ttl != 10*time.Second {
+		t.Errorf("期望锁过期时间为10s，实际: %v", ttl)
+	}
+
+	server.Clock().Advance(9 * time.Second)
+	if err := m.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh失败: %v", err)
+	}
+	server.Clock().Advance(9 * time.Second)
+	if ok, _ := other.TryLock(ctx); ok {
+		t.Fatal("期望续期后锁仍被持有")
+	}
+
+	server.Clock().Advance(2 * time.Second)
+	if ok, err := other.TryLock(ctx); err != nil || !ok {
+		t.Fatalf("期望锁过期后获取成功，实际: %v, %v", ok, err)
+	}
+
+	lost := m.Lost()
+	if err := m.Refresh(ctx); !errors.Is(err, redis.ErrLockNotHeld) {
+		t.Errorf("Refresh期望ErrLockNotHeld，实际: %v", err)
+	}
+	if err := m.Unlock(ctx); !errors.Is(err, redis.ErrLockNotHeld) {
+		t.Errorf("Unlock期望ErrLockNotHeld，实际: %v", err)
+	}
+	select {
+	case <-lost:
+	default:
+		t.Error("期望锁丢失后关闭Lost通道")
+	}
+	if ok, _ := other.TryLock(ctx); !ok {
+		t.Error("期望原持有者释放失败不影响新持有者")
+	}
+}
+
+// TestMutexWait 测试等待超时返回 ErrLockNotAcquired，锁释放后等待者获取成功
+func TestMutexWait(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+
+	holder := client.NewMutex("res", redis.LockWatchdog(false))
+	if err := holder.Lock(ctx); err != nil {
+		t.Fatalf("Lock失败: %v", err)
+	}
+
+	waiter := client.NewMutex("res",
+		redis.LockWatchdog(false),
+		redis.LockWait(100*time.Millisecond),
+		redis.LockRetry(10*time.Millisecond, 20*time.Millisecond),
+	)
+	start := time.Now()
+	if err := waiter.Lock(ctx); !errors.Is(err, redis.ErrLockNotAcquired) {
+		t.Fatalf("期望ErrLockNotAcquired，实际: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("期望等待至超时，实际: %v", elapsed)
+	}
+
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		_ = holder.Unlock(ctx)
+	}()
+	if err := waiter.Lock(ctx); err != nil {
+		t.Fatalf("期望释放后获取成功，实际: %v", err)
+	}
+}
+
+// TestMutexWatchdog 测试看门狗按周期续期，锁被删除后关闭 Lost 通道
+func TestMutexWatchdog(t *testing.T) {
+	ctx := context.Background()
+	client, server := redistest.NewClient(t)
+
+	m := client.NewMutex("task", redis.LockTTL(300*time.Millisecond))
+	if err := m.Lock(ctx); err != nil {
+		t.Fatalf("Lock失败: %v", err)
+	}
+	defer func() { _ = m.Unlock(ctx) }()
+
+	// 时钟前进后剩余100ms，看门狗每100ms续期一次，续期后过期时间恢复为300ms
+	server.Clock().Advance(200 * time.Millisecond)
+	time.Sleep(250 * time.Millisecond)
+	server.Clock().Advance(200 * time.Millisecond)
+	if n, _ := client.Exists(ctx, "task"); n != 1 {
+		t.Fatal("期望看门狗续期后锁仍存在")
+	}
+
+	lost := m.Lost()
+	if _, err := client.Del(ctx, "task"); err != nil {
+		t.Fatalf("Del失败: %v", err)
+	}
+	select {
+	case <-lost:
+	case <-time.After(time.Second):
+		t.Fatal("期望续期失败后关闭Lost通道")
+	}
+	if m.Lost() != nil {
+		t.Error("期望锁丢失后不再持有")
+	}
+}
@@ -0,0 +1,398 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nilchaosky/go-nexus/nexus_utils"
+	"github.com/redis/go-redis/v9"
+)
+
+// txMaxRetries 事务冲突时的最大重试次数
+const txMaxRetries = 10
+
+// ErrTxConflict 事务因监视的键被修改而多次重试后仍失败
+var ErrTxConflict = errors.New("事务冲突")
+
+// Pipe 管道命令集合
+// 命令在 Pipeline 或 Tx.Exec 回调中排队，回调返回后一次性发送；命令结果在执行后通过返回的 Cmd 读取
+// 带 Struct 后缀的命令使用客户端序列化器编码或解码；HSetFields、HGetAllStruct 按 `redis:"name"` 标签映射哈希表字段
+type Pipe struct {
+	client *Client
+	pipe   redis.Pipeliner
+	err    error // 排队阶段的序列化错误
+}
+
+// StructCmd 结构体结果命令，执行后通过 Scan 反序列化
+type StructCmd struct {
+	client *Client
+	cmd    *redis.StringCmd
+}
+
+// Err 获取命令错误，键不存在时返回 ErrNotFound
+func (c *StructCmd) Err() error {
+	return wrapError(c.cmd.Err())
+}
+
+// Scan 将命令结果反序列化到结构体
+func (c *StructCmd) Scan(value interface{}) error {
+	return c.client.unmarshalValue(value, func() (string, error) {
+		return wrapResult(c.cmd.Result())
+	})
+}
+
+// HashStructCmd 哈希表结构体结果命令，执行后通过 Scan 按 `redis:"name"` 标签填充到结构体
+type HashStructCmd struct {
+	client *Client
+	cmd    *redis.MapStringStringCmd
+}
+
+// Err 获取命令错误
+func (c *HashStructCmd) Err() error {
+	return c.cmd.Err()
+}
+
+// Scan 将哈希表字段填充到结构体，哈希表不存在时返回 ErrNotFound
+func (c *HashStructCmd) Scan(value interface{}) error {
+	rv, err := nexus_utils.IsPointer(value)
+	if err != nil {
+		return err
+	}
+
+	results, err := c.cmd.Result()
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return ErrNotFound
+	}
+
+	return c.client.hashToStruct(rv, results)
+}
+
+// Pipeline 管道执行：回调中排队的命令在一次往返中发送（非原子）
+// 返回第一个失败命令的错误，键不存在（redis.Nil）不视为失败，可通过各命令结果判断
+func (c *Client) Pipeline(ctx context.Context, fn func(p *Pipe) error) error {
+	return c.execPipe(ctx, c.UniversalClient.Pipeline(), fn)
+}
+
+// TxPipeline 事务管道执行：回调中排队的命令包装在 MULTI/EXEC 中原子执行
+func (c *Client) TxPipeline(ctx context.Context, fn func(p *Pipe) error) error {
+	return c.execPipe(ctx, c.UniversalClient.TxPipeline(), fn)
+}
+
+// execPipe 排队并执行管道命令
+func (c *Client) execPipe(ctx context.Context, pipe redis.Pipeliner, fn func(p *Pipe) error) error {
+	p := &Pipe{client: c, pipe: pipe}
+
+	if err := fn(p); err != nil {
+		pipe.Discard()
+		return err
+	}
+	if p.err != nil {
+		pipe.Discard()
+		return p.err
+	}
+
+	cmds, err := pipe.Exec(ctx)
+	if err == nil {
+		return nil
+	}
+
+	// 返回第一个非 redis.Nil 的错误
+	for _, cmd := range cmds {
+		if cmdErr := cmd.Err(); cmdErr != nil && !errors.Is(cmdErr, redis.Nil) {
+			return cmdErr
+		}
+	}
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
+
+// Tx 乐观锁事务
+// 读取阶段通过 Tx 的读方法在 WATCH 连接上读取，写入阶段通过 Tx.Exec 在 MULTI/EXEC 中执行
+type Tx struct {
+	client *Client
+	tx     *redis.Tx
+}
+
+// Tx 执行基于 WATCH 的乐观锁事务，监视的键在执行前被修改时自动重试
+// 重试次数耗尽后返回 ErrTxConflict
+func (c *Client) Tx(ctx context.Context, fn func(tx *Tx) error, keys ...string) error {
+	backoff := 10 * time.Millisecond
+
+	for i := 0; i < txMaxRetries; i++ {
+		err := c.UniversalClient.Watch(ctx, func(rtx *redis.Tx) error {
+			return fn(&Tx{client: c, tx: rtx})
+		}, c.keys(keys)...)
+
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+
+		// 监视的键被修改，退避后重试
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+
+	return fmt.Errorf("%w: 重试%d次后仍失败", ErrTxConflict, txMaxRetries)
+}
+
+// Raw 获取原始事务对象
+func (t *Tx) Raw() *redis.Tx {
+	return t.tx
+}
+
+// Get 在监视连接上获取键值，键不存在时返回 ErrNotFound
+func (t *Tx) Get(ctx context.Context, key string) (string, error) {
+	return wrapResult(t.tx.Get(ctx, t.client.key(key)).Result())
+}
+
+// GetStruct 在监视连接上获取键值并反序列化到结构体
+func (t *Tx) GetStruct(ctx context.Context, key string, value interface{}) error {
+	return t.client.unmarshalValue(value, func() (string, error) {
+		return t.Get(ctx, key)
+	})
+}
+
+// HGet 在监视连接上获取哈希表中字段的值，字段不存在时返回 ErrNotFound
+func (t *Tx) HGet(ctx context.Context, key, field string) (string, error) {
+	return wrapResult(t.tx.HGet(ctx, t.client.key(key), field).Result())
+}
+
+// HGetStruct 在监视连接上获取哈希表中字段的值，反序列化到结构体
+func (t *Tx) HGetStruct(ctx context.Context, key, field string, value interface{}) error {
+	return t.client.unmarshalValue(value, func() (string, error) {
+		return t.HGet(ctx, key, field)
+	})
+}
+
+// Exec 在 MULTI/EXEC 中原子执行回调中排队的命令
+// 监视的键已被修改时返回 redis.TxFailedErr，由 Client.Tx 自动重试
+func (t *Tx) Exec(ctx context.Context, fn func(p *Pipe) error) error {
+	return t.client.execPipe(ctx, t.tx.TxPipeline(), fn)
+}
+
+// Raw 获取原始管道对象，用于排队未封装的命令
+func (p *Pipe) Raw() redis.Pipeliner {
+	return p.pipe
+}
+
+// Set 设置键值（expiration为0表示不过期）
+func (p *Pipe) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	data, err := p.client.marshalValue(value)
+	if err != nil {
+		return p.statusErr(ctx, err)
+	}
+	return p.pipe.Set(ctx, p.client.key(key), data, expiration)
+}
+
+// SetStruct 序列化结构体并设置键值（expiration为0表示不过期）
+func (p *Pipe) SetStruct(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	data, err := p.client.marshal(value)
+	if err != nil {
+		return p.statusErr(ctx, fmt.Errorf("序列化失败: %w", err))
+	}
+	return p.pipe.Set(ctx, p.client.key(key), data, expiration)
+}
+
+// SetNX 仅在key不存在时设置（expiration为0表示不过期）
+func (p *Pipe) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.BoolCmd {
+	data, err := p.client.marshalValue(value)
+	if err != nil {
+		cmd := redis.NewBoolCmd(ctx)
+		cmd.SetErr(p.fail(err))
+		return cmd
+	}
+	return p.pipe.SetNX(ctx, p.client.key(key), data, expiration)
+}
+
+// Get 获取键值
+func (p *Pipe) Get(ctx context.Context, key string) *redis.StringCmd {
+	return p.pipe.Get(ctx, p.client.key(key))
+}
+
+// GetStruct 获取键值，执行后通过 StructCmd.Scan 反序列化
+func (p *Pipe) GetStruct(ctx context.Context, key string) *StructCmd {
+	return &StructCmd{client: p.client, cmd: p.pipe.Get(ctx, p.client.key(key))}
+}
+
+// Del 删除一个或多个键
+func (p *Pipe) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	return p.pipe.Del(ctx, p.client.keys(keys)...)
+}
+
+// Expire 设置键的过期时间
+func (p *Pipe) Expire(ctx context.Context, key string, expiration time.Duration) *redis.BoolCmd {
+	return p.pipe.Expire(ctx, p.client.key(key), expiration)
+}
+
+// Incr 递增键值
+func (p *Pipe) Incr(ctx context.Context, key string) *redis.IntCmd {
+	return p.pipe.Incr(ctx, p.client.key(key))
+}
+
+// IncrBy 按指定值递增键值
+func (p *Pipe) IncrBy(ctx context.Context, key string, value int64) *redis.IntCmd {
+	return p.pipe.IncrBy(ctx, p.client.key(key), value)
+}
+
+// HSet 设置哈希表中字段的值
+func (p *Pipe) HSet(ctx context.Context, key string, values ...interface{}) *redis.IntCmd {
+	values, err := p.client.marshalPairs(values)
+	if err != nil {
+		return p.intErr(ctx, err)
+	}
+	return p.pipe.HSet(ctx, p.client.key(key), values...)
+}
+
+// HSetStruct 序列化结构体并设置哈希表中字段的值，执行后可通过 HGetStruct 读取
+func (p *Pipe) HSetStruct(ctx context.Context, key, field string, value interface{}) *redis.IntCmd {
+	data, err := p.client.marshal(value)
+	if err != nil {
+		return p.intErr(ctx, fmt.Errorf("序列化失败: %w", err))
+	}
+	return p.pipe.HSet(ctx, p.client.key(key), field, data)
+}
+
+// HSetFields 将结构体按 `redis:"name"` 标签映射为哈希表字段并写入，映射规则见 Client.HSetStruct
+// 执行后可通过 HGetAllStruct 读取
+func (p *Pipe) HSetFields(ctx context.Context, key string, value interface{}) *redis.IntCmd {
+	values, err := p.client.structToHash(value)
+	if err != nil {
+		return p.intErr(ctx, err)
+	}
+	if len(values) == 0 {
+		return p.intErr(ctx, errNoHashFields)
+	}
+	return p.pipe.HSet(ctx, p.client.key(key), values...)
+}
+
+// HGet 获取哈希表中字段的值
+func (p *Pipe) HGet(ctx context.Context, key, field string) *redis.StringCmd {
+	return p.pipe.HGet(ctx, p.client.key(key), field)
+}
+
+// HGetStruct 获取哈希表中字段的值，执行后通过 StructCmd.Scan 反序列化
+func (p *Pipe) HGetStruct(ctx context.Context, key, field string) *StructCmd {
+	return &StructCmd{client: p.client, cmd: p.pipe.HGet(ctx, p.client.key(key), field)}
+}
+
+// HGetAllStruct 获取哈希表中所有字段，执行后通过 HashStructCmd.Scan 按 `redis:"name"` 标签填充到结构体
+func (p *Pipe) HGetAllStruct(ctx context.Context, key string) *HashStructCmd {
+	return &HashStructCmd{client: p.client, cmd: p.pipe.HGetAll(ctx, p.client.key(key))}
+}
+
+// HDel 删除哈希表中的字段
+func (p *Pipe) HDel(ctx context.Context, key string, fields ...string) *redis.IntCmd {
+	return p.pipe.HDel(ctx, p.client.key(key), fields...)
+}
+
+// HIncrBy 将哈希表中字段的值增加指定整数
+func (p *Pipe) HIncrBy(ctx context.Context, key, field string, incr int64) *redis.IntCmd {
+	return p.pipe.HIncrBy(ctx, p.client.key(key), field, incr)
+}
+
+// LPush 从列表左侧推入元素
+func (p *Pipe) LPush(ctx context.Context, key string, values ...interface{}) *redis.IntCmd {
+	values, err := p.client.marshalValues(values)
+	if err != nil {
+		return p.intErr(ctx, err)
+	}
+	return p.pipe.LPush(ctx, p.client.key(key), values...)
+}
+
+// LPushStruct 序列化结构体并从列表左侧推入
+func (p *Pipe) LPushStruct(ctx context.Context, key string, values ...interface{}) *redis.IntCmd {
+	values, err := p.marshalStructs(values)
+	if err != nil {
+		return p.intErr(ctx, err)
+	}
+	return p.pipe.LPush(ctx, p.client.key(key), values...)
+}
+
+// RPush 从列表右侧推入元素
+func (p *Pipe) RPush(ctx context.Context, key string, values ...interface{}) *redis.IntCmd {
+	values, err := p.client.marshalValues(values)
+	if err != nil {
+		return p.intErr(ctx, err)
+	}
+	return p.pipe.RPush(ctx, p.client.key(key), values...)
+}
+
+// RPushStruct 序列化结构体并从列表右侧推入
+func (p *Pipe) RPushStruct(ctx context.Context, key string, values ...interface{}) *redis.IntCmd {
+	values, err := p.marshalStructs(values)
+	if err != nil {
+		return p.intErr(ctx, err)
+	}
+	return p.pipe.RPush(ctx, p.client.key(key), values...)
+}
+
+// SAdd 向集合添加成员
+func (p *Pipe) SAdd(ctx context.Context, key string, members ...interface{}) *redis.IntCmd {
+	members, err := p.client.marshalValues(members)
+	if err != nil {
+		return p.intErr(ctx, err)
+	}
+	return p.pipe.SAdd(ctx, p.client.key(key), members...)
+}
+
+// ZAdd 向有序集合添加成员
+func (p *Pipe) ZAdd(ctx context.Context, key string, members ...redis.Z) *redis.IntCmd {
+	zs := make([]redis.Z, len(members))
+	for i, member := range members {
+		data, err := p.client.marshalValue(member.Member)
+		if err != nil {
+			return p.intErr(ctx, err)
+		}
+		zs[i] = redis.Z{Score: member.Score, Member: data}
+	}
+	return p.pipe.ZAdd(ctx, p.client.key(key), zs...)
+}
+
+// marshalStructs 使用序列化器编码所有值
+func (p *Pipe) marshalStructs(values []interface{}) ([]interface{}, error) {
+	results := make([]interface{}, len(values))
+	for i, value := range values {
+		data, err := p.client.marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("序列化失败: %w", err)
+		}
+		results[i] = data
+	}
+	return results, nil
+}
+
+// fail 记录排队阶段的错误，管道将不会执行
+// 错误由调用方负责描述（序列化错误已包含"序列化失败"前缀），此处不再包装
+func (p *Pipe) fail(err error) error {
+	if p.err == nil {
+		p.err = err
+	}
+	return err
+}
+
+// statusErr 返回带错误的 StatusCmd
+func (p *Pipe) statusErr(ctx context.Context, err error) *redis.StatusCmd {
+	cmd := redis.NewStatusCmd(ctx)
+	cmd.SetErr(p.fail(err))
+	return cmd
+}
+
+// intErr 返回带错误的 IntCmd
+func (p *Pipe) intErr(ctx context.Context, err error) *redis.IntCmd {
+	cmd := redis.NewIntCmd(ctx)
+	cmd.SetErr(p.fail(err))
+	return cmd
+}
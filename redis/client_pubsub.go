@@ -0,0 +1,243 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/nilchaosky/go-nexus/logz"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	// subscribeMinBackoff 连接中断后重新订阅的最小等待时间
+	subscribeMinBackoff = 100 * time.Millisecond
+	// subscribeMaxBackoff 连接中断后重新订阅的最大等待时间
+	subscribeMaxBackoff = 5 * time.Second
+)
+
+// Message 订阅消息
+type Message[T any] struct {
+	Channel string // 消息频道
+	Pattern string // 匹配的模式（仅模式订阅）
+	Payload T      // 反序列化后的消息内容
+}
+
+// Handler 消息处理函数，返回的错误记录到日志
+type Handler[T any] func(ctx context.Context, msg *Message[T]) error
+
+// SubscribeOption 订阅选项
+type SubscribeOption func(*subscribeOptions)
+
+// subscribeOptions 订阅选项
+type subscribeOptions struct {
+	workers int // 并发处理的协程数
+}
+
+// SubscribeWorkers 设置并发处理消息的协程数（默认：8），为1时按接收顺序处理
+func SubscribeWorkers(n int) SubscribeOption {
+	return func(o *subscribeOptions) {
+		if n > 0 {
+			o.workers = n
+		}
+	}
+}
+
+// Publish 发布消息，字符串和 []byte 原样发布，其他值使用客户端序列化器编码（与 Subscribe[T] 的解码对应）
+// 返回接收到消息的订阅者数量
+func (c *Client) Publish(ctx context.Context, channel string, value interface{}) (int64, error) {
+	data, err := encodeAs(c, value)
+	if err != nil {
+		return 0, err
+	}
+	return c.UniversalClient.Publish(ctx, channel, data).Result()
+}
+
+// Subscription 订阅
+// 消息反序列化为T后交由固定数量的协程处理，处理函数的panic会被恢复并记录日志
+// 连接中断时自动重新订阅，Close 等待正在处理的消息完成
+type Subscription struct {
+	pubsub  *redis.PubSub
+	cancel  context.CancelFunc
+	stopped chan struct{}
+	once    sync.Once
+	err     error
+}
+
+// Subscribe 订阅频道，消息反序列化为T后交由handler处理
+func Subscribe[T any](ctx context.Context, c *Client, channels []string, handler Handler[T], opts ...SubscribeOption) (*Subscription, error) {
+	if len(channels) == 0 {
+		return nil, errors.New("订阅频道不能为空")
+	}
+	return subscribe(ctx, c, c.UniversalClient.Subscribe(ctx, channels...), handler, opts)
+}
+
+// PSubscribe 按模式订阅频道，消息反序列化为T后交由handler处理
+func PSubscribe[T any](ctx context.Context, c *Client, patterns []string, handler Handler[T], opts ...SubscribeOption) (*Subscription, error) {
+	if len(patterns) == 0 {
+		return nil, errors.New("订阅模式不能为空")
+	}
+	return subscribe(ctx, c, c.UniversalClient.PSubscribe(ctx, patterns...), handler, opts)
+}
+
+// subscribe 确认订阅并启动接收与处理协程
+func subscribe[T any](ctx context.Context, c *Client, pubsub *redis.PubSub, handler Handler[T], opts []SubscribeOption) (*Subscription, error) {
+	if handler == nil {
+		_ = pubsub.Close()
+		return nil, errors.New("消息处理函数不能为空")
+	}
+
+	o := subscribeOptions{workers: 8}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// 等待订阅确认，确保之后发布的消息不会丢失
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+
+	// 处理函数不受调用方取消影响，关闭时等待其完成
+	handlerCtx := context.WithoutCancel(ctx)
+	loopCtx, cancel := context.WithCancel(ctx)
+
+	s := &Subscription{
+		pubsub:  pubsub,
+		cancel:  cancel,
+		stopped: make(chan struct{}),
+	}
+
+	messages := make(chan *redis.Message, o.workers)
+	var wg sync.WaitGroup
+	for i := 0; i < o.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for msg := range messages {
+				handleMessage(handlerCtx, c, msg, handler)
+			}
+		}()
+	}
+
+	go func() {
+		s.receive(loopCtx, messages)
+		close(messages)
+		wg.Wait()
+		close(s.stopped)
+	}()
+
+	// 调用方取消时关闭订阅
+	go func() {
+		select {
+		case <-loopCtx.Done():
+			_ = s.closePubSub()
+		case <-s.stopped:
+		}
+	}()
+
+	return s, nil
+}
+
+// Close 取消订阅并等待正在处理的消息完成
+func (s *Subscription) Close() error {
+	s.cancel()
+	err := s.closePubSub()
+	<-s.stopped
+	return err
+}
+
+// Shutdown 取消订阅并等待正在处理的消息完成，ctx 结束时不再等待
+func (s *Subscription) Shutdown(ctx context.Context) error {
+	s.cancel()
+	err := s.closePubSub()
+	select {
+	case <-s.stopped:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Done 返回订阅结束（所有消息处理完成）时关闭的通道
+func (s *Subscription) Done() <-chan struct{} {
+	return s.stopped
+}
+
+// closePubSub 关闭底层订阅连接（只执行一次）
+func (s *Subscription) closePubSub() error {
+	s.once.Do(func() {
+		s.err = s.pubsub.Close()
+	})
+	return s.err
+}
+
+// receive 接收消息，连接中断时退避后重新订阅
+func (s *Subscription) receive(ctx context.Context, messages chan<- *redis.Message) {
+	backoff := subscribeMinBackoff
+
+	for {
+		msg, err := s.pubsub.ReceiveMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, redis.ErrClosed) {
+				return
+			}
+
+			// 下一次接收时底层连接会重建并恢复所有订阅
+			logz.Logger.Warn("订阅连接中断，正在重新订阅",
+				zap.Duration("backoff", backoff),
+				zap.Error(err),
+			)
+
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			backoff = min(backoff*2, subscribeMaxBackoff)
+			continue
+		}
+		backoff = subscribeMinBackoff
+
+		select {
+		case messages <- msg:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handleMessage 反序列化消息并执行处理函数，恢复处理函数的panic
+func handleMessage[T any](ctx context.Context, c *Client, msg *redis.Message, handler Handler[T]) {
+	defer func() {
+		if r := recover(); r != nil {
+			logz.Logger.Error("订阅消息处理异常",
+				zap.String("channel", msg.Channel),
+				zap.Any("panic", r),
+				zap.Stack("stack"),
+			)
+		}
+	}()
+
+	payload, err := decodeAs[T](c, msg.Payload)
+	if err != nil {
+		logz.Logger.Warn("订阅消息反序列化失败",
+			zap.String("channel", msg.Channel),
+			zap.Error(err),
+		)
+		return
+	}
+
+	err = handler(ctx, &Message[T]{Channel: msg.Channel, Pattern: msg.Pattern, Payload: payload})
+	if err != nil {
+		logz.Logger.Warn("订阅消息处理失败",
+			zap.String("channel", msg.Channel),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,495 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nilchaosky/go-nexus/logz"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// reliableQueueKeyPrefix 可靠队列键前缀
+const reliableQueueKeyPrefix = "RELIABLEQUEUE:"
+
+var (
+	// reliableAckScript 确认消息，仅在消息确实从处理中列表移除时删除重试次数
+	// 消息已被恢复给其他工作者时不能删除，否则会重置其重试次数
+	// KEYS[1] 处理中列表 KEYS[2] 重试次数哈希 ARGV[1] 消息 ARGV[2] 消息ID
+	// 返回 0 消息不在处理中列表，1 已确认
+	reliableAckScript = redis.NewScript(`
+if redis.call('lrem', KEYS[1], 1, ARGV[1]) == 0 then
+	return 0
+end
+redis.call('hdel', KEYS[2], ARGV[2])
+return 1
+`)
+
+	// reliableNackScript 将处理中的消息放回等待列表，超过最大重试次数时转入死信列表
+	// KEYS[1] 处理中列表 KEYS[2] 等待列表 KEYS[3] 死信列表 KEYS[4] 重试次数哈希
+	// ARGV[1] 消息 ARGV[2] 消息ID ARGV[3] 最大重试次数
+	// 返回 -1 消息不在处理中列表，0 已转入死信列表，1 已重新入队
+	reliableNackScript = redis.NewScript(`
+if redis.call('lrem', KEYS[1], 1, ARGV[1]) == 0 then
+	return -1
+end
+local retries = redis.call('hincrby', KEYS[4], ARGV[2], 1)
+local max = tonumber(ARGV[3])
+if max > 0 and retries > max then
+	redis.call('lpush', KEYS[3], ARGV[1])
+	redis.call('hdel', KEYS[4], ARGV[2])
+	return 0
+end
+redis.call('lpush', KEYS[2], ARGV[1])
+return 1
+`)
+
+	// reliableRecoverScript 将失联工作者处理中列表的消息放回等待列表（计为一次重试）
+	// KEYS[1] 失联工作者处理中列表 KEYS[2] 等待列表 KEYS[3] 死信列表 KEYS[4] 重试次数哈希 KEYS[5] 工作者心跳集合
+	// ARGV[1] 工作者名称 ARGV[2] 最大重试次数 ARGV[3] 心跳截止时间（毫秒）
+	// 返回恢复的消息数量，工作者已恢复心跳时返回-1
+	reliableRecoverScript = redis.NewScript(`
+local beat = redis.call('zscore', KEYS[5], ARGV[1])
+if beat and tonumber(beat) > tonumber(ARGV[3]) then
+	return -1
+end
+local max = tonumber(ARGV[2])
+local moved = 0
+while true do
+	local item = redis.call('rpop', KEYS[1])
+	if not item then
+		break
+	end
+	local id = string.match(item, '^([^:]+):') or item
+	local retries = redis.call('hincrby', KEYS[4], id, 1)
+	if max > 0 and retries > max then
+		redis.call('lpush', KEYS[3], item)
+		redis.call('hdel', KEYS[4], id)
+	else
+		redis.call('rpush', KEYS[2], item)
+	end
+	moved = moved + 1
+end
+redis.call('zrem', KEYS[5], ARGV[1])
+return moved
+`)
+)
+
+// ReliableQueueOption 可靠队列选项
+type ReliableQueueOption func(*reliableQueueOptions)
+
+// reliableQueueOptions 可靠队列选项
+type reliableQueueOptions struct {
+	worker      string        // 工作者名称
+	heartbeat   time.Duration // 心跳间隔
+	deadAfter   time.Duration // 心跳超时时间
+	maxRetries  int64         // 最大重试次数
+	concurrency int           // 并发处理的协程数
+	block       time.Duration // 阻塞读取时间
+}
+
+// ReliableQueueWorker 设置工作者名称（默认：主机名-随机串），每个工作者使用独立的处理中列表
+func ReliableQueueWorker(name string) ReliableQueueOption {
+	return func(o *reliableQueueOptions) {
+		if name != "" {
+			o.worker = name
+		}
+	}
+}
+
+// ReliableQueueHeartbeat 设置心跳间隔和心跳超时时间（默认：5秒、30秒），心跳超时的工作者被视为失联
+func ReliableQueueHeartbeat(interval, deadAfter time.Duration) ReliableQueueOption {
+	return func(o *reliableQueueOptions) {
+		if interval > 0 {
+			o.heartbeat = interval
+		}
+		if deadAfter > o.heartbeat {
+			o.deadAfter = deadAfter
+		}
+	}
+}
+
+// ReliableQueueMaxRetries 设置最大重试次数，超过后消息转入死信列表（默认：5，0为不限制）
+func ReliableQueueMaxRetries(n int64) ReliableQueueOption {
+	return func(o *reliableQueueOptions) {
+		if n >= 0 {
+			o.maxRetries = n
+		}
+	}
+}
+
+// ReliableQueueConcurrency 设置 Run 并发处理消息的协程数（默认：4）
+func ReliableQueueConcurrency(n int) ReliableQueueOption {
+	return func(o *reliableQueueOptions) {
+		if n > 0 {
+			o.concurrency = n
+		}
+	}
+}
+
+// ReliableQueueBlock 设置阻塞读取时间（默认：5秒），也是停止处理时的最长等待时间
+func ReliableQueueBlock(d time.Duration) ReliableQueueOption {
+	return func(o *reliableQueueOptions) {
+		if d > 0 {
+			o.block = d
+		}
+	}
+}
+
+// ReliableMessage 可靠队列消息
+type ReliableMessage[T any] struct {
+	ID      string // 消息ID
+	Payload T      // 反序列化后的消息内容
+	Retries int64  // 已重试次数
+	raw     string // 列表中的原始元素
+}
+
+// ReliableHandler 可靠队列消息处理函数，返回nil时确认消息，返回错误时重新入队
+type ReliableHandler[T any] func(ctx context.Context, msg *ReliableMessage[T]) error
+
+// ReliableQueue 基于列表的可靠队列
+// 工作者通过 BRPOPLPUSH 将消息原子移入自己的处理中列表，处理完成后 Ack 删除、失败后 Nack 重新入队；
+// 工作者定期写入心跳，清理协程将心跳超时工作者处理中列表的消息放回等待列表，超过最大重试次数的消息转入死信列表
+// 列表元素格式为 {id}:{payload}，所有键使用 {name} 哈希标签，集群模式下位于同一槽位
+type ReliableQueue[T any] struct {
+	client     *Client
+	name       string
+	prefix     string
+	pending    string
+	processing string
+	dead       string
+	retries    string
+	workers    string
+	opts       reliableQueueOptions
+}
+
+// NewReliableQueue 创建可靠队列
+func NewReliableQueue[T any](c *Client, name string, opts ...ReliableQueueOption) (*ReliableQueue[T], error) {
+	if name == "" {
+		return nil, errors.New("队列名称不能为空")
+	}
+
+	o := reliableQueueOptions{
+		heartbeat:   5 * time.Second,
+		deadAfter:   30 * time.Second,
+		maxRetries:  5,
+		concurrency: 4,
+		block:       5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.worker == "" {
+		hostname, _ := os.Hostname()
+		o.worker = hostname + "-" + randomToken()[:8]
+	}
+
+	prefix := c.key(reliableQueueKeyPrefix + "{" + name + "}:")
+	return &ReliableQueue[T]{
+		client:     c,
+		name:       name,
+		prefix:     prefix,
+		pending:    prefix + "pending",
+		processing: prefix + "processing:" + o.worker,
+		dead:       prefix + "dead",
+		retries:    prefix + "retries",
+		workers:    prefix + "workers",
+		opts:       o,
+	}, nil
+}
+
+// Worker 获取工作者名称
+func (q *ReliableQueue[T]) Worker() string {
+	return q.opts.worker
+}
+
+// Push 将消息推入队列，返回消息ID
+func (q *ReliableQueue[T]) Push(ctx context.Context, payloads ...T) ([]string, error) {
+	if len(payloads) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]string, len(payloads))
+	items := make([]interface{}, len(payloads))
+	for i, payload := range payloads {
+		data, err := encodeAs(q.client, payload)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = randomToken()
+		items[i] = ids[i] + ":" + data
+	}
+
+	if err := q.client.UniversalClient.LPush(ctx, q.pending, items...).Err(); err != nil {
+		return nil, fmt.Errorf("推入消息失败: %w", err)
+	}
+
+	return ids, nil
+}
+
+// Pop 阻塞获取一条消息并移入当前工作者的处理中列表，超时返回 ErrNotFound
+// 获取的消息需通过 Ack 确认或 Nack 重新入队；无法反序列化的消息直接转入死信列表
+func (q *ReliableQueue[T]) Pop(ctx context.Context, timeout time.Duration) (*ReliableMessage[T], error) {
+	if err := q.beat(ctx); err != nil {
+		return nil, err
+	}
+
+	item, err := wrapResult(q.client.UniversalClient.BRPopLPush(ctx, q.pending, q.processing, timeout).Result())
+	if err != nil {
+		return nil, err
+	}
+
+	id, data, _ := strings.Cut(item, ":")
+	retries, err := q.client.UniversalClient.HGet(ctx, q.retries, id).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	msg := &ReliableMessage[T]{ID: id, Retries: retries, raw: item}
+	msg.Payload, err = decodeAs[T](q.client, data)
+	if err != nil {
+		logz.Logger.Warn("可靠队列消息反序列化失败，已转入死信列表",
+			zap.String("queue", q.name),
+			zap.String("id", id),
+			zap.Error(err),
+		)
+		_ = q.bury(ctx, item)
+		return nil, err
+	}
+
+	return msg, nil
+}
+
+// Ack 确认消息处理完成，消息已被清理协程放回等待列表时返回 ErrJobNotClaimed
+func (q *ReliableQueue[T]) Ack(ctx context.Context, msg *ReliableMessage[T]) error {
+	ok, err := reliableAckScript.Run(ctx, q.client.UniversalClient,
+		[]string{q.processing, q.retries}, msg.raw, msg.ID).Bool()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrJobNotClaimed
+	}
+	return nil
+}
+
+// Nack 将处理失败的消息放回等待列表末尾，超过最大重试次数时转入死信列表
+// 返回 true 表示消息已转入死信列表
+func (q *ReliableQueue[T]) Nack(ctx context.Context, msg *ReliableMessage[T]) (bool, error) {
+	result, err := reliableNackScript.Run(ctx, q.client.UniversalClient,
+		[]string{q.processing, q.pending, q.dead, q.retries},
+		msg.raw, msg.ID, q.opts.maxRetries).Int64()
+	if err != nil {
+		return false, err
+	}
+	if result < 0 {
+		return false, ErrJobNotClaimed
+	}
+	return result == 0, nil
+}
+
+// Recover 将心跳超时工作者处理中列表的消息放回等待列表，返回恢复的消息数量
+func (q *ReliableQueue[T]) Recover(ctx context.Context) (int64, error) {
+	deadline := time.Now().Add(-q.opts.deadAfter).UnixMilli()
+	workers, err := q.client.UniversalClient.ZRangeByScore(ctx, q.workers, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(deadline, 10),
+	}).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, worker := range workers {
+		if worker == q.opts.worker {
+			continue
+		}
+		moved, err := reliableRecoverScript.Run(ctx, q.client.UniversalClient,
+			[]string{q.prefix + "processing:" + worker, q.pending, q.dead, q.retries, q.workers},
+			worker, q.opts.maxRetries, deadline).Int64()
+		if err != nil {
+			return total, err
+		}
+		if moved > 0 {
+			logz.Logger.Warn("已恢复失联工作者的消息",
+				zap.String("queue", q.name),
+				zap.String("worker", worker),
+				zap.Int64("count", moved),
+			)
+			total += moved
+		}
+	}
+
+	return total, nil
+}
+
+// Len 获取等待中的消息数量
+func (q *ReliableQueue[T]) Len(ctx context.Context) (int64, error) {
+	return q.client.UniversalClient.LLen(ctx, q.pending).Result()
+}
+
+// DeadLen 获取死信列表中的消息数量
+func (q *ReliableQueue[T]) DeadLen(ctx context.Context) (int64, error) {
+	return q.client.UniversalClient.LLen(ctx, q.dead).Result()
+}
+
+// Run 启动心跳和清理协程并并发处理消息，阻塞直到ctx结束；返回前等待正在处理的消息完成
+// 处理函数返回错误或panic时消息重新入队
+func (q *ReliableQueue[T]) Run(ctx context.Context, handler ReliableHandler[T]) error {
+	if handler == nil {
+		return errors.New("消息处理函数不能为空")
+	}
+
+	if err := q.beat(ctx); err != nil {
+		return err
+	}
+
+	// 处理函数与确认不受停止信号影响，停止时等待其完成
+	handlerCtx := context.WithoutCancel(ctx)
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		q.maintain(ctx)
+	}()
+
+	for i := 0; i < q.opts.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				msg, err := q.Pop(ctx, q.opts.block)
+				if err != nil {
+					if !IsNotFound(err) && ctx.Err() == nil {
+						logz.Logger.Warn("获取可靠队列消息失败",
+							zap.String("queue", q.name),
+							zap.Error(err),
+						)
+						time.Sleep(time.Second)
+					}
+					continue
+				}
+				q.handle(handlerCtx, msg, handler)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// 确认或重新入队失败时处理中列表可能仍有消息，放回等待列表后再移除心跳
+	return q.release(handlerCtx)
+}
+
+// handle 执行处理函数，成功时确认消息，失败或panic时重新入队
+func (q *ReliableQueue[T]) handle(ctx context.Context, msg *ReliableMessage[T], handler ReliableHandler[T]) {
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logz.Logger.Error("可靠队列消息处理异常",
+					zap.String("queue", q.name),
+					zap.String("id", msg.ID),
+					zap.Any("panic", r),
+					zap.Stack("stack"),
+				)
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return handler(ctx, msg)
+	}()
+
+	if err == nil {
+		if err := q.Ack(ctx, msg); err != nil {
+			logz.Logger.Warn("确认可靠队列消息失败",
+				zap.String("queue", q.name),
+				zap.String("id", msg.ID),
+				zap.Error(err),
+			)
+		}
+		return
+	}
+
+	logz.Logger.Warn("可靠队列消息处理失败",
+		zap.String("queue", q.name),
+		zap.String("id", msg.ID),
+		zap.Int64("retries", msg.Retries),
+		zap.Error(err),
+	)
+	if _, err := q.Nack(ctx, msg); err != nil {
+		logz.Logger.Warn("重新入队可靠队列消息失败",
+			zap.String("queue", q.name),
+			zap.String("id", msg.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+// maintain 定期写入心跳并恢复失联工作者的消息
+func (q *ReliableQueue[T]) maintain(ctx context.Context) {
+	ticker := time.NewTicker(q.opts.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := q.beat(ctx); err != nil && ctx.Err() == nil {
+			logz.Logger.Warn("可靠队列心跳失败",
+				zap.String("queue", q.name),
+				zap.String("worker", q.opts.worker),
+				zap.Error(err),
+			)
+		}
+		if _, err := q.Recover(ctx); err != nil && ctx.Err() == nil {
+			logz.Logger.Warn("恢复失联工作者消息失败",
+				zap.String("queue", q.name),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// release 将当前工作者处理中列表剩余的消息放回等待列表（计为一次重试）并移除心跳
+// 与 Recover 使用同一脚本原子执行，移除心跳后不会留下无法恢复的消息
+func (q *ReliableQueue[T]) release(ctx context.Context) error {
+	moved, err := reliableRecoverScript.Run(ctx, q.client.UniversalClient,
+		[]string{q.processing, q.pending, q.dead, q.retries, q.workers},
+		q.opts.worker, q.opts.maxRetries, time.Now().UnixMilli()).Int64()
+	if err != nil {
+		return fmt.Errorf("释放处理中消息失败: %w", err)
+	}
+	if moved > 0 {
+		logz.Logger.Warn("停止时已将未确认的消息放回等待列表",
+			zap.String("queue", q.name),
+			zap.String("worker", q.opts.worker),
+			zap.Int64("count", moved),
+		)
+	}
+	return nil
+}
+
+// beat 写入当前工作者心跳
+func (q *ReliableQueue[T]) beat(ctx context.Context) error {
+	return q.client.UniversalClient.ZAdd(ctx, q.workers, redis.Z{
+		Score:  float64(time.Now().UnixMilli()),
+		Member: q.opts.worker,
+	}).Err()
+}
+
+// bury 将处理中的消息转入死信列表
+func (q *ReliableQueue[T]) bury(ctx context.Context, item string) error {
+	return q.client.TxPipeline(ctx, func(p *Pipe) error {
+		p.Raw().LRem(ctx, q.processing, 1, item)
+		p.Raw().LPush(ctx, q.dead, item)
+		return nil
+	})
+}
@@ -0,0 +1,187 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nilchaosky/go-nexus/redis"
+	"github.com/nilchaosky/go-nexus/redis/redistest"
+)
+
+// TestReliableQueueAckNack 测试确认、重新入队以及超过最大重试次数后转入死信列表
+func TestReliableQueueAckNack(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+
+	queue, err := redis.NewReliableQueue[string](client, "mail", redis.ReliableQueueMaxRetries(1))
+	if err != nil {
+		t.Fatalf("NewReliableQueue失败: %v", err)
+	}
+	ids, err := queue.Push(ctx, "a", "b")
+	if err != nil || len(ids) != 2 {
+		t.Fatalf("Push失败: %v, %v", ids, err)
+	}
+
+	msg, err := queue.Pop(ctx, time.Second)
+	if err != nil || msg.ID != ids[0] || msg.Payload != "a" || msg.Retries != 0 {
+		t.Fatalf("期望按入队顺序获取a，实际: %+v, %v", msg, err)
+	}
+	if dead, err := queue.Nack(ctx, msg); err != nil || dead {
+		t.Fatalf("期望第1次失败重新入队，实际: %v, %v", dead, err)
+	}
+
+	// 重新入队的消息排在b之后
+	msg, _ = queue.Pop(ctx, time.Second)
+	if msg == nil || msg.Payload != "b" {
+		t.Fatalf("期望获取b，实际: %+v", msg)
+	}
+	if err := queue.Ack(ctx, msg); err != nil {
+		t.Fatalf("Ack失败: %v", err)
+	}
+	if err := queue.Ack(ctx, msg); !errors.Is(err, redis.ErrJobNotClaimed) {
+		t.Errorf("期望重复确认返回ErrJobNotClaimed，实际: %v", err)
+	}
+
+	msg, _ = queue.Pop(ctx, time.Second)
+	if msg == nil || msg.ID != ids[0] || msg.Retries != 1 {
+		t.Fatalf("期望a的重试次数为1，实际: %+v", msg)
+	}
+	if dead, err := queue.Nack(ctx, msg); err != nil || !dead {
+		t.Fatalf("期望超过最大重试次数后转入死信列表，实际: %v, %v", dead, err)
+	}
+	if _, err := queue.Nack(ctx, msg); !errors.Is(err, redis.ErrJobNotClaimed) {
+		t.Errorf("期望重复Nack返回ErrJobNotClaimed，实际: %v", err)
+	}
+	if n, _ := queue.DeadLen(ctx); n != 1 {
+		t.Errorf("期望死信列表有1条消息，实际: %d", n)
+	}
+
+	if _, err := queue.Pop(ctx, time.Second); !redis.IsNotFound(err) {
+		t.Errorf("期望队列为空时返回ErrNotFound，实际: %v", err)
+	}
+}
+
+// TestReliableQueueRecover 测试恢复心跳超时工作者的消息，恢复计为一次重试
+func TestReliableQueueRecover(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+
+	opts := []redis.ReliableQueueOption{
+		redis.ReliableQueueHeartbeat(10*time.Millisecond, 50*time.Millisecond),
+		redis.ReliableQueueMaxRetries(1),
+	}
+	newWorker := func(name string) *redis.ReliableQueue[string] {
+		queue, err := redis.NewReliableQueue[string](client, "jobs", append(opts, redis.ReliableQueueWorker(name))...)
+		if err != nil {
+			t.Fatalf("NewReliableQueue失败: %v", err)
+		}
+		return queue
+	}
+	w1, w2, w3 := newWorker("w1"), newWorker("w2"), newWorker("w3")
+
+	if _, err := w1.Push(ctx, "x"); err != nil {
+		t.Fatalf("Push失败: %v", err)
+	}
+	lost, err := w1.Pop(ctx, time.Second)
+	if err != nil {
+		t.Fatalf("Pop失败: %v", err)
+	}
+
+	// w1 心跳未超时，不会被恢复
+	if n, err := w2.Recover(ctx); err != nil || n != 0 {
+		t.Fatalf("期望不恢复存活工作者的消息，实际: %d, %v", n, err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if n, err := w2.Recover(ctx); err != nil || n != 1 {
+		t.Fatalf("期望恢复1条消息，实际: %d, %v", n, err)
+	}
+	if err := w1.Ack(ctx, lost); !errors.Is(err, redis.ErrJobNotClaimed) {
+		t.Errorf("期望失联工作者确认返回ErrJobNotClaimed，实际: %v", err)
+	}
+
+	msg, err := w2.Pop(ctx, time.Second)
+	if err != nil || msg.ID != lost.ID || msg.Retries != 1 {
+		t.Fatalf("期望w2获取恢复的消息且重试次数为1，实际: %+v, %v", msg, err)
+	}
+
+	// w2 同样失联，重试次数用尽后转入死信列表
+	time.Sleep(60 * time.Millisecond)
+	if n, err := w3.Recover(ctx); err != nil || n != 1 {
+		t.Fatalf("期望恢复1条消息，实际: %d, %v", n, err)
+	}
+	if n, _ := w3.Len(ctx); n != 0 {
+		t.Errorf("期望等待列表为空，实际: %d", n)
+	}
+	if n, _ := w3.DeadLen(ctx); n != 1 {
+		t.Errorf("期望死信列表有1条消息，实际: %d", n)
+	}
+}
+
+// TestReliableQueueRun 测试 Run 处理失败后重新入队，停止时将未确认的消息放回等待列表
+func TestReliableQueueRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client, _ := redistest.NewClient(t)
+
+	queue, _ := redis.NewReliableQueue[string](client, "run",
+		redis.ReliableQueueBlock(time.Second),
+		redis.ReliableQueueConcurrency(1),
+	)
+	if _, err := queue.Push(ctx, "a"); err != nil {
+		t.Fatalf("Push失败: %v", err)
+	}
+
+	var mu sync.Mutex
+	var retries []int64
+	done := make(chan struct{})
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- queue.Run(ctx, func(_ context.Context, msg *redis.ReliableMessage[string]) error {
+			mu.Lock()
+			retries = append(retries, msg.Retries)
+			mu.Unlock()
+			if msg.Retries == 0 {
+				panic("boom")
+			}
+			close(done)
+			return nil
+		})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("等待消息处理成功超时")
+	}
+	cancel()
+	if err := <-stopped; err != nil {
+		t.Errorf("Run返回错误: %v", err)
+	}
+	mu.Lock()
+	if len(retries) != 2 || retries[1] != 1 {
+		t.Errorf("期望第2次处理成功，实际: %v", retries)
+	}
+	mu.Unlock()
+
+	// 手动获取但未确认的消息在 Run 停止时放回等待列表
+	bg := context.Background()
+	if _, err := queue.Push(bg, "b"); err != nil {
+		t.Fatalf("Push失败: %v", err)
+	}
+	if _, err := queue.Pop(bg, time.Second); err != nil {
+		t.Fatalf("Pop失败: %v", err)
+	}
+	stopCtx, stop := context.WithTimeout(bg, 50*time.Millisecond)
+	defer stop()
+	if err := queue.Run(stopCtx, func(context.Context, *redis.ReliableMessage[string]) error { return nil }); err != nil {
+		t.Fatalf("Run返回错误: %v", err)
+	}
+	msg, err := queue.Pop(bg, time.Second)
+	if err != nil || msg.Payload != "b" || msg.Retries != 1 {
+		t.Errorf("期望停止时释放消息b且重试次数为1，实际: %+v, %v", msg, err)
+	}
+}
@@ -0,0 +1,371 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ScanOption 扫描选项
+type ScanOption func(*scanOptions)
+
+// scanOptions 扫描选项
+type scanOptions struct {
+	match   string // 匹配模式
+	count   int64  // 每次扫描的数量提示
+	keyType string // 键类型（仅 SCAN）
+}
+
+// defaultScanOptions 返回默认扫描选项
+func defaultScanOptions(opts []ScanOption) scanOptions {
+	o := scanOptions{count: 100}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// ScanMatch 设置匹配模式（默认：匹配全部）
+func ScanMatch(pattern string) ScanOption {
+	return func(o *scanOptions) {
+		o.match = pattern
+	}
+}
+
+// ScanCount 设置每次扫描的数量提示，也是批量删除、设置过期时间的批次大小（默认：100）
+func ScanCount(count int64) ScanOption {
+	return func(o *scanOptions) {
+		if count > 0 {
+			o.count = count
+		}
+	}
+}
+
+// ScanType 按键类型过滤，如 string、list、set、zset、hash、stream（仅 SCAN，需要 Redis 6.0+）
+func ScanType(keyType string) ScanOption {
+	return func(o *scanOptions) {
+		o.keyType = keyType
+	}
+}
+
+// FieldValue 哈希表字段及其值
+type FieldValue struct {
+	Field string
+	Value string
+}
+
+// ScanIter 遍历匹配的键，集群模式下依次遍历所有主节点；带前缀的客户端视图只遍历前缀下的键并去除前缀
+// SCAN 可能返回重复的键，调用方需自行处理；出错时产出错误并结束遍历
+func (c *Client) ScanIter(ctx context.Context, opts ...ScanOption) iter.Seq2[string, error] {
+	o := defaultScanOptions(opts)
+	o.match = c.pattern(o.match)
+
+	return func(yield func(string, error) bool) {
+		nodes, err := c.masters(ctx)
+		if err != nil {
+			yield("", err)
+			return
+		}
+
+		for _, node := range nodes {
+			for key, err := range scanNode(ctx, node, o) {
+				if !yield(c.stripKey(key), err) || err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ScanAll 获取所有匹配的键（已去重），集群模式下遍历所有主节点
+func (c *Client) ScanAll(ctx context.Context, opts ...ScanOption) ([]string, error) {
+	seen := make(map[string]struct{})
+	var keys []string
+	for key, err := range c.ScanIter(ctx, opts...) {
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// HScanIter 遍历哈希表中匹配的字段
+func (c *Client) HScanIter(ctx context.Context, key string, opts ...ScanOption) iter.Seq2[FieldValue, error] {
+	o := defaultScanOptions(opts)
+
+	return func(yield func(FieldValue, error) bool) {
+		var cursor uint64
+		for {
+			values, next, err := c.UniversalClient.HScan(ctx, c.key(key), cursor, o.match, o.count).Result()
+			if err != nil {
+				yield(FieldValue{}, err)
+				return
+			}
+			for i := 0; i+1 < len(values); i += 2 {
+				if !yield(FieldValue{Field: values[i], Value: values[i+1]}, nil) {
+					return
+				}
+			}
+			if next == 0 {
+				return
+			}
+			cursor = next
+		}
+	}
+}
+
+// HScanAll 获取哈希表中所有匹配的字段
+func (c *Client) HScanAll(ctx context.Context, key string, opts ...ScanOption) (map[string]string, error) {
+	results := make(map[string]string)
+	for fv, err := range c.HScanIter(ctx, key, opts...) {
+		if err != nil {
+			return nil, err
+		}
+		results[fv.Field] = fv.Value
+	}
+	return results, nil
+}
+
+// SScanIter 遍历集合中匹配的成员
+func (c *Client) SScanIter(ctx context.Context, key string, opts ...ScanOption) iter.Seq2[string, error] {
+	o := defaultScanOptions(opts)
+
+	return func(yield func(string, error) bool) {
+		var cursor uint64
+		for {
+			members, next, err := c.UniversalClient.SScan(ctx, c.key(key), cursor, o.match, o.count).Result()
+			if err != nil {
+				yield("", err)
+				return
+			}
+			for _, member := range members {
+				if !yield(member, nil) {
+					return
+				}
+			}
+			if next == 0 {
+				return
+			}
+			cursor = next
+		}
+	}
+}
+
+// SScanAll 获取集合中所有匹配的成员（已去重）
+func (c *Client) SScanAll(ctx context.Context, key string, opts ...ScanOption) ([]string, error) {
+	seen := make(map[string]struct{})
+	var members []string
+	for member, err := range c.SScanIter(ctx, key, opts...) {
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := seen[member]; ok {
+			continue
+		}
+		seen[member] = struct{}{}
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+// ZScanIter 遍历有序集合中匹配的成员及其分数
+func (c *Client) ZScanIter(ctx context.Context, key string, opts ...ScanOption) iter.Seq2[redis.Z, error] {
+	o := defaultScanOptions(opts)
+
+	return func(yield func(redis.Z, error) bool) {
+		var cursor uint64
+		for {
+			values, next, err := c.UniversalClient.ZScan(ctx, c.key(key), cursor, o.match, o.count).Result()
+			if err != nil {
+				yield(redis.Z{}, err)
+				return
+			}
+			for i := 0; i+1 < len(values); i += 2 {
+				score, err := strconv.ParseFloat(values[i+1], 64)
+				if err != nil {
+					yield(redis.Z{}, err)
+					return
+				}
+				if !yield(redis.Z{Member: values[i], Score: score}, nil) {
+					return
+				}
+			}
+			if next == 0 {
+				return
+			}
+			cursor = next
+		}
+	}
+}
+
+// ZScanAll 获取有序集合中所有匹配的成员及其分数（已去重）
+func (c *Client) ZScanAll(ctx context.Context, key string, opts ...ScanOption) ([]redis.Z, error) {
+	seen := make(map[string]struct{})
+	var members []redis.Z
+	for z, err := range c.ZScanIter(ctx, key, opts...) {
+		if err != nil {
+			return nil, err
+		}
+		member := z.Member.(string)
+		if _, ok := seen[member]; ok {
+			continue
+		}
+		seen[member] = struct{}{}
+		members = append(members, z)
+	}
+	return members, nil
+}
+
+// DeleteByPattern 使用 SCAN 查找匹配的键并分批 UNLINK 异步删除，返回删除的键数量
+// pattern 不能为空，添加视图前缀后的模式不能只由通配符（* 和 ?）组成，避免误删整个数据库
+func (c *Client) DeleteByPattern(ctx context.Context, pattern string, opts ...ScanOption) (int64, error) {
+	return c.batchByPattern(ctx, pattern, opts, func(pipe redis.Pipeliner, key string) func() int64 {
+		return pipe.Unlink(ctx, key).Val
+	})
+}
+
+// ExpireByPattern 使用 SCAN 查找匹配的键并分批设置过期时间，返回设置成功的键数量
+func (c *Client) ExpireByPattern(ctx context.Context, pattern string, expiration time.Duration, opts ...ScanOption) (int64, error) {
+	return c.batchByPattern(ctx, pattern, opts, func(pipe redis.Pipeliner, key string) func() int64 {
+		cmd := pipe.Expire(ctx, key, expiration)
+		return func() int64 {
+			if cmd.Val() {
+				return 1
+			}
+			return 0
+		}
+	})
+}
+
+// batchByPattern 按节点扫描匹配的键，每批键在同一管道中逐个执行命令（避免集群模式下的跨槽错误）
+func (c *Client) batchByPattern(ctx context.Context, pattern string, opts []ScanOption, fn func(pipe redis.Pipeliner, key string) func() int64) (int64, error) {
+	if pattern == "" {
+		return 0, errors.New("匹配模式不能为空")
+	}
+
+	o := defaultScanOptions(opts)
+	o.match = c.pattern(pattern)
+	if strings.Trim(o.match, "*?") == "" {
+		return 0, fmt.Errorf("匹配模式不能只包含通配符: %s", o.match)
+	}
+
+	nodes, err := c.masters(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, node := range nodes {
+		batch := make([]string, 0, o.count)
+		flush := func() error {
+			if len(batch) == 0 {
+				return nil
+			}
+			n, err := execBatch(ctx, node, batch, fn)
+			total += n
+			batch = batch[:0]
+			return err
+		}
+
+		for key, err := range scanNode(ctx, node, o) {
+			if err != nil {
+				return total, err
+			}
+			batch = append(batch, key)
+			if int64(len(batch)) >= o.count {
+				if err := flush(); err != nil {
+					return total, err
+				}
+			}
+		}
+		if err := flush(); err != nil {
+			return total, err
+		}
+	}
+
+	return total, nil
+}
+
+// execBatch 在节点上以管道执行一批命令，返回结果计数之和
+func execBatch(ctx context.Context, node redis.Cmdable, keys []string, fn func(pipe redis.Pipeliner, key string) func() int64) (int64, error) {
+	pipe := node.Pipeline()
+	counts := make([]func() int64, len(keys))
+	for i, key := range keys {
+		counts[i] = fn(pipe, key)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, count := range counts {
+		total += count()
+	}
+	return total, nil
+}
+
+// scanNode 遍历单个节点上匹配的键
+func scanNode(ctx context.Context, node redis.Cmdable, o scanOptions) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		var cursor uint64
+		for {
+			var cmd *redis.ScanCmd
+			if o.keyType != "" {
+				cmd = node.ScanType(ctx, cursor, o.match, o.count, o.keyType)
+			} else {
+				cmd = node.Scan(ctx, cursor, o.match, o.count)
+			}
+
+			keys, next, err := cmd.Result()
+			if err != nil {
+				yield("", err)
+				return
+			}
+			for _, key := range keys {
+				if !yield(key, nil) {
+					return
+				}
+			}
+			if next == 0 {
+				return
+			}
+			cursor = next
+		}
+	}
+}
+
+// masters 获取需要扫描的节点：集群模式下为所有主节点，其他模式为客户端本身
+func (c *Client) masters(ctx context.Context) ([]redis.Cmdable, error) {
+	cluster, ok := c.UniversalClient.(*redis.ClusterClient)
+	if !ok {
+		return []redis.Cmdable{c.UniversalClient}, nil
+	}
+
+	var (
+		mu    sync.Mutex
+		nodes []redis.Cmdable
+	)
+	err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		mu.Lock()
+		nodes = append(nodes, node)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
@@ -0,0 +1,211 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrScriptNotFound 脚本未注册
+var ErrScriptNotFound = errors.New("脚本未注册")
+
+var (
+	scripts   = make(map[string]*redis.Script)
+	scriptsMu sync.RWMutex
+)
+
+// ScriptRunner 脚本操作接口
+type ScriptRunner interface {
+	LoadScripts(ctx context.Context) error
+	RunScript(ctx context.Context, name string, keys []string, args ...interface{}) *ScriptCmd
+	RunScriptRO(ctx context.Context, name string, keys []string, args ...interface{}) *ScriptCmd
+}
+
+// RegisterScript 注册命名Lua脚本，名称重复时返回错误
+// 应在 Register 之前注册，以便连接建立时预加载；之后注册的脚本在首次执行时自动加载
+func RegisterScript(name, src string) error {
+	if name == "" {
+		return errors.New("脚本名称不能为空")
+	}
+	if src == "" {
+		return errors.New("脚本内容不能为空")
+	}
+
+	scriptsMu.Lock()
+	defer scriptsMu.Unlock()
+
+	if _, ok := scripts[name]; ok {
+		return fmt.Errorf("脚本 %s 已注册", name)
+	}
+	scripts[name] = redis.NewScript(src)
+
+	return nil
+}
+
+// MustRegisterScript 注册命名Lua脚本，失败时panic，适用于包初始化
+func MustRegisterScript(name, src string) {
+	if err := RegisterScript(name, src); err != nil {
+		panic(err)
+	}
+}
+
+// Scripts 获取所有已注册的脚本名称（按名称排序）
+func Scripts() []string {
+	scriptsMu.RLock()
+	defer scriptsMu.RUnlock()
+
+	names := make([]string, 0, len(scripts))
+	for name := range scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// getScript 获取已注册的脚本
+func getScript(name string) (*redis.Script, error) {
+	scriptsMu.RLock()
+	defer scriptsMu.RUnlock()
+
+	script, ok := scripts[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrScriptNotFound, name)
+	}
+	return script, nil
+}
+
+// LoadScripts 将所有已注册的脚本加载到服务器（集群模式下加载到所有主节点）
+func (c *Client) LoadScripts(ctx context.Context) error {
+	scriptsMu.RLock()
+	list := make([]*redis.Script, 0, len(scripts))
+	names := make([]string, 0, len(scripts))
+	for name, script := range scripts {
+		list = append(list, script)
+		names = append(names, name)
+	}
+	scriptsMu.RUnlock()
+
+	var errs []error
+	for i, script := range list {
+		if err := script.Load(ctx, c.UniversalClient).Err(); err != nil {
+			errs = append(errs, fmt.Errorf("加载脚本 %s 失败: %w", names[i], err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// RunScript 执行已注册的脚本：优先使用 EVALSHA，服务器返回 NOSCRIPT 时回退到 EVAL
+// keys 按客户端视图添加前缀
+// 参数中的结构体、map、切片等值使用客户端序列化器编码
+func (c *Client) RunScript(ctx context.Context, name string, keys []string, args ...interface{}) *ScriptCmd {
+	return c.runScript(ctx, name, false, keys, args)
+}
+
+// RunScriptRO 以只读方式执行已注册的脚本（EVALSHA_RO/EVAL_RO），可在只读副本上执行
+func (c *Client) RunScriptRO(ctx context.Context, name string, keys []string, args ...interface{}) *ScriptCmd {
+	return c.runScript(ctx, name, true, keys, args)
+}
+
+// runScript 执行已注册的脚本
+func (c *Client) runScript(ctx context.Context, name string, readOnly bool, keys []string, args []interface{}) *ScriptCmd {
+	script, err := getScript(name)
+	if err != nil {
+		return c.scriptErr(ctx, err)
+	}
+
+	args, err = c.marshalValues(args)
+	if err != nil {
+		return c.scriptErr(ctx, err)
+	}
+
+	var cmd *redis.Cmd
+	if readOnly {
+		cmd = script.RunRO(ctx, c.UniversalClient, c.keys(keys), args...)
+	} else {
+		cmd = script.Run(ctx, c.UniversalClient, c.keys(keys), args...)
+	}
+
+	return &ScriptCmd{client: c, cmd: cmd}
+}
+
+// scriptErr 返回带错误的脚本结果
+func (c *Client) scriptErr(ctx context.Context, err error) *ScriptCmd {
+	cmd := redis.NewCmd(ctx)
+	cmd.SetErr(err)
+	return &ScriptCmd{client: c, cmd: cmd}
+}
+
+// ScriptCmd 脚本执行结果
+// 脚本返回 nil 时各方法返回 ErrNotFound
+type ScriptCmd struct {
+	client *Client
+	cmd    *redis.Cmd
+}
+
+// Raw 获取原始命令结果
+func (s *ScriptCmd) Raw() *redis.Cmd {
+	return s.cmd
+}
+
+// Err 获取执行错误
+func (s *ScriptCmd) Err() error {
+	return wrapError(s.cmd.Err())
+}
+
+// Result 获取原始结果
+func (s *ScriptCmd) Result() (interface{}, error) {
+	return wrapResult(s.cmd.Result())
+}
+
+// Text 获取字符串结果
+func (s *ScriptCmd) Text() (string, error) {
+	return wrapResult(s.cmd.Text())
+}
+
+// Int64 获取整数结果
+func (s *ScriptCmd) Int64() (int64, error) {
+	return wrapResult(s.cmd.Int64())
+}
+
+// Float64 获取浮点数结果
+func (s *ScriptCmd) Float64() (float64, error) {
+	return wrapResult(s.cmd.Float64())
+}
+
+// Bool 获取布尔结果
+func (s *ScriptCmd) Bool() (bool, error) {
+	return wrapResult(s.cmd.Bool())
+}
+
+// Int64Slice 获取整数切片结果
+func (s *ScriptCmd) Int64Slice() ([]int64, error) {
+	return wrapResult(s.cmd.Int64Slice())
+}
+
+// StringSlice 获取字符串切片结果
+func (s *ScriptCmd) StringSlice() ([]string, error) {
+	return wrapResult(s.cmd.StringSlice())
+}
+
+// Scan 将字符串结果（如脚本返回的 cjson.encode 结果）反序列化到结构体
+func (s *ScriptCmd) Scan(value interface{}) error {
+	return s.client.unmarshalValue(value, s.Text)
+}
+
+// RunScriptAs 执行已注册的脚本并将字符串结果反序列化为T
+func RunScriptAs[T any](ctx context.Context, c *Client, name string, keys []string, args ...interface{}) (T, error) {
+	var zero T
+
+	data, err := c.RunScript(ctx, name, keys, args...).Text()
+	if err != nil {
+		return zero, err
+	}
+
+	return decodeAs[T](c, data)
+}
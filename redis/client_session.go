@@ -0,0 +1,386 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	sessionIndexRedisKey = "Sessions"      // 用户会话索引（有序集合，成员为会话ID，分数为登录时间毫秒）
+	sessionRedisKey      = "Session"       // 会话记录（哈希表）
+	sessionRefreshField  = "refresh_token" // 会话记录中的RefreshToken字段
+)
+
+// Session 登录会话
+// 会话记录保存在 {id}:Session:<sessionID> 哈希表中，有效期为 RefreshToken 的过期时间；
+// Token 保存在 {id}:Session:<sessionID>:Token，有效期为 Token 的过期时间
+// 同一用户的所有会话键使用 {id} 哈希标签，集群模式下位于同一槽位，事务与多键删除不会产生 CROSSSLOT 错误
+// （键前缀中不应包含 {}，否则前缀中的哈希标签优先生效）
+type Session struct {
+	ID        string    `json:"id" redis:"-"`                  // 会话ID（为空时自动生成）
+	UserID    string    `json:"user_id" redis:"-"`             // 用户ID
+	Device    string    `json:"device" redis:"device"`         // 设备类型（如 mobile、web）
+	IP        string    `json:"ip" redis:"ip"`                 // 登录IP
+	UserAgent string    `json:"user_agent" redis:"user_agent"` // 客户端User-Agent
+	LoginAt   time.Time `json:"login_at" redis:"login_at"`     // 登录时间（为空时使用当前时间）
+}
+
+// SessionLimitOption 设置每种设备类型允许同时在线的会话数量（limit<=0 不限制）
+// 创建会话时同一用户同类型设备的会话超过限制，按登录时间从早到晚踢出
+func SessionLimitOption(device string, limit int) ClientOption {
+	return func(c *Client) {
+		if c.sessionLimits == nil {
+			c.sessionLimits = make(map[string]int)
+		}
+		c.sessionLimits[device] = limit
+	}
+}
+
+// sessionTag 获取用户会话键的哈希标签
+func sessionTag(id string) string {
+	return "{" + id + "}"
+}
+
+// sessionIndexKey 获取用户会话索引的键（不含前缀）
+func sessionIndexKey(id string) (string, error) {
+	if id == "" {
+		return "", errors.New("id不能为空")
+	}
+	return sessionTag(id) + ":" + sessionIndexRedisKey, nil
+}
+
+// sessionKeys 获取会话记录和会话Token的键（不含前缀）
+func sessionKeys(id, sessionID string) (string, string, error) {
+	if id == "" {
+		return "", "", errors.New("id不能为空")
+	}
+	if sessionID == "" {
+		return "", "", errors.New("会话ID不能为空")
+	}
+	recordKey := sessionTag(id) + ":" + sessionRedisKey + ":" + sessionID
+	return recordKey, recordKey + ":" + tokenRedisKey, nil
+}
+
+// CreateSession 创建会话并保存Token和RefreshToken，返回因设备数量限制被踢出的会话ID
+// session.UserID 和 session.Device 不能为空，ID 为空时自动生成，LoginAt 为空时使用当前时间；
+// 已存在相同ID的会话时覆盖原会话
+func (c *Client) CreateSession(ctx context.Context, session *Session, tokenValue, refreshTokenValue string, expiration, refreshExpiration time.Duration) ([]string, error) {
+	if session == nil {
+		return nil, errors.New("会话不能为空")
+	}
+	if session.Device == "" {
+		return nil, errors.New("设备类型不能为空")
+	}
+	indexKey, err := sessionIndexKey(session.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if session.ID == "" {
+		session.ID = randomToken()
+	}
+	if session.LoginAt.IsZero() {
+		session.LoginAt = time.Now()
+	}
+	recordKey, tokenKey, err := sessionKeys(session.UserID, session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	tc := c.tokenClient()
+	limit := c.sessionLimits[session.Device]
+
+	var evicted []string
+	err = tc.Tx(ctx, func(tx *Tx) error {
+		sessions, stale, err := tc.activeSessions(ctx, tx.Raw(), session.UserID)
+		if err != nil {
+			return err
+		}
+		indexTTL, err := tx.Raw().PTTL(ctx, tc.key(indexKey)).Result()
+		if err != nil {
+			return err
+		}
+
+		// 同类型设备的会话超过限制时，踢出登录时间最早的会话
+		evicted = evicted[:0]
+		if limit > 0 {
+			var same []string
+			for _, s := range sessions {
+				if s.Device == session.Device && s.ID != session.ID {
+					same = append(same, s.ID)
+				}
+			}
+			if n := len(same) - limit + 1; n > 0 {
+				evicted = append(evicted, same[:n]...)
+			}
+		}
+
+		return tx.Exec(ctx, func(p *Pipe) error {
+			tc.removeSessions(ctx, p, session.UserID, append(stale, evicted...))
+
+			p.Del(ctx, recordKey)
+			p.HSetFields(ctx, recordKey, session)
+			p.HSet(ctx, recordKey, sessionRefreshField, refreshTokenValue)
+			if refreshExpiration > 0 {
+				p.Expire(ctx, recordKey, refreshExpiration)
+			}
+			p.Set(ctx, tokenKey, tokenValue, expiration)
+			p.ZAdd(ctx, indexKey, redis.Z{Score: float64(session.LoginAt.UnixMilli()), Member: session.ID})
+			tc.expireIndex(ctx, p, indexKey, indexTTL, refreshExpiration)
+			return nil
+		})
+	}, indexKey)
+	if err != nil {
+		return nil, fmt.Errorf("创建会话失败: %w", err)
+	}
+
+	return evicted, nil
+}
+
+// GetSession 获取会话信息，会话不存在或已过期时返回 ErrNotFound
+func (c *Client) GetSession(ctx context.Context, id, sessionID string) (*Session, error) {
+	recordKey, _, err := sessionKeys(id, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &Session{ID: sessionID, UserID: id}
+	if err := c.tokenClient().HGetAllStruct(ctx, recordKey, session); err != nil {
+		return nil, fmt.Errorf("获取会话失败: %w", err)
+	}
+	return session, nil
+}
+
+// GetSessionToken 获取会话的Token和RefreshToken
+// Token过期或会话不存在时返回 ErrNotFound，可通过 IsNotFound 与Redis异常区分
+func (c *Client) GetSessionToken(ctx context.Context, id, sessionID string) (string, string, error) {
+	recordKey, tokenKey, err := sessionKeys(id, sessionID)
+	if err != nil {
+		return "", "", err
+	}
+	tc := c.tokenClient()
+
+	// 获取Token
+	tokenValue, err := tc.Get(ctx, tokenKey)
+	if err != nil {
+		return "", "", fmt.Errorf("获取Token失败: %w", err)
+	}
+
+	// 获取RefreshToken
+	refreshTokenValue, err := tc.HGet(ctx, recordKey, sessionRefreshField)
+	if err != nil {
+		return "", "", fmt.Errorf("获取RefreshToken失败: %w", err)
+	}
+
+	return tokenValue, refreshTokenValue, nil
+}
+
+// RefreshSession 更新会话的Token和RefreshToken并延长会话有效期，会话不存在或已过期时返回 ErrNotFound
+func (c *Client) RefreshSession(ctx context.Context, id, sessionID, tokenValue, refreshTokenValue string, expiration, refreshExpiration time.Duration) error {
+	recordKey, tokenKey, err := sessionKeys(id, sessionID)
+	if err != nil {
+		return err
+	}
+	indexKey, _ := sessionIndexKey(id)
+	tc := c.tokenClient()
+
+	err = tc.Tx(ctx, func(tx *Tx) error {
+		if _, err := tx.HGet(ctx, recordKey, sessionRefreshField); err != nil {
+			return err
+		}
+		indexTTL, err := tx.Raw().PTTL(ctx, tc.key(indexKey)).Result()
+		if err != nil {
+			return err
+		}
+		return tx.Exec(ctx, func(p *Pipe) error {
+			p.HSet(ctx, recordKey, sessionRefreshField, refreshTokenValue)
+			if refreshExpiration > 0 {
+				p.Expire(ctx, recordKey, refreshExpiration)
+			}
+			p.Set(ctx, tokenKey, tokenValue, expiration)
+			tc.expireIndex(ctx, p, indexKey, indexTTL, refreshExpiration)
+			return nil
+		})
+	}, recordKey, indexKey)
+	if err != nil {
+		return fmt.Errorf("刷新会话失败: %w", err)
+	}
+
+	return nil
+}
+
+// ListSessions 获取用户所有有效会话，按登录时间从早到晚排序
+// Token已过期但RefreshToken仍有效的会话视为有效会话；已过期的会话从索引中清理
+func (c *Client) ListSessions(ctx context.Context, id string) ([]Session, error) {
+	if _, err := sessionIndexKey(id); err != nil {
+		return nil, err
+	}
+	tc := c.tokenClient()
+
+	sessions, stale, err := tc.activeSessions(ctx, tc.UniversalClient, id)
+	if err != nil {
+		return nil, fmt.Errorf("获取会话列表失败: %w", err)
+	}
+	if len(stale) > 0 {
+		if err := tc.TxPipeline(ctx, func(p *Pipe) error {
+			tc.removeSessions(ctx, p, id, stale)
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("清理过期会话失败: %w", err)
+		}
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession 撤销单个会话，删除会话记录、Token并从索引中移除
+func (c *Client) RevokeSession(ctx context.Context, id, sessionID string) error {
+	if _, _, err := sessionKeys(id, sessionID); err != nil {
+		return err
+	}
+	tc := c.tokenClient()
+
+	err := tc.TxPipeline(ctx, func(p *Pipe) error {
+		tc.removeSessions(ctx, p, id, []string{sessionID})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("撤销会话失败: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeSessions 撤销用户的所有会话
+func (c *Client) RevokeSessions(ctx context.Context, id string) error {
+	indexKey, err := sessionIndexKey(id)
+	if err != nil {
+		return err
+	}
+	tc := c.tokenClient()
+
+	err = tc.Tx(ctx, func(tx *Tx) error {
+		ids, err := tx.Raw().ZRange(ctx, tc.key(indexKey), 0, -1).Result()
+		if err != nil {
+			return err
+		}
+		return tx.Exec(ctx, func(p *Pipe) error {
+			tc.removeSessions(ctx, p, id, ids)
+			p.Del(ctx, indexKey)
+			return nil
+		})
+	}, indexKey)
+	if err != nil {
+		return fmt.Errorf("撤销所有会话失败: %w", err)
+	}
+
+	return nil
+}
+
+// VerifySessionRefreshToken 验证会话的刷新Token
+func (c *Client) VerifySessionRefreshToken(ctx context.Context, id, sessionID, oldToken, oldRefreshToken, secret string) error {
+	if err := verifyTokenOwner(id, oldToken, oldRefreshToken, secret); err != nil {
+		return err
+	}
+
+	recordKey, _, err := sessionKeys(id, sessionID)
+	if err != nil {
+		return err
+	}
+
+	// 获取会话的RefreshToken
+	refreshTokenValue, err := c.tokenClient().HGet(ctx, recordKey, sessionRefreshField)
+	if IsNotFound(err) {
+		return errors.New("刷新Token无效")
+	}
+	if err != nil {
+		return err
+	}
+
+	// 验证刷新Token是否一致
+	if refreshTokenValue != oldRefreshToken {
+		return errors.New("刷新Token无效")
+	}
+
+	return nil
+}
+
+// activeSessions 读取用户会话索引中的会话，返回有效会话（按登录时间排序）和已过期的会话ID
+func (c *Client) activeSessions(ctx context.Context, cmd redis.Cmdable, id string) ([]Session, []string, error) {
+	indexKey, err := sessionIndexKey(id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids, err := cmd.ZRange(ctx, c.key(indexKey), 0, -1).Result()
+	if err != nil || len(ids) == 0 {
+		return nil, nil, err
+	}
+
+	cmds := make([]*redis.MapStringStringCmd, len(ids))
+	_, err = cmd.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, sessionID := range ids {
+			recordKey, _, _ := sessionKeys(id, sessionID)
+			cmds[i] = p.HGetAll(ctx, c.key(recordKey))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sessions := make([]Session, 0, len(ids))
+	var stale []string
+	for i, sessionID := range ids {
+		fields := cmds[i].Val()
+		if len(fields) == 0 {
+			stale = append(stale, sessionID)
+			continue
+		}
+		session := Session{ID: sessionID, UserID: id}
+		if err := c.hashToStruct(reflect.ValueOf(&session), fields); err != nil {
+			return nil, nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, stale, nil
+}
+
+// removeSessions 在管道中删除会话记录、会话Token并从索引中移除
+func (c *Client) removeSessions(ctx context.Context, p *Pipe, id string, sessionIDs []string) {
+	if len(sessionIDs) == 0 {
+		return
+	}
+	indexKey, _ := sessionIndexKey(id)
+
+	keys := make([]string, 0, len(sessionIDs)*2)
+	members := make([]interface{}, len(sessionIDs))
+	for i, sessionID := range sessionIDs {
+		recordKey, tokenKey, _ := sessionKeys(id, sessionID)
+		keys = append(keys, recordKey, tokenKey)
+		members[i] = sessionID
+	}
+	p.Del(ctx, keys...)
+	p.Raw().ZRem(ctx, c.key(indexKey), members...)
+}
+
+// expireIndex 在管道中延长会话索引的有效期，使其不早于会话记录过期
+// ttl 为事务中读取的索引剩余有效期（PTTL）：-1 表示索引包含永久会话，不再设置过期时间；
+// 会话永久有效（expiration<=0）时移除索引的过期时间
+// 调用方需 WATCH 索引键，保证读取的有效期在执行时仍然成立（不依赖 Redis 7 的 EXPIRE NX/GT）
+func (c *Client) expireIndex(ctx context.Context, p *Pipe, indexKey string, ttl, expiration time.Duration) {
+	key := c.key(indexKey)
+	switch {
+	case expiration <= 0:
+		p.Raw().Persist(ctx, key)
+	case ttl == -1:
+		// 索引中有永久会话，保持永久
+	case ttl < expiration:
+		p.Raw().PExpire(ctx, key, expiration)
+	}
+}
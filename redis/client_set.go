@@ -2,6 +2,7 @@ package redis
 
 import (
 	"context"
+	"iter"
 )
 
 // Set 集合操作接口
@@ -27,6 +28,8 @@ type Set interface {
 	SRandMemberN(ctx context.Context, key string, count int64) ([]string, error)
 	SRandMemberNStruct(ctx context.Context, key string, count int64, value interface{}) error
 	SRem(ctx context.Context, key string, members ...interface{}) (int64, error)
+	SScanIter(ctx context.Context, key string, opts ...ScanOption) iter.Seq2[string, error]
+	SScanAll(ctx context.Context, key string, opts ...ScanOption) ([]string, error)
 	SUnion(ctx context.Context, keys ...string) ([]string, error)
 	SUnionStruct(ctx context.Context, value interface{}, keys ...string) error
 	SUnionStore(ctx context.Context, destination string, keys ...string) (int64, error)
@@ -35,17 +38,21 @@ type Set interface {
 
 // SAdd 向集合添加成员
 func (c *Client) SAdd(ctx context.Context, key string, members ...interface{}) (int64, error) {
-	return c.UniversalClient.SAdd(ctx, key, members...).Result()
+	members, err := c.marshalValues(members)
+	if err != nil {
+		return 0, err
+	}
+	return wrapResult(c.UniversalClient.SAdd(ctx, c.key(key), members...).Result())
 }
 
 // SCard 获取集合成员数量
 func (c *Client) SCard(ctx context.Context, key string) (int64, error) {
-	return c.UniversalClient.SCard(ctx, key).Result()
+	return wrapResult(c.UniversalClient.SCard(ctx, c.key(key)).Result())
 }
 
 // SDiff 获取多个集合的差集
 func (c *Client) SDiff(ctx context.Context, keys ...string) ([]string, error) {
-	return c.UniversalClient.SDiff(ctx, keys...).Result()
+	return wrapResult(c.UniversalClient.SDiff(ctx, c.keys(keys)...).Result())
 }
 
 // SDiffStruct 获取多个集合的差集，反序列化到结构体切片
@@ -57,12 +64,12 @@ func (c *Client) SDiffStruct(ctx context.Context, value interface{}, keys ...str
 
 // SDiffStore 将多个集合的差集存储到目标集合
 func (c *Client) SDiffStore(ctx context.Context, destination string, keys ...string) (int64, error) {
-	return c.UniversalClient.SDiffStore(ctx, destination, keys...).Result()
+	return wrapResult(c.UniversalClient.SDiffStore(ctx, c.key(destination), c.keys(keys)...).Result())
 }
 
 // SInter 获取多个集合的交集
 func (c *Client) SInter(ctx context.Context, keys ...string) ([]string, error) {
-	return c.UniversalClient.SInter(ctx, keys...).Result()
+	return wrapResult(c.UniversalClient.SInter(ctx, c.keys(keys)...).Result())
 }
 
 // SInterStruct 获取多个集合的交集，反序列化到结构体切片
@@ -74,17 +81,21 @@ func (c *Client) SInterStruct(ctx context.Context, value interface{}, keys ...st
 
 // SInterStore 将多个集合的交集存储到目标集合
 func (c *Client) SInterStore(ctx context.Context, destination string, keys ...string) (int64, error) {
-	return c.UniversalClient.SInterStore(ctx, destination, keys...).Result()
+	return wrapResult(c.UniversalClient.SInterStore(ctx, c.key(destination), c.keys(keys)...).Result())
 }
 
 // SIsMember 判断成员是否在集合中
 func (c *Client) SIsMember(ctx context.Context, key string, member interface{}) (bool, error) {
-	return c.UniversalClient.SIsMember(ctx, key, member).Result()
+	data, err := c.marshalValue(member)
+	if err != nil {
+		return false, err
+	}
+	return wrapResult(c.UniversalClient.SIsMember(ctx, c.key(key), data).Result())
 }
 
 // SMembers 获取集合所有成员
 func (c *Client) SMembers(ctx context.Context, key string) ([]string, error) {
-	return c.UniversalClient.SMembers(ctx, key).Result()
+	return wrapResult(c.UniversalClient.SMembers(ctx, c.key(key)).Result())
 }
 
 // SMembersStruct 获取集合所有成员，反序列化到结构体切片
@@ -96,12 +107,16 @@ func (c *Client) SMembersStruct(ctx context.Context, key string, value interface
 
 // SMove 将成员从一个集合移动到另一个集合
 func (c *Client) SMove(ctx context.Context, source, destination string, member interface{}) (bool, error) {
-	return c.UniversalClient.SMove(ctx, source, destination, member).Result()
+	data, err := c.marshalValue(member)
+	if err != nil {
+		return false, err
+	}
+	return wrapResult(c.UniversalClient.SMove(ctx, c.key(source), c.key(destination), data).Result())
 }
 
 // SPop 随机移除并返回集合中的一个成员
 func (c *Client) SPop(ctx context.Context, key string) (string, error) {
-	return c.UniversalClient.SPop(ctx, key).Result()
+	return wrapResult(c.UniversalClient.SPop(ctx, c.key(key)).Result())
 }
 
 // SPopStruct 随机移除并返回集合中的一个成员，反序列化到结构体
@@ -113,7 +128,7 @@ func (c *Client) SPopStruct(ctx context.Context, key string, value interface{})
 
 // SPopN 随机移除并返回集合中的N个成员
 func (c *Client) SPopN(ctx context.Context, key string, count int64) ([]string, error) {
-	return c.UniversalClient.SPopN(ctx, key, count).Result()
+	return wrapResult(c.UniversalClient.SPopN(ctx, c.key(key), count).Result())
 }
 
 // SPopNStruct 随机移除并返回集合中的N个成员，反序列化到结构体切片
@@ -125,7 +140,7 @@ func (c *Client) SPopNStruct(ctx context.Context, key string, count int64, value
 
 // SRandMember 随机返回集合中的一个成员（不移除）
 func (c *Client) SRandMember(ctx context.Context, key string) (string, error) {
-	return c.UniversalClient.SRandMember(ctx, key).Result()
+	return wrapResult(c.UniversalClient.SRandMember(ctx, c.key(key)).Result())
 }
 
 // SRandMemberStruct 随机返回集合中的一个成员（不移除），反序列化到结构体
@@ -137,7 +152,7 @@ func (c *Client) SRandMemberStruct(ctx context.Context, key string, value interf
 
 // SRandMemberN 随机返回集合中的N个成员（不移除）
 func (c *Client) SRandMemberN(ctx context.Context, key string, count int64) ([]string, error) {
-	return c.UniversalClient.SRandMemberN(ctx, key, count).Result()
+	return wrapResult(c.UniversalClient.SRandMemberN(ctx, c.key(key), count).Result())
 }
 
 // SRandMemberNStruct 随机返回集合中的N个成员（不移除），反序列化到结构体切片
@@ -149,12 +164,16 @@ func (c *Client) SRandMemberNStruct(ctx context.Context, key string, count int64
 
 // SRem 从集合中移除成员
 func (c *Client) SRem(ctx context.Context, key string, members ...interface{}) (int64, error) {
-	return c.UniversalClient.SRem(ctx, key, members...).Result()
+	members, err := c.marshalValues(members)
+	if err != nil {
+		return 0, err
+	}
+	return wrapResult(c.UniversalClient.SRem(ctx, c.key(key), members...).Result())
 }
 
 // SUnion 获取多个集合的并集
 func (c *Client) SUnion(ctx context.Context, keys ...string) ([]string, error) {
-	return c.UniversalClient.SUnion(ctx, keys...).Result()
+	return wrapResult(c.UniversalClient.SUnion(ctx, c.keys(keys)...).Result())
 }
 
 // SUnionStruct 获取多个集合的并集，反序列化到结构体切片
@@ -166,10 +185,10 @@ func (c *Client) SUnionStruct(ctx context.Context, value interface{}, keys ...st
 
 // SUnionStore 将多个集合的并集存储到目标集合
 func (c *Client) SUnionStore(ctx context.Context, destination string, keys ...string) (int64, error) {
-	return c.UniversalClient.SUnionStore(ctx, destination, keys...).Result()
+	return wrapResult(c.UniversalClient.SUnionStore(ctx, c.key(destination), c.keys(keys)...).Result())
 }
 
 // SSCan 扫描集合成员
 func (c *Client) SSCan(ctx context.Context, key string, cursor uint64, match string, count int64) ([]string, uint64, error) {
-	return c.UniversalClient.SScan(ctx, key, cursor, match, count).Result()
+	return c.UniversalClient.SScan(ctx, c.key(key), cursor, match, count).Result()
 }
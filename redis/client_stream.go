@@ -0,0 +1,548 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nilchaosky/go-nexus/logz"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	// streamPayloadField 消息内容字段名
+	streamPayloadField = "payload"
+	// streamDeadLetterSuffix 默认死信流后缀
+	streamDeadLetterSuffix = ":DLQ"
+)
+
+// StreamOption 写入流的选项
+type StreamOption func(*redis.XAddArgs)
+
+// StreamMaxLen 写入时近似裁剪流，保留约n条最新消息（MAXLEN ~ n）
+func StreamMaxLen(n int64) StreamOption {
+	return func(a *redis.XAddArgs) {
+		a.MaxLen = n
+		a.MinID = ""
+		a.Approx = true
+	}
+}
+
+// StreamMaxLenExact 写入时精确裁剪流，严格保留n条最新消息（MAXLEN = n）
+func StreamMaxLenExact(n int64) StreamOption {
+	return func(a *redis.XAddArgs) {
+		a.MaxLen = n
+		a.MinID = ""
+		a.Approx = false
+	}
+}
+
+// StreamMinID 写入时近似裁剪流，删除ID小于minID的消息（MINID ~ minID）
+func StreamMinID(minID string) StreamOption {
+	return func(a *redis.XAddArgs) {
+		a.MaxLen = 0
+		a.MinID = minID
+		a.Approx = true
+	}
+}
+
+// StreamID 指定消息ID（默认：* 由服务器生成）
+func StreamID(id string) StreamOption {
+	return func(a *redis.XAddArgs) {
+		a.ID = id
+	}
+}
+
+// XAddStruct 序列化消息内容并写入流，返回消息ID
+// 字符串和 []byte 原样写入，其他值使用客户端序列化器编码（与 NewConsumer[T] 的解码对应）
+func (c *Client) XAddStruct(ctx context.Context, stream string, value interface{}, opts ...StreamOption) (string, error) {
+	data, err := encodeAs(c, value)
+	if err != nil {
+		return "", err
+	}
+
+	args := &redis.XAddArgs{Stream: c.key(stream), Values: []interface{}{streamPayloadField, data}}
+	for _, opt := range opts {
+		opt(args)
+	}
+
+	return c.UniversalClient.XAdd(ctx, args).Result()
+}
+
+// StreamMessage 流消息
+type StreamMessage[T any] struct {
+	ID         string // 消息ID
+	Stream     string // 所属流
+	Payload    T      // 反序列化后的消息内容
+	Deliveries int64  // 投递次数（首次投递为1）
+}
+
+// StreamHandler 流消息处理函数
+// 返回nil时自动确认消息（ConsumerManualAck 时需调用 Consumer.Ack）；返回错误时消息保留在待处理列表，空闲超时后重新投递
+type StreamHandler[T any] func(ctx context.Context, msg *StreamMessage[T]) error
+
+// ConsumerOption 消费者选项
+type ConsumerOption func(*consumerOptions)
+
+// consumerOptions 消费者选项
+type consumerOptions struct {
+	name          string        // 消费者名称
+	concurrency   int           // 并发处理的协程数
+	batch         int64         // 单次读取的消息数
+	block         time.Duration // 读取阻塞时间
+	startID       string        // 创建消费组时的起始ID
+	claimIdle     time.Duration // 待处理消息空闲多久后被重新认领
+	claimInterval time.Duration // 认领检查间隔
+	maxDeliveries int64         // 最大投递次数
+	deadLetter    string        // 死信流
+	deadLetterLen int64         // 死信流最大长度
+	manualAck     bool          // 是否手动确认
+}
+
+// ConsumerName 设置消费者名称（默认：主机名-随机串），重启后使用相同名称可继续处理自己的待处理消息
+func ConsumerName(name string) ConsumerOption {
+	return func(o *consumerOptions) {
+		if name != "" {
+			o.name = name
+		}
+	}
+}
+
+// ConsumerConcurrency 设置并发处理消息的协程数（默认：4）
+func ConsumerConcurrency(n int) ConsumerOption {
+	return func(o *consumerOptions) {
+		if n > 0 {
+			o.concurrency = n
+		}
+	}
+}
+
+// ConsumerBatch 设置单次读取的消息数（默认：10）
+func ConsumerBatch(n int64) ConsumerOption {
+	return func(o *consumerOptions) {
+		if n > 0 {
+			o.batch = n
+		}
+	}
+}
+
+// ConsumerBlock 设置读取阻塞时间（默认：5秒），也是停止消费时的最长等待时间
+func ConsumerBlock(d time.Duration) ConsumerOption {
+	return func(o *consumerOptions) {
+		if d > 0 {
+			o.block = d
+		}
+	}
+}
+
+// ConsumerStartID 设置自动创建消费组时的起始ID（默认：$ 仅消费新消息，0 从头消费）
+func ConsumerStartID(id string) ConsumerOption {
+	return func(o *consumerOptions) {
+		if id != "" {
+			o.startID = id
+		}
+	}
+}
+
+// ConsumerClaim 设置待处理消息的重新认领：空闲超过idle的消息每隔interval通过 XAUTOCLAIM 认领（默认：1分钟、30秒，idle为0时不认领）
+func ConsumerClaim(idle, interval time.Duration) ConsumerOption {
+	return func(o *consumerOptions) {
+		if idle >= 0 {
+			o.claimIdle = idle
+		}
+		if interval > 0 {
+			o.claimInterval = interval
+		}
+	}
+}
+
+// ConsumerMaxDeliveries 设置最大投递次数，超过后消息转入死信流并确认（默认：5，0为不限制）
+func ConsumerMaxDeliveries(n int64) ConsumerOption {
+	return func(o *consumerOptions) {
+		if n >= 0 {
+			o.maxDeliveries = n
+		}
+	}
+}
+
+// ConsumerDeadLetter 设置死信流名称及最大长度（默认：{stream}:DLQ、10000，maxLen为0时不裁剪）
+func ConsumerDeadLetter(stream string, maxLen int64) ConsumerOption {
+	return func(o *consumerOptions) {
+		if stream != "" {
+			o.deadLetter = stream
+		}
+		if maxLen >= 0 {
+			o.deadLetterLen = maxLen
+		}
+	}
+}
+
+// ConsumerManualAck 处理成功后不自动确认，由调用方通过 Consumer.Ack 确认
+func ConsumerManualAck() ConsumerOption {
+	return func(o *consumerOptions) {
+		o.manualAck = true
+	}
+}
+
+// Consumer 流消费组消费者
+// 运行时自动创建消费组，通过 XREADGROUP 阻塞读取新消息并交由固定数量的协程处理；
+// 空闲超时的待处理消息通过 XAUTOCLAIM 重新认领，超过最大投递次数的消息转入死信流
+type Consumer[T any] struct {
+	client  *Client
+	stream  string
+	group   string
+	handler StreamHandler[T]
+	opts    consumerOptions
+}
+
+// NewConsumer 创建流消费者
+func NewConsumer[T any](c *Client, stream, group string, handler StreamHandler[T], opts ...ConsumerOption) (*Consumer[T], error) {
+	if stream == "" {
+		return nil, errors.New("流名称不能为空")
+	}
+	if group == "" {
+		return nil, errors.New("消费组名称不能为空")
+	}
+	if handler == nil {
+		return nil, errors.New("消息处理函数不能为空")
+	}
+
+	o := consumerOptions{
+		concurrency:   4,
+		batch:         10,
+		block:         5 * time.Second,
+		startID:       "$",
+		claimIdle:     time.Minute,
+		claimInterval: 30 * time.Second,
+		maxDeliveries: 5,
+		deadLetter:    stream + streamDeadLetterSuffix,
+		deadLetterLen: 10000,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.name == "" {
+		hostname, _ := os.Hostname()
+		o.name = hostname + "-" + randomToken()[:8]
+	}
+	o.deadLetter = c.key(o.deadLetter)
+
+	return &Consumer[T]{client: c, stream: c.key(stream), group: group, handler: handler, opts: o}, nil
+}
+
+// Name 获取消费者名称
+func (c *Consumer[T]) Name() string {
+	return c.opts.name
+}
+
+// DeadLetter 获取死信流名称
+func (c *Consumer[T]) DeadLetter() string {
+	return c.opts.deadLetter
+}
+
+// Ack 确认消息
+func (c *Consumer[T]) Ack(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return c.client.UniversalClient.XAck(ctx, c.stream, c.group, ids...).Err()
+}
+
+// Run 运行消费者，阻塞直到ctx结束；返回前等待正在处理的消息完成
+func (c *Consumer[T]) Run(ctx context.Context) error {
+	if err := c.createGroup(ctx); err != nil {
+		return err
+	}
+
+	// 处理函数不受停止信号影响，停止时等待其完成
+	handlerCtx := context.WithoutCancel(ctx)
+	messages := make(chan *StreamMessage[T])
+	var wg sync.WaitGroup
+	for i := 0; i < c.opts.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for msg := range messages {
+				c.handle(handlerCtx, msg)
+			}
+		}()
+	}
+
+	var loops sync.WaitGroup
+	loops.Add(1)
+	go func() {
+		defer loops.Done()
+		c.read(ctx, messages)
+	}()
+	if c.opts.claimIdle > 0 {
+		loops.Add(1)
+		go func() {
+			defer loops.Done()
+			c.claim(ctx, messages)
+		}()
+	}
+
+	loops.Wait()
+	close(messages)
+	wg.Wait()
+
+	return nil
+}
+
+// createGroup 创建消费组（流不存在时同时创建流），消费组已存在时忽略
+func (c *Consumer[T]) createGroup(ctx context.Context) error {
+	err := c.client.UniversalClient.XGroupCreateMkStream(ctx, c.stream, c.group, c.opts.startID).Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("创建消费组失败: %w", err)
+	}
+	return nil
+}
+
+// read 循环读取新消息
+func (c *Consumer[T]) read(ctx context.Context, messages chan<- *StreamMessage[T]) {
+	for ctx.Err() == nil {
+		streams, err := c.client.UniversalClient.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    c.group,
+			Consumer: c.opts.name,
+			Streams:  []string{c.stream, ">"},
+			Count:    c.opts.batch,
+			Block:    c.opts.block,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			// 消费组或流被删除时重新创建
+			if strings.HasPrefix(err.Error(), "NOGROUP") {
+				err = c.createGroup(ctx)
+			}
+			if err != nil {
+				logz.Logger.Warn("读取流消息失败",
+					zap.String("stream", c.stream),
+					zap.String("group", c.group),
+					zap.Error(err),
+				)
+				c.sleep(ctx, time.Second)
+			}
+			continue
+		}
+
+		for _, stream := range streams {
+			for _, msg := range stream.Messages {
+				if !c.dispatch(ctx, msg, 1, messages) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// claim 定期认领空闲超时的待处理消息
+func (c *Consumer[T]) claim(ctx context.Context, messages chan<- *StreamMessage[T]) {
+	for c.sleep(ctx, c.opts.claimInterval) {
+		start := "0-0"
+		for ctx.Err() == nil {
+			claimed, next, err := c.client.UniversalClient.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+				Stream:   c.stream,
+				Group:    c.group,
+				MinIdle:  c.opts.claimIdle,
+				Start:    start,
+				Count:    c.opts.batch,
+				Consumer: c.opts.name,
+			}).Result()
+			if err != nil {
+				if ctx.Err() == nil {
+					logz.Logger.Warn("认领待处理消息失败",
+						zap.String("stream", c.stream),
+						zap.String("group", c.group),
+						zap.Error(err),
+					)
+				}
+				break
+			}
+
+			if len(claimed) > 0 {
+				deliveries, err := c.deliveries(ctx, claimed)
+				if err != nil {
+					logz.Logger.Warn("获取消息投递次数失败",
+						zap.String("stream", c.stream),
+						zap.Error(err),
+					)
+					break
+				}
+				for _, msg := range claimed {
+					if !c.dispatch(ctx, msg, deliveries[msg.ID], messages) {
+						return
+					}
+				}
+			}
+
+			if next == "" || next == "0-0" {
+				break
+			}
+			start = next
+		}
+	}
+}
+
+// deliveries 获取已认领消息的投递次数
+// 同一消费者在已认领消息之间可能还有其他待处理消息，按区间查询会漏掉部分ID，
+// 因此逐条按ID查询（起止ID相同），通过管道一次发送
+func (c *Consumer[T]) deliveries(ctx context.Context, msgs []redis.XMessage) (map[string]int64, error) {
+	pipe := c.client.UniversalClient.Pipeline()
+	cmds := make([]*redis.XPendingExtCmd, len(msgs))
+	for i, msg := range msgs {
+		cmds[i] = pipe.XPendingExt(ctx, &redis.XPendingExtArgs{
+			Stream:   c.stream,
+			Group:    c.group,
+			Start:    msg.ID,
+			End:      msg.ID,
+			Count:    1,
+			Consumer: c.opts.name,
+		})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]int64, len(msgs))
+	for _, cmd := range cmds {
+		for _, p := range cmd.Val() {
+			results[p.ID] = p.RetryCount
+		}
+	}
+	return results, nil
+}
+
+// dispatch 反序列化消息并分发给处理协程，超过最大投递次数或无法反序列化的消息转入死信流
+// ctx 结束时返回false
+func (c *Consumer[T]) dispatch(ctx context.Context, msg redis.XMessage, deliveries int64, messages chan<- *StreamMessage[T]) bool {
+	if deliveries <= 0 {
+		deliveries = 1
+	}
+
+	if c.opts.maxDeliveries > 0 && deliveries > c.opts.maxDeliveries {
+		c.deadLetter(ctx, msg, deliveries, "超过最大投递次数")
+		return true
+	}
+
+	data, _ := msg.Values[streamPayloadField].(string)
+	payload, err := decodeAs[T](c.client, data)
+	if err != nil {
+		c.deadLetter(ctx, msg, deliveries, err.Error())
+		return true
+	}
+
+	select {
+	case messages <- &StreamMessage[T]{ID: msg.ID, Stream: c.stream, Payload: payload, Deliveries: deliveries}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// handle 执行处理函数，成功时确认消息；恢复处理函数的panic
+func (c *Consumer[T]) handle(ctx context.Context, msg *StreamMessage[T]) {
+	defer func() {
+		if r := recover(); r != nil {
+			logz.Logger.Error("流消息处理异常",
+				zap.String("stream", c.stream),
+				zap.String("id", msg.ID),
+				zap.Any("panic", r),
+				zap.Stack("stack"),
+			)
+		}
+	}()
+
+	if err := c.handler(ctx, msg); err != nil {
+		logz.Logger.Warn("流消息处理失败",
+			zap.String("stream", c.stream),
+			zap.String("id", msg.ID),
+			zap.Int64("deliveries", msg.Deliveries),
+			zap.Error(err),
+		)
+		return
+	}
+
+	if c.opts.manualAck {
+		return
+	}
+	if err := c.Ack(ctx, msg.ID); err != nil {
+		logz.Logger.Warn("确认流消息失败",
+			zap.String("stream", c.stream),
+			zap.String("id", msg.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+// deadLetter 将消息写入死信流并确认原消息
+// 死信消息保留原字段，并附加来源流、原消息ID、消费组、投递次数和原因
+// 死信流与原流在集群模式下可能位于不同槽位，因此不使用事务：先写入死信流，成功后再确认，
+// 确认失败时消息会再次投递并重复写入死信流（至少一次）
+func (c *Consumer[T]) deadLetter(ctx context.Context, msg redis.XMessage, deliveries int64, reason string) {
+	values := make([]interface{}, 0, len(msg.Values)*2+10)
+	for field, value := range msg.Values {
+		values = append(values, field, value)
+	}
+	values = append(values,
+		"source_stream", c.stream,
+		"source_id", msg.ID,
+		"group", c.group,
+		"deliveries", strconv.FormatInt(deliveries, 10),
+		"reason", reason,
+	)
+
+	args := &redis.XAddArgs{Stream: c.opts.deadLetter, Values: values}
+	if c.opts.deadLetterLen > 0 {
+		args.MaxLen = c.opts.deadLetterLen
+		args.Approx = true
+	}
+
+	if err := c.client.UniversalClient.XAdd(ctx, args).Err(); err != nil {
+		logz.Logger.Warn("写入死信流失败",
+			zap.String("stream", c.stream),
+			zap.String("id", msg.ID),
+			zap.Error(err),
+		)
+		return
+	}
+	if err := c.client.UniversalClient.XAck(ctx, c.stream, c.group, msg.ID).Err(); err != nil {
+		logz.Logger.Warn("确认死信消息失败",
+			zap.String("stream", c.stream),
+			zap.String("id", msg.ID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	logz.Logger.Warn("流消息已转入死信流",
+		zap.String("stream", c.stream),
+		zap.String("id", msg.ID),
+		zap.String("dead_letter", c.opts.deadLetter),
+		zap.Int64("deliveries", deliveries),
+		zap.String("reason", reason),
+	)
+}
+
+// sleep 等待指定时间，ctx 结束时返回false
+func (c *Consumer[T]) sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
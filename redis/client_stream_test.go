@@ -0,0 +1,332 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nilchaosky/go-nexus/redis"
+	"github.com/nilchaosky/go-nexus/redis/redistest"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// runConsumer 在后台运行消费者，测试结束时停止并等待其返回
+func runConsumer[T any](t *testing.T, consumer *redis.Consumer[T]) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := consumer.Run(ctx); err != nil {
+			t.Errorf("消费者运行失败: %v", err)
+		}
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+// collector 记录处理函数收到的消息
+type collector[T any] struct {
+	mu       sync.Mutex
+	messages []*redis.StreamMessage[T]
+	notify   chan struct{}
+}
+
+// newCollector 创建消息记录器
+func newCollector[T any]() *collector[T] {
+	return &collector[T]{notify: make(chan struct{}, 100)}
+}
+
+// add 记录消息
+func (c *collector[T]) add(msg *redis.StreamMessage[T]) {
+	c.mu.Lock()
+	c.messages = append(c.messages, msg)
+	c.mu.Unlock()
+	c.notify <- struct{}{}
+}
+
+// wait 等待收到n条消息，超时返回false
+func (c *collector[T]) wait(n int) bool {
+	timeout := time.After(3 * time.Second)
+	for {
+		c.mu.Lock()
+		count := len(c.messages)
+		c.mu.Unlock()
+		if count >= n {
+			return true
+		}
+		select {
+		case <-c.notify:
+		case <-timeout:
+			return false
+		}
+	}
+}
+
+// list 获取收到的全部消息（按接收顺序）
+func (c *collector[T]) list() []*redis.StreamMessage[T] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*redis.StreamMessage[T](nil), c.messages...)
+}
+
+// byID 按消息ID获取收到的消息
+func (c *collector[T]) byID() map[string]*redis.StreamMessage[T] {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	results := make(map[string]*redis.StreamMessage[T], len(c.messages))
+	for _, msg := range c.messages {
+		results[msg.ID] = msg
+	}
+	return results
+}
+
+// TestConsumerClaimDeliveries 测试认领的消息之间夹有同一消费者其他待处理消息时，投递次数仍按ID准确获取
+func TestConsumerClaimDeliveries(t *testing.T) {
+	ctx := context.Background()
+	client, server := redistest.NewClient(t)
+	rdb := client.GetRawClient()
+
+	if err := rdb.XGroupCreateMkStream(ctx, "orders", "g", "0").Err(); err != nil {
+		t.Fatalf("创建消费组失败: %v", err)
+	}
+	var ids []string
+	for _, v := range []string{"a", "b", "c"} {
+		id, err := client.XAddStruct(ctx, "orders", v)
+		if err != nil {
+			t.Fatalf("XAddStruct失败: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	// c1 读取后未确认，三条消息均在其待处理列表中
+	if err := rdb.XReadGroup(ctx, &goredis.XReadGroupArgs{Group: "g", Consumer: "c1", Streams: []string{"orders", ">"}}).Err(); err != nil {
+		t.Fatalf("XReadGroup失败: %v", err)
+	}
+
+	// 中间的消息刚被重新投递给 c1，未达到空闲时间，不会被认领
+	server.Clock().Advance(2 * time.Minute)
+	if err := rdb.XClaim(ctx, &goredis.XClaimArgs{Stream: "orders", Group: "g", Consumer: "c1", Messages: []string{ids[1]}}).Err(); err != nil {
+		t.Fatalf("XClaim失败: %v", err)
+	}
+
+	received := newCollector[string]()
+	consumer, err := redis.NewConsumer(client, "orders", "g", func(_ context.Context, msg *redis.StreamMessage[string]) error {
+		received.add(msg)
+		return nil
+	}, redis.ConsumerName("c1"), redis.ConsumerBlock(50*time.Millisecond), redis.ConsumerClaim(time.Minute, 20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("创建消费者失败: %v", err)
+	}
+	runConsumer(t, consumer)
+
+	if !received.wait(2) {
+		t.Fatal("未收到认领的消息")
+	}
+	messages := received.byID()
+	for _, id := range []string{ids[0], ids[2]} {
+		msg, ok := messages[id]
+		if !ok {
+			t.Fatalf("期望认领消息%s，实际: %v", id, messages)
+		}
+		if msg.Deliveries != 2 {
+			t.Errorf("期望消息%s的投递次数为2，实际: %d", id, msg.Deliveries)
+		}
+	}
+	if _, ok := messages[ids[1]]; ok {
+		t.Errorf("未达到空闲时间的消息%s不应被认领", ids[1])
+	}
+}
+
+// TestConsumer 测试消费者从头读取新消息并自动确认
+func TestConsumer(t *testing.T) {
+	ctx := context.Background()
+	base, _ := redistest.NewClient(t)
+	client := base.WithPrefix("app:")
+	rdb := client.GetRawClient()
+
+	type order struct {
+		ID int `json:"id"`
+	}
+	received := newCollector[order]()
+	consumer, err := redis.NewConsumer(client, "orders", "g", func(_ context.Context, msg *redis.StreamMessage[order]) error {
+		received.add(msg)
+		return nil
+	}, redis.ConsumerName("c1"), redis.ConsumerStartID("0"), redis.ConsumerBlock(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("创建消费者失败: %v", err)
+	}
+	if consumer.DeadLetter() != "app:orders:DLQ" {
+		t.Errorf("期望死信流带前缀，实际: %s", consumer.DeadLetter())
+	}
+
+	// 消费者启动前写入的消息从头消费
+	if _, err := client.XAddStruct(ctx, "orders", order{ID: 1}); err != nil {
+		t.Fatalf("XAddStruct失败: %v", err)
+	}
+	runConsumer(t, consumer)
+	if _, err := client.XAddStruct(ctx, "orders", order{ID: 2}, redis.StreamMaxLen(100)); err != nil {
+		t.Fatalf("XAddStruct失败: %v", err)
+	}
+
+	if !received.wait(2) {
+		t.Fatal("未收到全部消息")
+	}
+	for _, msg := range received.byID() {
+		if msg.Deliveries != 1 || msg.Stream != "app:orders" || (msg.Payload.ID != 1 && msg.Payload.ID != 2) {
+			t.Errorf("消息不符: %+v", msg)
+		}
+	}
+
+	// 处理成功的消息被自动确认
+	deadline := time.Now().Add(time.Second)
+	for {
+		summary, err := rdb.XPending(ctx, "app:orders", "g").Result()
+		if err != nil {
+			t.Fatalf("XPending失败: %v", err)
+		}
+		if summary.Count == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("期望消息已确认，实际待处理数量: %d", summary.Count)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestConsumerManualAck 测试手动确认模式下处理成功的消息保留在待处理列表，直到调用 Ack
+func TestConsumerManualAck(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+	rdb := client.GetRawClient()
+
+	received := newCollector[string]()
+	consumer, err := redis.NewConsumer(client, "orders", "g", func(_ context.Context, msg *redis.StreamMessage[string]) error {
+		received.add(msg)
+		return nil
+	}, redis.ConsumerStartID("0"), redis.ConsumerBlock(50*time.Millisecond), redis.ConsumerManualAck())
+	if err != nil {
+		t.Fatalf("创建消费者失败: %v", err)
+	}
+	id, _ := client.XAddStruct(ctx, "orders", "a")
+	runConsumer(t, consumer)
+
+	if !received.wait(1) {
+		t.Fatal("未收到消息")
+	}
+	if summary, _ := rdb.XPending(ctx, "orders", "g").Result(); summary == nil || summary.Count != 1 {
+		t.Fatalf("期望消息未确认，实际: %+v", summary)
+	}
+	if err := consumer.Ack(ctx, id); err != nil {
+		t.Fatalf("Ack失败: %v", err)
+	}
+	if summary, _ := rdb.XPending(ctx, "orders", "g").Result(); summary == nil || summary.Count != 0 {
+		t.Errorf("期望Ack后待处理列表为空，实际: %+v", summary)
+	}
+}
+
+// TestConsumerDeadLetter 测试处理失败的消息在空闲超时后重新投递，超过最大投递次数后转入死信流
+func TestConsumerDeadLetter(t *testing.T) {
+	ctx := context.Background()
+	client, server := redistest.NewClient(t)
+	rdb := client.GetRawClient()
+
+	received := newCollector[string]()
+	consumer, err := redis.NewConsumer(client, "orders", "g", func(_ context.Context, msg *redis.StreamMessage[string]) error {
+		received.add(msg)
+		return errors.New("处理失败")
+	},
+		redis.ConsumerName("c1"),
+		redis.ConsumerStartID("0"),
+		redis.ConsumerBlock(50*time.Millisecond),
+		redis.ConsumerClaim(time.Minute, 20*time.Millisecond),
+		redis.ConsumerMaxDeliveries(2),
+	)
+	if err != nil {
+		t.Fatalf("创建消费者失败: %v", err)
+	}
+	id, _ := client.XAddStruct(ctx, "orders", "a")
+	runConsumer(t, consumer)
+
+	if !received.wait(1) {
+		t.Fatal("未收到首次投递")
+	}
+	// 未达到空闲时间时不会重新投递
+	time.Sleep(60 * time.Millisecond)
+	if list := received.list(); len(list) != 1 || list[0].Deliveries != 1 {
+		t.Fatalf("期望仅首次投递，实际: %d", len(list))
+	}
+
+	server.Clock().Advance(2 * time.Minute)
+	if !received.wait(2) {
+		t.Fatal("未收到重新投递")
+	}
+	if msg := received.list()[1]; msg.ID != id || msg.Deliveries != 2 {
+		t.Errorf("期望第2次投递，实际: %+v", msg)
+	}
+
+	// 第3次认领超过最大投递次数，不再调用处理函数而转入死信流
+	server.Clock().Advance(2 * time.Minute)
+	var dead []goredis.XMessage
+	deadline := time.Now().Add(3 * time.Second)
+	for len(dead) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		dead, _ = rdb.XRange(ctx, "orders:DLQ", "-", "+").Result()
+	}
+	if len(dead) != 1 {
+		t.Fatalf("期望死信流有1条消息，实际: %d", len(dead))
+	}
+	values := dead[0].Values
+	if values["payload"] != "a" || values["source_stream"] != "orders" || values["source_id"] != id ||
+		values["group"] != "g" || values["deliveries"] != "3" || values["reason"] != "超过最大投递次数" {
+		t.Errorf("死信消息字段不符: %v", values)
+	}
+	if n := len(received.list()); n != 2 {
+		t.Errorf("期望处理函数仅被调用2次，实际: %d", n)
+	}
+	if summary, _ := rdb.XPending(ctx, "orders", "g").Result(); summary == nil || summary.Count != 0 {
+		t.Errorf("期望死信消息已确认，实际: %+v", summary)
+	}
+}
+
+// TestConsumerDecodeError 测试无法反序列化的消息直接转入死信流
+func TestConsumerDecodeError(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+	rdb := client.GetRawClient()
+
+	type order struct {
+		ID int `json:"id"`
+	}
+	received := newCollector[order]()
+	consumer, err := redis.NewConsumer(client, "orders", "g", func(_ context.Context, msg *redis.StreamMessage[order]) error {
+		received.add(msg)
+		return nil
+	}, redis.ConsumerStartID("0"), redis.ConsumerBlock(50*time.Millisecond), redis.ConsumerDeadLetter("bad", 0))
+	if err != nil {
+		t.Fatalf("创建消费者失败: %v", err)
+	}
+	id, err := rdb.XAdd(ctx, &goredis.XAddArgs{Stream: "orders", Values: []string{"payload", "not json"}}).Result()
+	if err != nil {
+		t.Fatalf("XAdd失败: %v", err)
+	}
+	runConsumer(t, consumer)
+
+	var dead []goredis.XMessage
+	deadline := time.Now().Add(3 * time.Second)
+	for len(dead) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		dead, _ = rdb.XRange(ctx, "bad", "-", "+").Result()
+	}
+	if len(dead) != 1 || dead[0].Values["source_id"] != id || dead[0].Values["deliveries"] != "1" || dead[0].Values["reason"] == "" {
+		t.Fatalf("死信消息不符: %+v", dead)
+	}
+	if n := len(received.list()); n != 0 {
+		t.Errorf("期望处理函数未被调用，实际: %d", n)
+	}
+}
@@ -0,0 +1,726 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nilchaosky/go-nexus/redis"
+	"github.com/nilchaosky/go-nexus/redis/redistest"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// TestString 测试字符串读写、计数器与过期时间
+func TestString(t *testing.T) {
+	ctx := context.Background()
+	client, server := redistest.NewClient(t)
+
+	type user struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+	if err := client.SetEX(ctx, "user:1", user{Name: "Tom", Age: 18}, time.Minute); err != nil {
+		t.Fatalf("SetEX失败: %v", err)
+	}
+	var u user
+	if err := client.GetStruct(ctx, "user:1", &u); err != nil || u.Name != "Tom" || u.Age != 18 {
+		t.Fatalf("GetStruct结果不符: %+v, %v", u, err)
+	}
+
+	ok, err := client.SetNX(ctx, "user:1", "x")
+	if err != nil || ok {
+		t.Errorf("期望SetNX失败，实际: %v, %v", ok, err)
+	}
+
+	if n, _ := client.IncrBy(ctx, "counter", 5); n != 5 {
+		t.Errorf("期望计数为5，实际: %d", n)
+	}
+	if n, _ := client.Decr(ctx, "counter"); n != 4 {
+		t.Errorf("期望计数为4，实际: %d", n)
+	}
+
+	server.Clock().Advance(time.Minute)
+	if err := client.GetStruct(ctx, "user:1", &u); !redis.IsNotFound(err) {
+		t.Errorf("期望键已过期，实际: %v", err)
+	}
+	if _, err := client.Get(ctx, "missing"); !redis.IsNotFound(err) {
+		t.Errorf("期望返回ErrNotFound，实际: %v", err)
+	}
+}
+
+// TestList 测试列表操作
+func TestList(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+
+	if _, err := client.RPush(ctx, "queue", "a", "b", "c"); err != nil {
+		t.Fatalf("RPush失败: %v", err)
+	}
+	if _, err := client.LPush(ctx, "queue", "z"); err != nil {
+		t.Fatalf("LPush失败: %v", err)
+	}
+	values, _ := client.LRange(ctx, "queue", 0, -1)
+	if !slices.Equal(values, []string{"z", "a", "b", "c"}) {
+		t.Errorf("期望 [z a b c]，实际: %v", values)
+	}
+
+	if value, _ := client.RPopLPush(ctx, "queue", "done"); value != "c" {
+		t.Errorf("期望弹出c，实际: %s", value)
+	}
+	if err := client.LTrim(ctx, "queue", 1, -1); err != nil {
+		t.Fatalf("LTrim失败: %v", err)
+	}
+	if n, _ := client.LLen(ctx, "queue"); n != 2 {
+		t.Errorf("期望长度为2，实际: %d", n)
+	}
+	if _, err := client.LPop(ctx, "empty"); !redis.IsNotFound(err) {
+		t.Errorf("期望返回ErrNotFound，实际: %v", err)
+	}
+}
+
+// TestSet 测试集合操作
+func TestSet(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+
+	_, _ = client.SAdd(ctx, "a", 1, 2, 3)
+	_, _ = client.SAdd(ctx, "b", 2, 3, 4)
+
+	inter, _ := client.SInter(ctx, "a", "b")
+	if !slices.Equal(inter, []string{"2", "3"}) {
+		t.Errorf("期望交集 [2 3]，实际: %v", inter)
+	}
+	union, _ := client.SUnion(ctx, "a", "b")
+	if len(union) != 4 {
+		t.Errorf("期望并集4个成员，实际: %v", union)
+	}
+	if ok, _ := client.SIsMember(ctx, "a", 1); !ok {
+		t.Error("期望1是a的成员")
+	}
+	if n, _ := client.SDiffStore(ctx, "diff", "a", "b"); n != 1 {
+		t.Errorf("期望差集1个成员，实际: %d", n)
+	}
+}
+
+// TestHashStruct 测试结构体与哈希表的映射
+func TestHashStruct(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+
+	type profile struct {
+		Name   string `redis:"name"`
+		Age    int    `redis:"age"`
+		Active bool   `redis:"active"`
+		Note   string `redis:"note,omitempty"`
+	}
+	if _, err := client.HSetStruct(ctx, "profile:1", profile{Name: "Tom", Age: 18, Active: true}); err != nil {
+		t.Fatalf("HSetStruct失败: %v", err)
+	}
+
+	fields, _ := client.HGetAll(ctx, "profile:1")
+	if fields["age"] != "18" || fields["active"] != "1" {
+		t.Errorf("哈希字段不符: %v", fields)
+	}
+	if _, ok := fields["note"]; ok {
+		t.Error("omitempty字段不应写入")
+	}
+
+	var p profile
+	if err := client.HGetAllStruct(ctx, "profile:1", &p); err != nil || p.Name != "Tom" || !p.Active {
+		t.Fatalf("HGetAllStruct结果不符: %+v, %v", p, err)
+	}
+	if n, _ := client.HIncrBy(ctx, "profile:1", "age", 2); n != 20 {
+		t.Errorf("期望age为20，实际: %d", n)
+	}
+	if err := client.HGetAllStruct(ctx, "missing", &p); !redis.IsNotFound(err) {
+		t.Errorf("期望返回ErrNotFound，实际: %v", err)
+	}
+}
+
+// TestZSet 测试有序集合操作
+func TestZSet(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+
+	_, err := client.ZAdd(ctx, "rank",
+		goredis.Z{Score: 10, Member: "a"},
+		goredis.Z{Score: 30, Member: "b"},
+		goredis.Z{Score: 20, Member: "c"},
+	)
+	if err != nil {
+		t.Fatalf("ZAdd失败: %v", err)
+	}
+
+	top, _ := client.ZRevRange(ctx, "rank", 0, 1)
+	if !slices.Equal(top, []string{"b", "c"}) {
+		t.Errorf("期望 [b c]，实际: %v", top)
+	}
+	if rank, _ := client.ZRank(ctx, "rank", "c"); rank != 1 {
+		t.Errorf("期望排名为1，实际: %d", rank)
+	}
+	if score, _ := client.ZIncrBy(ctx, "rank", 15, "a"); score != 25 {
+		t.Errorf("期望分数为25，实际: %v", score)
+	}
+
+	members, _ := client.ZRangeByScore(ctx, "rank", &goredis.ZRangeBy{Min: "(20", Max: "+inf"})
+	if !slices.Equal(members, []string{"a", "b"}) {
+		t.Errorf("期望 [a b]，实际: %v", members)
+	}
+	if n, _ := client.ZCount(ctx, "rank", "-inf", "25"); n != 2 {
+		t.Errorf("期望2个成员，实际: %d", n)
+	}
+	if _, err := client.ZScore(ctx, "rank", "missing"); !redis.IsNotFound(err) {
+		t.Errorf("期望返回ErrNotFound，实际: %v", err)
+	}
+}
+
+// TestTx 测试乐观锁事务
+func TestTx(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+
+	_ = client.Set(ctx, "balance", 100)
+	err := client.Tx(ctx, func(tx *redis.Tx) error {
+		value, err := tx.Get(ctx, "balance")
+		if err != nil {
+			return err
+		}
+		if value != "100" {
+			t.Errorf("期望余额为100，实际: %s", value)
+		}
+		return tx.Exec(ctx, func(p *redis.Pipe) error {
+			p.Set(ctx, "balance", 80, 0)
+			return nil
+		})
+	}, "balance")
+	if err != nil {
+		t.Fatalf("Tx失败: %v", err)
+	}
+
+	if value, _ := client.Get(ctx, "balance"); value != "80" {
+		t.Errorf("期望余额为80，实际: %s", value)
+	}
+}
+
+// TestPipeHashStruct 测试管道中两组哈希结构体命令各自对称：HSetStruct/HGetStruct 与 HSetFields/HGetAllStruct
+func TestPipeHashStruct(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+
+	type profile struct {
+		Name string `json:"name" redis:"name"`
+		Age  int    `json:"age" redis:"age"`
+	}
+	err := client.Pipeline(ctx, func(p *redis.Pipe) error {
+		p.HSetStruct(ctx, "profiles", "1", profile{Name: "Tom", Age: 18})
+		p.HSetFields(ctx, "profile:2", profile{Name: "Jerry", Age: 20})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Pipeline写入失败: %v", err)
+	}
+
+	var field, missing *redis.StructCmd
+	var all *redis.HashStructCmd
+	err = client.Pipeline(ctx, func(p *redis.Pipe) error {
+		field = p.HGetStruct(ctx, "profiles", "1")
+		all = p.HGetAllStruct(ctx, "profile:2")
+		missing = p.HGetStruct(ctx, "profiles", "missing")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Pipeline读取失败: %v", err)
+	}
+
+	var p1, p2 profile
+	if err := field.Scan(&p1); err != nil || p1 != (profile{Name: "Tom", Age: 18}) {
+		t.Errorf("HGetStruct结果不符: %+v, %v", p1, err)
+	}
+	if err := all.Scan(&p2); err != nil || p2 != (profile{Name: "Jerry", Age: 20}) {
+		t.Errorf("HGetAllStruct结果不符: %+v, %v", p2, err)
+	}
+	if err := missing.Scan(&p1); !redis.IsNotFound(err) {
+		t.Errorf("期望返回ErrNotFound，实际: %v", err)
+	}
+}
+
+// TestToken 测试Token的保存、获取与删除
+func TestToken(t *testing.T) {
+	ctx := context.Background()
+	client, server := redistest.NewClient(t)
+
+	if err := client.SaveToken(ctx, "42", "access", "refresh", time.Hour, 2*time.Hour); err != nil {
+		t.Fatalf("SaveToken失败: %v", err)
+	}
+	key, _ := client.GetUserTokenKey("42")
+	if n, _ := client.GetRawClient().Exists(ctx, key+":Token").Result(); n != 1 {
+		t.Errorf("期望Token键%s存在", key+":Token")
+	}
+
+	access, refresh, err := client.GetToken(ctx, "42")
+	if err != nil || access != "access" || refresh != "refresh" {
+		t.Fatalf("GetToken结果不符: %s, %s, %v", access, refresh, err)
+	}
+
+	// Token过期后返回 ErrNotFound
+	server.Clock().Advance(time.Hour)
+	if _, _, err := client.GetToken(ctx, "42"); !redis.IsNotFound(err) {
+		t.Errorf("期望Token已过期，实际: %v", err)
+	}
+
+	if err := client.DeleteToken(ctx, "42"); err != nil {
+		t.Fatalf("DeleteToken失败: %v", err)
+	}
+	if n, _ := client.GetRawClient().Exists(ctx, key+":RefreshKey").Result(); n != 0 {
+		t.Error("期望RefreshToken已删除")
+	}
+}
+
+// TestPrefixRandomKeyAndMSet 测试带前缀视图的 RandomKey 只返回前缀下的键，MSet 的 map 参数与无前缀时编码一致
+func TestPrefixRandomKeyAndMSet(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+	app := client.WithPrefix("app:")
+
+	for i := range 50 {
+		_ = client.Set(ctx, "other:"+strconv.Itoa(i), i)
+	}
+	_ = app.Set(ctx, "x", 1)
+	for range 10 {
+		if key, err := app.RandomKey(ctx); err != nil || key != "x" {
+			t.Fatalf("期望返回前缀下的键x，实际: %s, %v", key, err)
+		}
+	}
+	if _, err := client.WithPrefix("none:").RandomKey(ctx); !redis.IsNotFound(err) {
+		t.Errorf("期望前缀下没有键时返回ErrNotFound，实际: %v", err)
+	}
+
+	type item struct {
+		N int `json:"n"`
+	}
+	if err := client.MSet(ctx, map[string]interface{}{"m:1": item{N: 1}}); err != nil {
+		t.Fatalf("MSet失败: %v", err)
+	}
+	if err := app.MSet(ctx, map[string]interface{}{"m:1": item{N: 1}}); err != nil {
+		t.Fatalf("带前缀MSet失败: %v", err)
+	}
+	plain, _ := client.GetRawClient().Get(ctx, "m:1").Result()
+	prefixed, _ := client.GetRawClient().Get(ctx, "app:m:1").Result()
+	if plain != prefixed || plain != `{"n":1}` {
+		t.Errorf("期望两种方式编码一致，实际: %q, %q", plain, prefixed)
+	}
+}
+
+// TestDeleteByPattern 测试按模式删除，只包含通配符的模式被拒绝
+func TestDeleteByPattern(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+	app := client.WithPrefix("app:")
+
+	for i := range 5 {
+		_ = client.Set(ctx, "tmp:"+strconv.Itoa(i), i)
+		_ = app.Set(ctx, "tmp:"+strconv.Itoa(i), i)
+	}
+	_ = client.Set(ctx, "keep", 1)
+
+	for _, pattern := range []string{"", "*", "**", "?*", "???"} {
+		if _, err := client.DeleteByPattern(ctx, pattern); err == nil {
+			t.Errorf("期望模式%q被拒绝", pattern)
+		}
+	}
+
+	// 带前缀的视图只删除前缀下的键
+	if n, err := app.DeleteByPattern(ctx, "*"); err != nil || n != 5 {
+		t.Fatalf("期望删除前缀下的5个键，实际: %d, %v", n, err)
+	}
+	if n, err := client.DeleteByPattern(ctx, "tmp:*"); err != nil || n != 5 {
+		t.Fatalf("期望删除5个键，实际: %d, %v", n, err)
+	}
+	if n, _ := client.Exists(ctx, "keep"); n != 1 {
+		t.Error("期望不匹配的键保留")
+	}
+}
+
+// TestTokenWithPrefix 测试带前缀视图上的Token键保留 USER: 命名空间
+func TestTokenWithPrefix(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+	app := client.WithPrefix("app:")
+
+	if err := app.SaveToken(ctx, "42", "access", "refresh", time.Hour, 2*time.Hour); err != nil {
+		t.Fatalf("SaveToken失败: %v", err)
+	}
+	if key, _ := app.GetUserTokenKey("42"); key != "app:USER:42" {
+		t.Errorf("期望Token键为app:USER:42，实际: %s", key)
+	}
+	if n, _ := client.GetRawClient().Exists(ctx, "app:USER:42:Token", "app:USER:42:RefreshKey").Result(); n != 2 {
+		t.Errorf("期望Token键位于app:USER:命名空间下，实际存在%d个", n)
+	}
+
+	access, _, err := app.GetToken(ctx, "42")
+	if err != nil || access != "access" {
+		t.Fatalf("GetToken结果不符: %s, %v", access, err)
+	}
+}
+
+// TestWithPrefix 测试键前缀视图
+func TestWithPrefix(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+	tenant := client.WithPrefix("tenant:1:")
+
+	_ = tenant.Set(ctx, "name", "a")
+	_ = tenant.Set(ctx, "age", 1)
+
+	if value, _ := client.Get(ctx, "tenant:1:name"); value != "a" {
+		t.Errorf("期望原始键带前缀，实际值: %s", value)
+	}
+	keys, _ := tenant.Keys(ctx, "*")
+	slices.Sort(keys)
+	if !slices.Equal(keys, []string{"age", "name"}) {
+		t.Errorf("期望返回去除前缀的键，实际: %v", keys)
+	}
+}
+
+// TestCodec 测试值编码的压缩、新旧格式共存与结构体哈希映射
+func TestCodec(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t, redis.CodecOption(redis.CompressionGzip, 256))
+
+	type doc struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}
+	large := doc{Title: "large", Body: strings.Repeat("redis ", 1000)}
+	if err := client.SetEX(ctx, "doc:large", large, time.Minute); err != nil {
+		t.Fatalf("SetEX失败: %v", err)
+	}
+	raw, _ := client.GetRawClient().Get(ctx, "doc:large").Bytes()
+	if len(raw) == 0 || raw[0] != 0xF5 || len(raw) >= len(large.Body) {
+		t.Errorf("期望值以gzip头部压缩存储，实际长度: %d", len(raw))
+	}
+	var d doc
+	if err := client.GetStruct(ctx, "doc:large", &d); err != nil || d != large {
+		t.Fatalf("GetStruct结果不符: %v", err)
+	}
+
+	// 小于阈值的值只添加头部
+	if err := client.SetEX(ctx, "doc:small", doc{Title: "small"}, time.Minute); err != nil {
+		t.Fatalf("SetEX失败: %v", err)
+	}
+	raw, _ = client.GetRawClient().Get(ctx, "doc:small").Bytes()
+	if len(raw) == 0 || raw[0] != 0xF4 || raw[1] != '{' {
+		t.Errorf("期望值带未压缩头部，实际: %q", raw)
+	}
+
+	// 未带头部的旧格式值仍可读取
+	_ = client.GetRawClient().Set(ctx, "doc:legacy", `{"title":"legacy"}`, 0)
+	if err := client.GetStruct(ctx, "doc:legacy", &d); err != nil || d.Title != "legacy" {
+		t.Errorf("旧格式值读取失败: %+v, %v", d, err)
+	}
+
+	type profile struct {
+		Name string `redis:"name"`
+		Doc  doc    `redis:"doc"`
+	}
+	if _, err := client.HSetStruct(ctx, "profile:1", profile{Name: "Tom", Doc: large}); err != nil {
+		t.Fatalf("HSetStruct失败: %v", err)
+	}
+	var p profile
+	if err := client.HGetAllStruct(ctx, "profile:1", &p); err != nil || p.Name != "Tom" || p.Doc != large {
+		t.Errorf("HGetAllStruct结果不符: %v", err)
+	}
+	// 嵌套结构体字段写入普通JSON，不带值编码头部也不压缩
+	field, _ := client.GetRawClient().HGet(ctx, "profile:1", "doc").Result()
+	if !strings.HasPrefix(field, `{"title":`) {
+		t.Errorf("期望嵌套字段为普通JSON，实际: %q", field)
+	}
+
+	// 旧版本带值编码头部的嵌套字段仍可读取
+	legacy, _ := client.GetRawClient().Get(ctx, "doc:small").Result()
+	_ = client.GetRawClient().HSet(ctx, "profile:2", "name", "Jerry", "doc", legacy)
+	if err := client.HGetAllStruct(ctx, "profile:2", &p); err != nil || p.Doc.Title != "small" {
+		t.Errorf("旧格式嵌套字段读取失败: %+v, %v", p, err)
+	}
+}
+
+// TestDecompressLimit 测试解压后数据超过最大长度时返回错误
+func TestDecompressLimit(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t,
+		redis.CodecOption(redis.CompressionGzip, 256),
+		redis.DecompressLimitOption(1024),
+	)
+
+	type doc struct {
+		Body string `json:"body"`
+	}
+	if err := client.SetEX(ctx, "doc:small", doc{Body: strings.Repeat("a", 512)}, time.Minute); err != nil {
+		t.Fatalf("SetEX失败: %v", err)
+	}
+	var d doc
+	if err := client.GetStruct(ctx, "doc:small", &d); err != nil || len(d.Body) != 512 {
+		t.Errorf("期望未超过限制的值正常读取，实际: %v", err)
+	}
+
+	if err := client.SetEX(ctx, "doc:large", doc{Body: strings.Repeat("a", 4096)}, time.Minute); err != nil {
+		t.Fatalf("SetEX失败: %v", err)
+	}
+	if err := client.GetStruct(ctx, "doc:large", &d); !errors.Is(err, redis.ErrDecompressTooLarge) {
+		t.Errorf("期望返回 ErrDecompressTooLarge，实际: %v", err)
+	}
+}
+
+// TestSession 测试多设备会话的数量限制、列表与撤销
+func TestSession(t *testing.T) {
+	ctx := context.Background()
+	client, server := redistest.NewClient(t, redis.SessionLimitOption("mobile", 1))
+
+	login := func(device string, at time.Time) *redis.Session {
+		session := &redis.Session{UserID: "42", Device: device, IP: "127.0.0.1", UserAgent: device + "-agent", LoginAt: at}
+		if _, err := client.CreateSession(ctx, session, "access-"+device, "refresh-"+device, time.Hour, 2*time.Hour); err != nil {
+			t.Fatalf("CreateSession失败: %v", err)
+		}
+		return session
+	}
+	now := time.Now().Truncate(time.Second)
+	phone := login("mobile", now)
+	web := login("web", now.Add(time.Second))
+
+	// 同类型设备超过限制时踢出最早的会话
+	tablet := &redis.Session{UserID: "42", Device: "mobile", LoginAt: now.Add(2 * time.Second)}
+	evicted, err := client.CreateSession(ctx, tablet, "a", "r", time.Hour, 2*time.Hour)
+	if err != nil || !slices.Equal(evicted, []string{phone.ID}) {
+		t.Fatalf("期望踢出 %s，实际: %v, %v", phone.ID, evicted, err)
+	}
+	if _, err := client.GetSession(ctx, "42", phone.ID); !redis.IsNotFound(err) {
+		t.Errorf("期望被踢出的会话不存在，实际: %v", err)
+	}
+
+	sessions, err := client.ListSessions(ctx, "42")
+	if err != nil || len(sessions) != 2 || sessions[0].ID != web.ID || sessions[1].ID != tablet.ID {
+		t.Fatalf("会话列表不符: %+v, %v", sessions, err)
+	}
+	if s := sessions[0]; s.Device != "web" || s.UserAgent != "web-agent" || !s.LoginAt.Equal(web.LoginAt) {
+		t.Errorf("会话信息不符: %+v", s)
+	}
+
+	access, refresh, err := client.GetSessionToken(ctx, "42", web.ID)
+	if err != nil || access != "access-web" || refresh != "refresh-web" {
+		t.Errorf("GetSessionToken结果不符: %s, %s, %v", access, refresh, err)
+	}
+
+	// Token过期后会话仍可刷新
+	server.Clock().Advance(time.Hour)
+	if _, _, err := client.GetSessionToken(ctx, "42", web.ID); !redis.IsNotFound(err) {
+		t.Errorf("期望Token已过期，实际: %v", err)
+	}
+	if err := client.RefreshSession(ctx, "42", web.ID, "access-2", "refresh-2", time.Hour, 2*time.Hour); err != nil {
+		t.Fatalf("RefreshSession失败: %v", err)
+	}
+
+	if err := client.RevokeSession(ctx, "42", web.ID); err != nil {
+		t.Fatalf("RevokeSession失败: %v", err)
+	}
+	if sessions, _ := client.ListSessions(ctx, "42"); len(sessions) != 1 || sessions[0].ID != tablet.ID {
+		t.Errorf("期望只剩一个会话，实际: %+v", sessions)
+	}
+
+	// 会话过期后从列表中清理
+	server.Clock().Advance(time.Hour)
+	if sessions, _ := client.ListSessions(ctx, "42"); len(sessions) != 0 {
+		t.Errorf("期望会话已过期，实际: %+v", sessions)
+	}
+
+	login("web", now)
+	if err := client.RevokeSessions(ctx, "42"); err != nil {
+		t.Fatalf("RevokeSessions失败: %v", err)
+	}
+	if keys, _ := client.GetRawClient().Keys(ctx, "USER:{42}:*").Result(); len(keys) != 0 {
+		t.Errorf("期望所有会话键已删除，实际: %v", keys)
+	}
+}
+
+// TestSessionHashTag 测试同一用户的会话键共享哈希标签，集群模式下位于同一槽位
+func TestSessionHashTag(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+	app := client.WithPrefix("app:")
+
+	for _, device := range []string{"mobile", "web"} {
+		session := &redis.Session{UserID: "42", Device: device}
+		if _, err := app.CreateSession(ctx, session, "access", "refresh", time.Hour, 2*time.Hour); err != nil {
+			t.Fatalf("CreateSession失败: %v", err)
+		}
+	}
+
+	keys, err := client.GetRawClient().Keys(ctx, "*").Result()
+	if err != nil || len(keys) != 5 {
+		t.Fatalf("期望1个索引键和4个会话键，实际: %v, %v", keys, err)
+	}
+	for _, key := range keys {
+		// 集群按第一个 {} 中的内容计算槽位
+		start := strings.IndexByte(key, '{')
+		end := strings.IndexByte(key[start+1:], '}')
+		if start < 0 || end < 0 || key[start+1:start+1+end] != "42" {
+			t.Errorf("期望键%s使用{42}哈希标签", key)
+		}
+	}
+}
+
+// TestSessionIndexTTL 测试会话索引的有效期不早于任一会话，包含永久会话时不设置过期时间
+func TestSessionIndexTTL(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+
+	create := func(id, device string, refreshExpiration time.Duration) {
+		session := &redis.Session{UserID: id, Device: device}
+		if _, err := client.CreateSession(ctx, session, "access", "refresh", time.Minute, refreshExpiration); err != nil {
+			t.Fatalf("CreateSession失败: %v", err)
+		}
+	}
+
+	// 有效期只延长不缩短
+	create("1", "web", 2*time.Hour)
+	create("1", "mobile", time.Hour)
+	if ttl, _ := client.GetRawClient().PTTL(ctx, "USER:{1}:Sessions").Result(); ttl <= time.Hour {
+		t.Errorf("期望索引有效期不短于2小时，实际: %v", ttl)
+	}
+
+	// 包含永久会话的索引不再设置过期时间
+	create("2", "web", 0)
+	create("2", "mobile", time.Hour)
+	if ttl, _ := client.GetRawClient().PTTL(ctx, "USER:{2}:Sessions").Result(); ttl != -1 {
+		t.Errorf("期望索引永久有效，实际: %v", ttl)
+	}
+}
+
+// TestCacheWaitCancel 测试等待其他调用方加载时可以通过ctx取消
+func TestCacheWaitCancel(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+
+	release := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		var value string
+		_, err := client.Cache(ctx, "slow", &value, time.Minute, func() (interface{}, error) {
+			<-release
+			return "loaded", nil
+		})
+		done <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	var value string
+	if _, err := client.Cache(waitCtx, "slow", &value, time.Minute, func() (interface{}, error) {
+		return "other", nil
+	}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("期望等待被取消，实际: %v", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Cache失败: %v", err)
+	}
+	if hit, err := client.Cache(ctx, "slow", &value, time.Minute, nil); err != nil || !hit || value != "loaded" {
+		t.Errorf("期望命中已加载的缓存，实际: %v, %v, %s", hit, err, value)
+	}
+}
+
+// TestCacheWaitLockReleased 测试其他进程释放加载锁但未写入缓存时，等待方立即自行加载
+func TestCacheWaitLockReleased(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+
+	// 模拟其他进程持有加载锁，加载失败后释放锁且未写入缓存
+	_ = client.GetRawClient().Set(ctx, "user:1:loading", "other", time.Minute)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = client.GetRawClient().Del(ctx, "user:1:loading")
+	}()
+
+	start := time.Now()
+	var value string
+	hit, err := client.Cache(ctx, "user:1", &value, time.Minute, func() (interface{}, error) {
+		return "loaded", nil
+	}, redis.CacheLock(time.Minute))
+	if err != nil || hit || value != "loaded" {
+		t.Fatalf("期望自行加载，实际: %v, %v, %s", hit, err, value)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("期望加载锁释放后立即加载，实际等待: %v", elapsed)
+	}
+}
+
+// TestPipeMarshalError 测试管道排队阶段的序列化错误只包装一次
+func TestPipeMarshalError(t *testing.T) {
+	ctx := context.Background()
+	client, _ := redistest.NewClient(t)
+
+	err := client.Pipeline(ctx, func(p *redis.Pipe) error {
+		p.Set(ctx, "bad", map[string]interface{}{"ch": make(chan int)}, 0)
+		return nil
+	})
+	if err == nil || strings.Count(err.Error(), "序列化失败") != 1 {
+		t.Errorf("期望只包含一次\"序列化失败\"，实际: %v", err)
+	}
+	if n, _ := client.Exists(ctx, "bad"); n != 0 {
+		t.Error("序列化失败时管道不应执行")
+	}
+}
+
+// receiveOne 订阅频道并返回收到的第一条消息
+func receiveOne[T any](t *testing.T, client *redis.Client, channel string, publish interface{}) T {
+	t.Helper()
+	ctx := context.Background()
+
+	received := make(chan T, 1)
+	sub, err := redis.Subscribe(ctx, client, []string{channel}, func(_ context.Context, msg *redis.Message[T]) error {
+		received <- msg.Payload
+		return nil
+	}, redis.SubscribeWorkers(1))
+	if err != nil {
+		t.Fatalf("Subscribe失败: %v", err)
+	}
+	defer sub.Close()
+
+	if n, err := client.Publish(ctx, channel, publish); err != nil || n != 1 {
+		t.Fatalf("Publish失败: %d, %v", n, err)
+	}
+	select {
+	case payload := <-received:
+		return payload
+	case <-time.After(3 * time.Second):
+		t.Fatalf("频道 %s 未收到消息", channel)
+	}
+	var zero T
+	return zero
+}
+
+// TestPubSubTyped 测试布尔值和时间经 Publish 发布后由 Subscribe[T] 正确解码
+func TestPubSubTyped(t *testing.T) {
+	client, _ := redistest.NewClient(t)
+
+	if got := receiveOne[bool](t, client, "flag", true); !got {
+		t.Errorf("期望收到true，实际: %v", got)
+	}
+
+	now := time.Date(2026, 10, 17, 8, 30, 0, 0, time.UTC)
+	if got := receiveOne[time.Time](t, client, "time", now); !got.Equal(now) {
+		t.Errorf("期望收到 %v，实际: %v", now, got)
+	}
+
+	type event struct {
+		Name string `json:"name"`
+	}
+	if got := receiveOne[event](t, client, "event", event{Name: "login"}); got.Name != "login" {
+		t.Errorf("期望收到login事件，实际: %+v", got)
+	}
+}
@@ -10,6 +10,7 @@ import (
 )
 
 var (
+	// userTokenRedisKeyPrefix Token键前缀，客户端视图设置了前缀（WithPrefix）时叠加在视图前缀之后
 	userTokenRedisKeyPrefix = "USER:"
 	tokenRedisKey           = "Token"
 	refreshTokenRedisKey    = "RefreshKey"
@@ -18,80 +19,97 @@ var (
 // Token Token操作接口
 type Token interface {
 	GetUserTokenKey(id string) (string, error)
-	GetToken(ctx context.Context, id string) (string, string)
+	GetToken(ctx context.Context, id string) (string, string, error)
 	SaveToken(ctx context.Context, id, tokenValue, refreshTokenValue string, expiration, refreshExpiration time.Duration) error
 	DeleteToken(ctx context.Context, id string) error
 	VerifyRefreshToken(ctx context.Context, id, oldToken, oldRefreshToken, secret string) error
+	CreateSession(ctx context.Context, session *Session, tokenValue, refreshTokenValue string, expiration, refreshExpiration time.Duration) ([]string, error)
+	GetSession(ctx context.Context, id, sessionID string) (*Session, error)
+	GetSessionToken(ctx context.Context, id, sessionID string) (string, string, error)
+	RefreshSession(ctx context.Context, id, sessionID, tokenValue, refreshTokenValue string, expiration, refreshExpiration time.Duration) error
+	ListSessions(ctx context.Context, id string) ([]Session, error)
+	RevokeSession(ctx context.Context, id, sessionID string) error
+	RevokeSessions(ctx context.Context, id string) error
+	VerifySessionRefreshToken(ctx context.Context, id, sessionID, oldToken, oldRefreshToken, secret string) error
 }
 
-// GetUserTokenKey 获取用户Token Key
+// GetUserTokenKey 获取用户Token Key（包含前缀的完整键名）
 func (c *Client) GetUserTokenKey(id string) (string, error) {
 	if id == "" {
 		return "", errors.New("id不能为空")
 	}
-	return userTokenRedisKeyPrefix + id, nil
+	return c.tokenClient().key(id), nil
+}
+
+// tokenClient 获取Token操作使用的客户端视图
+func (c *Client) tokenClient() *Client {
+	return c.WithPrefix(userTokenRedisKeyPrefix)
+}
+
+// tokenKeys 获取用户Token和RefreshToken的键（不含前缀）
+func tokenKeys(id string) (string, string, error) {
+	if id == "" {
+		return "", "", errors.New("id不能为空")
+	}
+	return id + ":" + tokenRedisKey, id + ":" + refreshTokenRedisKey, nil
 }
 
 // GetToken 获取Token和RefreshToken
-func (c *Client) GetToken(ctx context.Context, id string) (string, string) {
+// Token不存在时返回 ErrNotFound，可通过 IsNotFound 与Redis异常区分
+func (c *Client) GetToken(ctx context.Context, id string) (string, string, error) {
 	// 获取用户Token Key
-	key, err := c.GetUserTokenKey(id)
+	tokenKey, refreshTokenKey, err := tokenKeys(id)
 	if err != nil {
-		return "", ""
+		return "", "", err
 	}
-	tokenKey := key + ":" + tokenRedisKey
-	refreshTokenKey := key + ":" + refreshTokenRedisKey
+	tc := c.tokenClient()
 
 	// 获取Token
-	tokenValue, err := c.Get(ctx, tokenKey)
+	tokenValue, err := tc.Get(ctx, tokenKey)
 	if err != nil {
-		return "", ""
+		return "", "", fmt.Errorf("获取Token失败: %w", err)
 	}
 
 	// 获取RefreshToken
-	refreshTokenValue, err := c.Get(ctx, refreshTokenKey)
+	refreshTokenValue, err := tc.Get(ctx, refreshTokenKey)
 	if err != nil {
-		return "", ""
+		return "", "", fmt.Errorf("获取RefreshToken失败: %w", err)
 	}
 
-	return tokenValue, refreshTokenValue
+	return tokenValue, refreshTokenValue, nil
 }
 
 // SaveToken 保存Token
 func (c *Client) SaveToken(ctx context.Context, id, tokenValue, refreshTokenValue string, expiration, refreshExpiration time.Duration) error {
 	// 获取用户Token Key
-	key, err := c.GetUserTokenKey(id)
+	tokenKey, refreshTokenKey, err := tokenKeys(id)
 	if err != nil {
 		return err
 	}
-	tokenKey := key + ":" + tokenRedisKey
-	refreshTokenKey := key + ":" + refreshTokenRedisKey
 
-	// 保存Token和RefreshToken，使用不同的过期时间
-	if err := c.SetEX(ctx, tokenKey, tokenValue, expiration); err != nil {
+	// 在同一事务中保存Token和RefreshToken，使用不同的过期时间
+	err = c.tokenClient().TxPipeline(ctx, func(p *Pipe) error {
+		p.Set(ctx, tokenKey, tokenValue, expiration)
+		p.Set(ctx, refreshTokenKey, refreshTokenValue, refreshExpiration)
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("保存Token失败: %w", err)
 	}
-	if err := c.SetEX(ctx, refreshTokenKey, refreshTokenValue, refreshExpiration); err != nil {
-		return fmt.Errorf("保存RefreshToken失败: %w", err)
-	}
 
 	return nil
 }
 
 // DeleteToken 删除Token
 func (c *Client) DeleteToken(ctx context.Context, id string) error {
-	// 获取用户Token Key
-	key, err := c.GetUserTokenKey(id)
+	// 获取用户Token和RefreshToken的key
+	tokenKey, refreshTokenKey, err := tokenKeys(id)
 	if err != nil {
 		return err
 	}
 
-	// 构建Token和RefreshToken的key
-	tokenKey := key + ":" + tokenRedisKey
-	refreshTokenKey := key + ":" + refreshTokenRedisKey
-
 	// 删除Token和RefreshToken
-	_, err = c.Del(ctx, tokenKey, refreshTokenKey)
+	_, err = c.tokenClient().Del(ctx, tokenKey, refreshTokenKey)
 	if err != nil {
 		return fmt.Errorf("删除Token失败: %w", err)
 	}
@@ -101,6 +119,29 @@ func (c *Client) DeleteToken(ctx context.Context, id string) error {
 
 // VerifyRefreshToken 验证刷新Token
 func (c *Client) VerifyRefreshToken(ctx context.Context, id, oldToken, oldRefreshToken, secret string) error {
+	if err := verifyTokenOwner(id, oldToken, oldRefreshToken, secret); err != nil {
+		return err
+	}
+
+	// 获取Token和RefreshToken
+	_, refreshTokenValue, err := c.GetToken(ctx, id)
+	if IsNotFound(err) {
+		return errors.New("刷新Token无效")
+	}
+	if err != nil {
+		return err
+	}
+
+	// 验证刷新Token是否一致
+	if refreshTokenValue != oldRefreshToken {
+		return errors.New("刷新Token无效")
+	}
+
+	return nil
+}
+
+// verifyTokenOwner 验证旧Token的签名及其中的用户id
+func verifyTokenOwner(id, oldToken, oldRefreshToken, secret string) error {
 	// 验证oldToken和oldRefreshToken是否为空
 	if oldToken == "" || oldRefreshToken == "" {
 		return errors.New("token丢失")
@@ -122,13 +163,5 @@ func (c *Client) VerifyRefreshToken(ctx context.Context, id, oldToken, oldRefres
 		return fmt.Errorf("刷新Token验证失败: %w", err)
 	}
 
-	// 获取Token和RefreshToken
-	_, refreshTokenValue := c.GetToken(ctx, id)
-
-	// 验证刷新Token是否一致
-	if refreshTokenValue != oldRefreshToken {
-		return errors.New("刷新Token无效")
-	}
-
 	return nil
 }
@@ -0,0 +1,204 @@
+package redis
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/nilchaosky/go-nexus/serialize"
+)
+
+// Compression 值压缩算法
+type Compression byte
+
+const (
+	// CompressionNone 不压缩
+	CompressionNone Compression = iota
+	// CompressionGzip gzip压缩
+	CompressionGzip
+)
+
+// 值编码头部（1字节）：高4位固定为 1111 作为标记，bit2-3 为序列化格式，bit0-1 为压缩算法
+// JSON 以 ASCII 字符开头，不会与标记冲突；未带头部的旧格式值按客户端序列化器直接反序列化
+const (
+	headerMagic     byte = 0xF0
+	headerMagicMask byte = 0xF0
+	formatMask      byte = 0x0C
+	compressionMask byte = 0x03
+)
+
+// 头部中的序列化格式
+const (
+	formatDefault  byte = 0 << 2 // 客户端序列化器（自定义序列化器）
+	formatJSON     byte = 1 << 2 // JSON（json、jsoniter 通用）
+	formatProtobuf byte = 2 << 2 // Protobuf
+)
+
+// defaultDecompressLimit 默认解压后数据的最大长度（字节）
+const defaultDecompressLimit = 64 << 20
+
+// ErrDecompressTooLarge 解压后的数据超过最大长度
+var ErrDecompressTooLarge = errors.New("解压后数据超过最大长度")
+
+// valueCodec 值编码配置
+type valueCodec struct {
+	compression Compression // 压缩算法
+	threshold   int         // 压缩阈值（字节），序列化结果达到该长度时压缩
+}
+
+// CodecOption 启用值编码：序列化结果带1字节头部标记序列化格式与压缩算法，
+// 长度达到 threshold 字节时使用 compression 压缩（threshold<=0 或 CompressionNone 时只添加头部不压缩）
+// 读取时自动识别头部，新旧格式的值可以共存
+func CodecOption(compression Compression, threshold int) ClientOption {
+	return func(c *Client) {
+		c.codec = &valueCodec{compression: compression, threshold: threshold}
+	}
+}
+
+// DecompressLimitOption 设置解压后数据的最大长度（字节，默认：64MB），超过时读取返回 ErrDecompressTooLarge
+// 防止损坏或恶意构造的压缩值在解压时耗尽内存
+func DecompressLimitOption(n int64) ClientOption {
+	return func(c *Client) {
+		if n > 0 {
+			c.decompressLimit = n
+		}
+	}
+}
+
+// gzipWriters gzip压缩器复用池
+var gzipWriters = sync.Pool{
+	New: func() interface{} { return gzip.NewWriter(nil) },
+}
+
+// marshal 使用客户端序列化器编码，启用值编码时添加头部并按阈值压缩
+func (c *Client) marshal(value interface{}) ([]byte, error) {
+	data, err := c.serializer.Marshal(value)
+	if err != nil || c.codec == nil {
+		return data, err
+	}
+	return c.codec.encode(formatOf(c.serializer), data)
+}
+
+// unmarshal 反序列化，自动识别值编码头部
+// 头部校验或反序列化失败时按旧格式重试（旧格式 Protobuf 值的首字节可能与标记相同）
+func (c *Client) unmarshal(data []byte, value interface{}) error {
+	if len(data) == 0 || data[0]&headerMagicMask != headerMagic {
+		return c.serializer.Unmarshal(data, value)
+	}
+
+	serializer, payload, err := c.unwrap(data)
+	if err == nil {
+		err = serializer.Unmarshal(payload, value)
+	}
+	if err != nil && c.serializer.Unmarshal(data, value) == nil {
+		return nil
+	}
+	return err
+}
+
+// unwrap 解析头部，返回对应的序列化器与解压后的数据
+func (c *Client) unwrap(data []byte) (serialize.Serializer, []byte, error) {
+	header, payload := data[0], data[1:]
+
+	var serializer serialize.Serializer
+	switch header & formatMask {
+	case formatDefault:
+		serializer = c.serializer
+	case formatJSON:
+		serializer = serialize.JSONIter
+		if formatOf(c.serializer) == formatJSON {
+			serializer = c.serializer
+		}
+	case formatProtobuf:
+		serializer = serialize.Protobuf
+	default:
+		return nil, nil, fmt.Errorf("不支持的序列化格式: %#x", header&formatMask)
+	}
+
+	switch Compression(header & compressionMask) {
+	case CompressionNone:
+		return serializer, payload, nil
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, nil, err
+		}
+		defer r.Close()
+		payload, err = io.ReadAll(io.LimitReader(r, c.decompressLimit+1))
+		if err != nil {
+			return nil, nil, err
+		}
+		if int64(len(payload)) > c.decompressLimit {
+			return nil, nil, fmt.Errorf("%w: %d字节", ErrDecompressTooLarge, c.decompressLimit)
+		}
+		return serializer, payload, nil
+	default:
+		return nil, nil, fmt.Errorf("不支持的压缩算法: %d", header&compressionMask)
+	}
+}
+
+// encode 添加头部，长度达到阈值时压缩；压缩后未变小则保留原始数据
+func (vc *valueCodec) encode(format byte, data []byte) ([]byte, error) {
+	if vc.compression != CompressionNone && vc.threshold > 0 && len(data) >= vc.threshold {
+		compressed, err := vc.compress(format, data)
+		if err != nil {
+			return nil, err
+		}
+		if len(compressed) < len(data)+1 {
+			return compressed, nil
+		}
+	}
+
+	buf := make([]byte, 0, len(data)+1)
+	buf = append(buf, headerMagic|format|byte(CompressionNone))
+	return append(buf, data...), nil
+}
+
+// compress 按配置的压缩算法压缩，结果包含头部
+func (vc *valueCodec) compress(format byte, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(headerMagic | format | byte(vc.compression))
+
+	switch vc.compression {
+	case CompressionGzip:
+		w := gzipWriters.Get().(*gzip.Writer)
+		defer gzipWriters.Put(w)
+		w.Reset(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("不支持的压缩算法: %d", vc.compression)
+	}
+	return buf.Bytes(), nil
+}
+
+// formatOf 返回序列化器对应的头部格式，自定义序列化器使用 formatDefault
+func formatOf(s serialize.Serializer) byte {
+	switch s {
+	case serialize.JSON, serialize.JSONIter:
+		return formatJSON
+	case serialize.Protobuf:
+		return formatProtobuf
+	default:
+		return formatDefault
+	}
+}
+
+// parseCompression 解析配置中的压缩算法名称
+func parseCompression(name string) (Compression, error) {
+	switch name {
+	case "", "none":
+		return CompressionNone, nil
+	case "gzip":
+		return CompressionGzip, nil
+	default:
+		return CompressionNone, fmt.Errorf("不支持的压缩算法: %s", name)
+	}
+}
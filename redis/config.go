@@ -1,9 +1,236 @@
 package redis
 
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/nilchaosky/go-nexus/serialize"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// ModeStandalone 单机模式
+	ModeStandalone = "standalone"
+	// ModeSentinel 哨兵模式
+	ModeSentinel = "sentinel"
+	// ModeCluster 集群模式
+	ModeCluster = "cluster"
+)
+
 // Config 配置结构体
 type Config struct {
-	Address  string `json:"address" mapstructure:"address" yaml:"address"`    // 地址（默认：localhost）
-	Port     int    `json:"port" mapstructure:"port" yaml:"port"`             // 端口（默认：6379）
-	Password string `json:"password" mapstructure:"password" yaml:"password"` // 密码（默认：空）
-	DB       []int  `json:"db" mapstructure:"db" yaml:"db"`                   // 数据库编号列表（默认：[0]）
+	Mode              string         `json:"mode" mapstructure:"mode" yaml:"mode"`                                           // 部署模式：standalone, sentinel, cluster（默认：standalone）
+	Address           string         `json:"address" mapstructure:"address" yaml:"address"`                                  // 地址（默认：localhost）
+	Port              int            `json:"port" mapstructure:"port" yaml:"port"`                                           // 端口（默认：6379）
+	Password          string         `json:"password" mapstructure:"password" yaml:"password"`                               // 密码（默认：空）
+	DB                []int          `json:"db" mapstructure:"db" yaml:"db"`                                                 // 数据库编号列表（默认：[0]）
+	MasterName        string         `json:"master_name" mapstructure:"master_name" yaml:"master_name"`                      // 哨兵模式主节点名称
+	SentinelAddrs     []string       `json:"sentinel_addrs" mapstructure:"sentinel_addrs" yaml:"sentinel_addrs"`             // 哨兵节点地址列表（host:port）
+	SentinelPassword  string         `json:"sentinel_password" mapstructure:"sentinel_password" yaml:"sentinel_password"`    // 哨兵节点密码（默认：空）
+	ClusterAddrs      []string       `json:"cluster_addrs" mapstructure:"cluster_addrs" yaml:"cluster_addrs"`                // 集群种子节点地址列表（host:port）
+	Username          string         `json:"username" mapstructure:"username" yaml:"username"`                               // ACL用户名（默认：空，使用default用户）
+	PoolSize          int            `json:"pool_size" mapstructure:"pool_size" yaml:"pool_size"`                            // 连接池大小（默认：10*CPU核数）
+	MinIdleConns      int            `json:"min_idle_conns" mapstructure:"min_idle_conns" yaml:"min_idle_conns"`             // 最小空闲连接数（默认：0）
+	MaxIdleConns      int            `json:"max_idle_conns" mapstructure:"max_idle_conns" yaml:"max_idle_conns"`             // 最大空闲连接数（默认：0，不限制）
+	ConnMaxIdleTime   int            `json:"conn_max_idle_time" mapstructure:"conn_max_idle_time" yaml:"conn_max_idle_time"` // 空闲连接最大存活时间（秒，默认：30分钟）
+	PoolTimeout       int            `json:"pool_timeout" mapstructure:"pool_timeout" yaml:"pool_timeout"`                   // 获取连接等待超时（毫秒，默认：读超时+1秒）
+	DialTimeout       int            `json:"dial_timeout" mapstructure:"dial_timeout" yaml:"dial_timeout"`                   // 建立连接超时（毫秒，默认：5000）
+	ReadTimeout       int            `json:"read_timeout" mapstructure:"read_timeout" yaml:"read_timeout"`                   // 读超时（毫秒，默认：3000）
+	WriteTimeout      int            `json:"write_timeout" mapstructure:"write_timeout" yaml:"write_timeout"`                // 写超时（毫秒，默认：与读超时相同）
+	TLS               TLS            `json:"tls" mapstructure:"tls" yaml:"tls"`                                              // TLS配置
+	Serializer        string         `json:"serializer" mapstructure:"serializer" yaml:"serializer"`                         // 结构体序列化器：json, jsoniter, protobuf（默认：jsoniter）
+	Compression       string         `json:"compression" mapstructure:"compression" yaml:"compression"`                      // 值压缩算法：none, gzip（默认：空，不启用值编码）
+	CompressThreshold int            `json:"compress_threshold" mapstructure:"compress_threshold" yaml:"compress_threshold"` // 压缩阈值（字节，默认：1024）
+	DecompressLimit   int64          `json:"decompress_limit" mapstructure:"decompress_limit" yaml:"decompress_limit"`       // 解压后数据的最大长度（字节，默认：64MB）
+	SlowThreshold     int            `json:"slow_threshold" mapstructure:"slow_threshold" yaml:"slow_threshold"`             // 慢命令日志阈值（毫秒，默认：0，不记录）
+	RedactKeys        []string       `json:"redact_keys" mapstructure:"redact_keys" yaml:"redact_keys"`                      // 慢命令日志键名脱敏模式（如 USER:*:Token，匹配的键名以模式代替）
+	SessionLimits     map[string]int `json:"session_limits" mapstructure:"session_limits" yaml:"session_limits"`             // 每种设备类型允许同时在线的会话数量（如 mobile: 1，默认：不限制）
+}
+
+// TLS TLS配置结构体
+type TLS struct {
+	Enabled            bool   `json:"enabled" mapstructure:"enabled" yaml:"enabled"`                                        // 是否启用TLS（默认：false）
+	CAFile             string `json:"ca_file" mapstructure:"ca_file" yaml:"ca_file"`                                        // 自定义CA证书文件路径（默认：使用系统根证书）
+	CertFile           string `json:"cert_file" mapstructure:"cert_file" yaml:"cert_file"`                                  // 客户端证书文件路径（双向认证时使用）
+	KeyFile            string `json:"key_file" mapstructure:"key_file" yaml:"key_file"`                                     // 客户端私钥文件路径（双向认证时使用）
+	ServerName         string `json:"server_name" mapstructure:"server_name" yaml:"server_name"`                            // 证书校验使用的服务器名称（默认：单机模式使用Address）
+	InsecureSkipVerify bool   `json:"insecure_skip_verify" mapstructure:"insecure_skip_verify" yaml:"insecure_skip_verify"` // 是否跳过证书校验（默认：false，仅用于测试）
+}
+
+// config 构建 tls.Config，未启用时返回nil
+func (t *TLS) config() (*tls.Config, error) {
+	if !t.Enabled {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+
+	// 加载自定义CA证书
+	if t.CAFile != "" {
+		ca, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取CA证书失败: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, errors.New("CA证书格式错误")
+		}
+		config.RootCAs = pool
+	}
+
+	// 加载客户端证书
+	if t.CertFile != "" || t.KeyFile != "" {
+		if t.CertFile == "" || t.KeyFile == "" {
+			return nil, errors.New("客户端证书和私钥必须同时配置")
+		}
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+// mode 返回部署模式，为空时使用单机模式
+func (c *Config) mode() string {
+	if c.Mode == "" {
+		return ModeStandalone
+	}
+	return c.Mode
+}
+
+// validate 验证配置
+func (c *Config) validate() error {
+	switch c.mode() {
+	case ModeStandalone:
+		if c.Address == "" {
+			return errors.New("地址不能为空")
+		}
+		if c.Port == 0 {
+			return errors.New("端口不能为空")
+		}
+	case ModeSentinel:
+		if c.MasterName == "" {
+			return errors.New("哨兵模式主节点名称不能为空")
+		}
+		if len(c.SentinelAddrs) == 0 {
+			return errors.New("哨兵节点地址不能为空")
+		}
+	case ModeCluster:
+		if len(c.ClusterAddrs) == 0 {
+			return errors.New("集群节点地址不能为空")
+		}
+		// 集群模式只有数据库0，SELECT命令不可用
+		if len(c.DB) > 1 || (len(c.DB) == 1 && c.DB[0] != 0) {
+			return errors.New("集群模式仅支持数据库0，不能配置多数据库列表")
+		}
+	default:
+		return fmt.Errorf("不支持的部署模式: %s", c.Mode)
+	}
+	if _, err := c.serializer(); err != nil {
+		return err
+	}
+	if c.PoolSize < 0 || c.MinIdleConns < 0 || c.MaxIdleConns < 0 {
+		return errors.New("连接池参数不能为负数")
+	}
+	if _, err := parseCompression(c.Compression); err != nil {
+		return err
+	}
+	if c.CompressThreshold < 0 {
+		return errors.New("压缩阈值不能为负数")
+	}
+	if c.DecompressLimit < 0 {
+		return errors.New("解压长度限制不能为负数")
+	}
+	for device, limit := range c.SessionLimits {
+		if limit < 0 {
+			return fmt.Errorf("设备类型 %s 的会话数量限制不能为负数", device)
+		}
+	}
+	if c.SlowThreshold < 0 {
+		return errors.New("慢命令阈值不能为负数")
+	}
+	return nil
+}
+
+// serializer 根据配置返回序列化器
+func (c *Config) serializer() (serialize.Serializer, error) {
+	switch c.Serializer {
+	case "", "jsoniter":
+		return serialize.JSONIter, nil
+	case "json":
+		return serialize.JSON, nil
+	case "protobuf":
+		return serialize.Protobuf, nil
+	default:
+		return nil, fmt.Errorf("不支持的序列化器: %s", c.Serializer)
+	}
+}
+
+// codecOption 根据配置返回值编码选项，未配置压缩算法时返回nil
+func (c *Config) codecOption() (ClientOption, error) {
+	if c.Compression == "" {
+		return nil, nil
+	}
+	compression, err := parseCompression(c.Compression)
+	if err != nil {
+		return nil, err
+	}
+	threshold := c.CompressThreshold
+	if threshold == 0 {
+		threshold = 1024
+	}
+	return CodecOption(compression, threshold), nil
+}
+
+// options 根据部署模式构建指定数据库的客户端选项
+func (c *Config) options(db int) (*redis.UniversalOptions, error) {
+	tlsConfig, err := c.TLS.config()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &redis.UniversalOptions{
+		Username:        c.Username,
+		Password:        c.Password,
+		DB:              db,
+		PoolSize:        c.PoolSize,
+		MinIdleConns:    c.MinIdleConns,
+		MaxIdleConns:    c.MaxIdleConns,
+		ConnMaxIdleTime: time.Duration(c.ConnMaxIdleTime) * time.Second,
+		PoolTimeout:     time.Duration(c.PoolTimeout) * time.Millisecond,
+		DialTimeout:     time.Duration(c.DialTimeout) * time.Millisecond,
+		ReadTimeout:     time.Duration(c.ReadTimeout) * time.Millisecond,
+		WriteTimeout:    time.Duration(c.WriteTimeout) * time.Millisecond,
+		TLSConfig:       tlsConfig,
+	}
+
+	switch c.mode() {
+	case ModeSentinel:
+		opts.Addrs = c.SentinelAddrs
+		opts.MasterName = c.MasterName
+		opts.SentinelPassword = c.SentinelPassword
+	case ModeCluster:
+		opts.Addrs = c.ClusterAddrs
+		opts.DB = 0
+		opts.IsClusterMode = true
+	default:
+		opts.Addrs = []string{fmt.Sprintf("%s:%d", c.Address, c.Port)}
+		// go-redis 不会根据地址推断证书校验的服务器名称
+		if opts.TLSConfig != nil && opts.TLSConfig.ServerName == "" {
+			opts.TLSConfig.ServerName = c.Address
+		}
+	}
+
+	return opts, nil
 }
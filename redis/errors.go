@@ -0,0 +1,47 @@
+package redis
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrNotFound 数据不存在（键不存在、列表为空、阻塞弹出超时等）
+	// 由 redis.Nil 转换的错误同时满足 errors.Is(err, redis.Nil)
+	ErrNotFound = errors.New("数据不存在")
+	// ErrEmptyValue 键值为空字符串，无法反序列化
+	ErrEmptyValue = errors.New("键值为空")
+	// ErrDecode 反序列化失败
+	ErrDecode = errors.New("反序列化失败")
+)
+
+// IsNotFound 判断错误是否表示数据不存在
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, redis.Nil)
+}
+
+// wrapError 将 redis.Nil 转换为 ErrNotFound，其他错误原样返回
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, redis.Nil) {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	return err
+}
+
+// wrapResult 转换命令结果中的错误，见 wrapError
+func wrapResult[T any](value T, err error) (T, error) {
+	return value, wrapError(err)
+}
+
+// decodeError 将反序列化错误包装为 ErrDecode
+func decodeError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrDecode, err)
+}
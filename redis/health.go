@@ -0,0 +1,213 @@
+package redis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nilchaosky/go-nexus/logz"
+	"go.uber.org/zap"
+)
+
+// InstanceHealth 实例健康状态
+type InstanceHealth struct {
+	Name      string        // 实例名称
+	DB        int           // 数据库编号
+	Healthy   bool          // 是否可用
+	Err       error         // 最近一次检查的错误（可用时为nil）
+	Latency   time.Duration // 最近一次检查的耗时
+	Failures  int           // 连续失败次数
+	CheckedAt time.Time     // 最近一次检查时间
+	Since     time.Time     // 进入当前状态的时间
+}
+
+// HealthOption 健康检查选项
+type HealthOption func(*healthOptions)
+
+// healthOptions 健康检查选项
+type healthOptions struct {
+	interval time.Duration          // 检查间隔
+	timeout  time.Duration          // 单个实例的检查超时
+	onChange []func(InstanceHealth) // 状态变化回调
+}
+
+// HealthInterval 设置 RunHealthCheck 的检查间隔（默认：10秒）
+func HealthInterval(d time.Duration) HealthOption {
+	return func(o *healthOptions) {
+		if d > 0 {
+			o.interval = d
+		}
+	}
+}
+
+// HealthTimeout 设置单个实例 PING 或重新连接的超时（默认：3秒）
+func HealthTimeout(d time.Duration) HealthOption {
+	return func(o *healthOptions) {
+		if d > 0 {
+			o.timeout = d
+		}
+	}
+}
+
+// HealthOnChange 添加状态变化回调，实例在可用与不可用之间切换时调用
+// 回调在检查协程中同步执行，耗时操作应自行异步处理
+func HealthOnChange(fn func(InstanceHealth)) HealthOption {
+	return func(o *healthOptions) {
+		if fn != nil {
+			o.onChange = append(o.onChange, fn)
+		}
+	}
+}
+
+// newHealthOptions 创建健康检查选项
+func newHealthOptions(opts []HealthOption) *healthOptions {
+	o := &healthOptions{
+		interval: 10 * time.Second,
+		timeout:  3 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Health 获取所有已注册实例的健康状态快照（实例名称 -> 状态）
+func Health() map[string]InstanceHealth {
+	instancesMu.RLock()
+	defer instancesMu.RUnlock()
+
+	health := make(map[string]InstanceHealth, len(instances))
+	for name, inst := range instances {
+		health[name] = inst.health
+	}
+	return health
+}
+
+// RunHealthCheck 后台周期性检查所有已注册实例，阻塞直到 ctx 取消
+// 已连接的实例发送 PING；连接失败或被跳过的实例重新建立连接，成功后 Get、GetClient 即可获取
+// 通常以协程方式启动：go redis.RunHealthCheck(ctx, redis.HealthOnChange(fn))
+func RunHealthCheck(ctx context.Context, opts ...HealthOption) error {
+	o := newHealthOptions(opts)
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+
+	for {
+		checkHealth(ctx, o)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckHealth 立即检查一次所有已注册实例，返回检查后的健康状态快照
+func CheckHealth(ctx context.Context, opts ...HealthOption) map[string]InstanceHealth {
+	checkHealth(ctx, newHealthOptions(opts))
+	return Health()
+}
+
+// checkHealth 并发检查所有实例，状态变化时记录日志并调用回调
+func checkHealth(ctx context.Context, o *healthOptions) {
+	instancesMu.RLock()
+	list := make([]*instance, 0, len(instances))
+	for _, inst := range instances {
+		list = append(list, inst)
+	}
+	instancesMu.RUnlock()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		changes []InstanceHealth
+	)
+	for _, inst := range list {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if health, changed := checkInstance(ctx, inst, o.timeout); changed {
+				mu.Lock()
+				changes = append(changes, health)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, health := range changes {
+		if health.Healthy {
+			logz.Logger.Info("Redis实例已恢复",
+				zap.String("instance", health.Name),
+				zap.Int("db", health.DB),
+			)
+		} else {
+			logz.Logger.Warn("Redis实例不可用",
+				zap.String("instance", health.Name),
+				zap.Int("db", health.DB),
+				zap.Error(health.Err),
+			)
+		}
+		for _, fn := range o.onChange {
+			fn(health)
+		}
+	}
+}
+
+// checkInstance 检查单个实例，未连接时重新建立连接，返回最新状态及状态是否变化
+func checkInstance(ctx context.Context, inst *instance, timeout time.Duration) (InstanceHealth, bool) {
+	instancesMu.RLock()
+	client := inst.client
+	instancesMu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	var reconnected *Client
+	var err error
+	if client == nil {
+		reconnected, err = connect(ctx, inst.name, inst.config, inst.db)
+	} else {
+		err = client.Ping(ctx)
+	}
+	now := time.Now()
+
+	instancesMu.Lock()
+	defer instancesMu.Unlock()
+
+	// 检查期间实例被注销或替换，或并发的检查已重连成功，丢弃结果
+	if instances[inst.name] != inst || inst.client != client {
+		if reconnected != nil {
+			_ = reconnected.Close()
+		}
+		return InstanceHealth{}, false
+	}
+
+	if reconnected != nil {
+		inst.client, inst.err = reconnected, nil
+		if inst.indexed {
+			setIndexedClient(inst.db, nil, reconnected)
+		}
+	} else if client == nil {
+		inst.err = err
+	}
+
+	health := inst.health
+	changed := health.Healthy != (err == nil)
+	health.Healthy = err == nil
+	health.Err = err
+	health.Latency = now.Sub(start)
+	health.CheckedAt = now
+	if changed {
+		health.Since = now
+	}
+	if err != nil {
+		health.Failures++
+	} else {
+		health.Failures = 0
+	}
+	inst.health = health
+
+	return health, changed
+}
@@ -0,0 +1,129 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nilchaosky/go-nexus/redis"
+	"github.com/nilchaosky/go-nexus/redis/redistest"
+)
+
+// unusedAddr 获取一个当前未被监听的本地地址
+func unusedAddr(t *testing.T) (string, int) {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("获取本地端口失败: %v", err)
+	}
+	addr := l.Addr().(*net.TCPAddr)
+	_ = l.Close()
+	return addr.IP.String(), addr.Port
+}
+
+// TestHealthReconnect 测试健康检查重新连接启动时不可用的实例
+func TestHealthReconnect(t *testing.T) {
+	ctx := context.Background()
+	host, port := unusedAddr(t)
+	config := redis.Config{Address: host, Port: port, DB: []int{7}, DialTimeout: 200}
+
+	if err := redis.Register(config); err != nil {
+		t.Fatalf("Register失败: %v", err)
+	}
+	t.Cleanup(func() { _ = redis.Unregister(redis.DBInstanceName(7)) })
+
+	if _, err := redis.GetClient(7); !errors.Is(err, redis.ErrInstanceUnhealthy) {
+		t.Fatalf("期望数据库7不可用，实际: %v", err)
+	}
+	if health := redis.Health()[redis.DBInstanceName(7)]; health.Healthy || health.Err == nil {
+		t.Fatalf("期望初始状态不可用，实际: %+v", health)
+	}
+
+	// 服务器恢复后重新连接
+	server, err := redistest.Listen(net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		t.Fatalf("启动测试服务器失败: %v", err)
+	}
+	defer server.Close()
+
+	var changes []redis.InstanceHealth
+	onChange := redis.HealthOnChange(func(h redis.InstanceHealth) {
+		if h.Name == redis.DBInstanceName(7) {
+			changes = append(changes, h)
+		}
+	})
+
+	health := redis.CheckHealth(ctx, onChange)[redis.DBInstanceName(7)]
+	if !health.Healthy || health.Failures != 0 {
+		t.Fatalf("期望实例已恢复，实际: %+v", health)
+	}
+	client, err := redis.GetClient(7)
+	if err != nil {
+		t.Fatalf("期望数据库7可用，实际: %v", err)
+	}
+	if err := client.Set(ctx, "k", "v"); err != nil {
+		t.Errorf("重新连接后写入失败: %v", err)
+	}
+
+	// 服务器再次宕机
+	_ = server.Close()
+	health = redis.CheckHealth(ctx, onChange)[redis.DBInstanceName(7)]
+	if health.Healthy || health.Failures != 1 {
+		t.Errorf("期望实例不可用，实际: %+v", health)
+	}
+
+	if len(changes) != 2 || !changes[0].Healthy || changes[1].Healthy {
+		t.Errorf("期望两次状态变化（恢复、不可用），实际: %+v", changes)
+	}
+}
+
+// TestHealthConcurrentReconnect 测试并发健康检查只保留一个重新建立的连接
+func TestHealthConcurrentReconnect(t *testing.T) {
+	ctx := context.Background()
+	host, port := unusedAddr(t)
+	config := redis.Config{Address: host, Port: port, DB: []int{8}, DialTimeout: 200}
+
+	if err := redis.Register(config); err != nil {
+		t.Fatalf("Register失败: %v", err)
+	}
+	t.Cleanup(func() { _ = redis.Unregister(redis.DBInstanceName(8)) })
+
+	server, err := redistest.Listen(net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		t.Fatalf("启动测试服务器失败: %v", err)
+	}
+	defer server.Close()
+
+	var recovered atomic.Int32
+	onChange := redis.HealthOnChange(func(h redis.InstanceHealth) {
+		if h.Name == redis.DBInstanceName(8) && h.Healthy {
+			recovered.Add(1)
+		}
+	})
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			redis.CheckHealth(ctx, onChange)
+		}()
+	}
+	wg.Wait()
+
+	if n := recovered.Load(); n != 1 {
+		t.Errorf("期望只记录一次恢复，实际: %d", n)
+	}
+	client, err := redis.GetClient(8)
+	if err != nil {
+		t.Fatalf("期望数据库8可用，实际: %v", err)
+	}
+	if err := client.Set(ctx, "k", "v"); err != nil {
+		t.Errorf("重新连接后写入失败: %v", err)
+	}
+}
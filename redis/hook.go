@@ -0,0 +1,180 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/nilchaosky/go-nexus/logz"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// keylessCommands 不含键参数的常用命令，慢命令日志中不记录键名
+var keylessCommands = map[string]struct{}{
+	"auth": {}, "client": {}, "cluster": {}, "command": {}, "config": {}, "dbsize": {},
+	"discard": {}, "exec": {}, "flushall": {}, "flushdb": {}, "hello": {}, "info": {},
+	"multi": {}, "ping": {}, "pubsub": {}, "quit": {}, "randomkey": {}, "readonly": {},
+	"scan": {}, "script": {}, "select": {}, "slowlog": {}, "time": {}, "unwatch": {},
+}
+
+// instrumentHook 命令埋点钩子：记录慢命令日志并上报命令耗时和错误
+type instrumentHook struct {
+	instance string        // 实例名称
+	slow     time.Duration // 慢命令阈值（0为不记录）
+	redact   []string      // 键名脱敏匹配模式
+}
+
+// newInstrumentHook 根据配置创建命令埋点钩子
+func newInstrumentHook(instance string, config Config) *instrumentHook {
+	return &instrumentHook{
+		instance: instance,
+		slow:     time.Duration(config.SlowThreshold) * time.Millisecond,
+		redact:   config.RedactKeys,
+	}
+}
+
+// DialHook 建立连接钩子（不处理）
+func (h *instrumentHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook 单个命令钩子
+func (h *instrumentHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		duration := time.Since(start)
+
+		name := cmd.Name()
+		observeCommand(h.instance, name, duration, commandError(err))
+
+		if h.slow > 0 && duration >= h.slow {
+			logz.Logger.Warn("Redis慢命令",
+				zap.String("instance", h.instance),
+				zap.String("command", name),
+				zap.String("key", h.redactKey(commandKey(cmd))),
+				zap.Duration("duration", duration),
+				zap.Error(commandError(err)),
+			)
+		}
+
+		return err
+	}
+}
+
+// ProcessPipelineHook 管道和事务钩子，整个管道作为一次 pipeline 命令上报
+func (h *instrumentHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		duration := time.Since(start)
+
+		if err == nil {
+			for _, cmd := range cmds {
+				if err = commandError(cmd.Err()); err != nil {
+					break
+				}
+			}
+		}
+		observeCommand(h.instance, "pipeline", duration, commandError(err))
+
+		if h.slow > 0 && duration >= h.slow {
+			names := make([]string, len(cmds))
+			keys := make([]string, 0, len(cmds))
+			for i, cmd := range cmds {
+				names[i] = cmd.Name()
+				if key := commandKey(cmd); key != "" {
+					keys = append(keys, h.redactKey(key))
+				}
+			}
+			logz.Logger.Warn("Redis慢管道",
+				zap.String("instance", h.instance),
+				zap.Strings("commands", names),
+				zap.Strings("keys", keys),
+				zap.Duration("duration", duration),
+				zap.Error(commandError(err)),
+			)
+		}
+
+		return err
+	}
+}
+
+// redactKey 键名匹配脱敏模式时以模式代替
+func (h *instrumentHook) redactKey(key string) string {
+	for _, pattern := range h.redact {
+		if globMatch(pattern, key) {
+			return pattern
+		}
+	}
+	return key
+}
+
+// commandError 忽略表示数据不存在的 redis.Nil
+func commandError(err error) error {
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
+
+// commandKey 获取命令的第一个键（EVAL 系列为 KEYS[1]），无键命令返回空字符串
+func commandKey(cmd redis.Cmder) string {
+	args := cmd.Args()
+	name := cmd.Name()
+	if _, ok := keylessCommands[name]; ok {
+		return ""
+	}
+
+	pos := 1
+	switch name {
+	case "eval", "evalsha", "eval_ro", "evalsha_ro":
+		if len(args) < 3 || args[2] == "0" || args[2] == 0 {
+			return ""
+		}
+		pos = 3
+	}
+
+	if len(args) <= pos {
+		return ""
+	}
+	key, _ := args[pos].(string)
+	return key
+}
+
+// globMatch 按 Redis 匹配规则判断字符串是否匹配模式，支持 *、? 和 \ 转义
+func globMatch(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			pattern = strings.TrimLeft(pattern, "*")
+			if pattern == "" {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if globMatch(pattern, s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if s == "" {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if s == "" || s[0] != pattern[0] {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+		}
+	}
+	return s == ""
+}
@@ -0,0 +1,236 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nilchaosky/go-nexus/logz"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrInstanceNotFound 实例未注册
+	ErrInstanceNotFound = errors.New("实例未注册")
+	// ErrInstanceUnhealthy 实例不可用
+	ErrInstanceUnhealthy = errors.New("实例不可用")
+)
+
+var (
+	// instances 命名实例注册表
+	instances = make(map[string]*instance)
+	// instancesMu 命名实例注册表读写锁
+	instancesMu sync.RWMutex
+)
+
+// InstanceError 实例错误
+// 通过 errors.Is 可判断是 ErrInstanceNotFound 还是 ErrInstanceUnhealthy
+type InstanceError struct {
+	Name  string // 实例名称
+	Kind  error  // 错误类型：ErrInstanceNotFound, ErrInstanceUnhealthy
+	Cause error  // 原始错误（可为空）
+}
+
+// Error 返回错误信息
+func (e *InstanceError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("redis实例[%s]%s: %v", e.Name, e.Kind, e.Cause)
+	}
+	return fmt.Sprintf("redis实例[%s]%s", e.Name, e.Kind)
+}
+
+// Unwrap 返回包装的错误，支持 errors.Is 判断错误类型和原始错误
+func (e *InstanceError) Unwrap() []error {
+	if e.Cause != nil {
+		return []error{e.Kind, e.Cause}
+	}
+	return []error{e.Kind}
+}
+
+// instance 命名实例
+type instance struct {
+	name    string         // 实例名称
+	config  Config         // 实例配置
+	db      int            // 数据库编号
+	indexed bool           // 由 Register 注册，客户端同时存放在 Clients[db]
+	client  *Client        // 客户端（连接失败时为nil，由健康检查重新连接）
+	err     error          // 最近一次连接错误
+	health  InstanceHealth // 最近一次健康状态
+}
+
+// DBInstanceName 返回 Register 注册的数据库对应的实例名称
+func DBInstanceName(db int) string {
+	return fmt.Sprintf("db%d", db)
+}
+
+// RegisterInstance 注册命名实例
+// 每个实例使用独立的配置，可以指向不同的Redis服务器；DB列表最多指定一个数据库（默认：0）
+// 连接失败时实例仍会被记录，Get 将返回 ErrInstanceUnhealthy
+func RegisterInstance(name string, config Config) error {
+	if name == "" {
+		return errors.New("实例名称不能为空")
+	}
+
+	if err := config.validate(); err != nil {
+		return err
+	}
+
+	if len(config.DB) > 1 {
+		return errors.New("命名实例只能指定一个数据库")
+	}
+
+	db := 0
+	if len(config.DB) == 1 {
+		db = config.DB[0]
+	}
+
+	client, err := connect(context.Background(), name, config, db)
+	setInstance(name, config, db, false, client, err)
+	if err != nil {
+		return &InstanceError{Name: name, Kind: ErrInstanceUnhealthy, Cause: err}
+	}
+
+	return nil
+}
+
+// Get 获取命名实例的客户端
+// 实例未注册返回 ErrInstanceNotFound，连接失败返回 ErrInstanceUnhealthy
+func Get(name string) (*Client, error) {
+	instancesMu.RLock()
+	defer instancesMu.RUnlock()
+
+	inst, ok := instances[name]
+	if !ok {
+		return nil, &InstanceError{Name: name, Kind: ErrInstanceNotFound}
+	}
+
+	if inst.client == nil {
+		return nil, &InstanceError{Name: name, Kind: ErrInstanceUnhealthy, Cause: inst.err}
+	}
+
+	return inst.client, nil
+}
+
+// Instances 获取所有已注册的实例名称（按名称排序）
+func Instances() []string {
+	instancesMu.RLock()
+	defer instancesMu.RUnlock()
+
+	names := make([]string, 0, len(instances))
+	for name := range instances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Unregister 注销命名实例并关闭连接
+// Register 注册的数据库实例同时清空 Clients 中对应的客户端
+func Unregister(name string) error {
+	instancesMu.Lock()
+	inst, ok := instances[name]
+	delete(instances, name)
+	if ok && inst.indexed {
+		setIndexedClient(inst.db, inst.client, nil)
+	}
+	instancesMu.Unlock()
+
+	if !ok {
+		return &InstanceError{Name: name, Kind: ErrInstanceNotFound}
+	}
+
+	if inst.client != nil {
+		return inst.client.Close()
+	}
+
+	return nil
+}
+
+// setInstance 记录命名实例，替换同名实例时关闭旧连接
+// indexed 为 true 时客户端同时存放在 Clients[db]，健康检查重新连接后一并更新
+func setInstance(name string, config Config, db int, indexed bool, client *Client, err error) {
+	now := time.Now()
+	instancesMu.Lock()
+	old, ok := instances[name]
+	instances[name] = &instance{
+		name:    name,
+		config:  config,
+		db:      db,
+		indexed: indexed,
+		client:  client,
+		err:     err,
+		health: InstanceHealth{
+			Name:      name,
+			DB:        db,
+			Healthy:   err == nil,
+			Err:       err,
+			CheckedAt: now,
+			Since:     now,
+		},
+	}
+	if err != nil {
+		instances[name].health.Failures = 1
+	}
+	if indexed {
+		setIndexedClient(db, nil, client)
+	}
+	instancesMu.Unlock()
+
+	if ok && old.client != nil && old.client != client {
+		_ = old.client.Close()
+	}
+}
+
+// connect 创建指定数据库的客户端并测试连接，失败时关闭客户端
+// 客户端安装命令埋点钩子，以实例名称记录慢命令日志和命令指标
+func connect(ctx context.Context, name string, config Config, db int) (*Client, error) {
+	opts, err := config.options(db)
+	if err != nil {
+		return nil, err
+	}
+
+	serializer, err := config.serializer()
+	if err != nil {
+		return nil, err
+	}
+
+	clientOpts := []ClientOption{SerializerOption(serializer)}
+	codec, err := config.codecOption()
+	if err != nil {
+		return nil, err
+	}
+	if codec != nil {
+		clientOpts = append(clientOpts, codec)
+	}
+	if config.DecompressLimit > 0 {
+		clientOpts = append(clientOpts, DecompressLimitOption(config.DecompressLimit))
+	}
+	for device, limit := range config.SessionLimits {
+		clientOpts = append(clientOpts, SessionLimitOption(device, limit))
+	}
+
+	client := redis.NewUniversalClient(opts)
+	client.AddHook(newInstrumentHook(name, config))
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+
+	c := NewClient(client, clientOpts...)
+
+	// 预加载已注册的脚本，失败时不影响连接，执行时会自动回退到 EVAL
+	if err := c.LoadScripts(ctx); err != nil {
+		logz.Logger.Warn("预加载脚本失败",
+			zap.Int("db", db),
+			zap.Error(err),
+		)
+	}
+
+	return c, nil
+}
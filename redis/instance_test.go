@@ -0,0 +1,24 @@
+package redis_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nilchaosky/go-nexus/redis"
+)
+
+// TestDefaultClient 测试默认数据库未注册时返回错误而不是nil客户端
+func TestDefaultClient(t *testing.T) {
+	if err := redis.SetIndex(9); err != nil {
+		t.Fatalf("SetIndex失败: %v", err)
+	}
+	t.Cleanup(func() { _ = redis.SetIndex(0) })
+
+	client, err := redis.DefaultClient()
+	if client != nil || !errors.Is(err, redis.ErrInstanceNotFound) {
+		t.Errorf("期望返回ErrInstanceNotFound，实际: %v, %v", client, err)
+	}
+	if client := redis.GetDefaultClient(); client != nil {
+		t.Errorf("期望GetDefaultClient返回nil，实际: %v", client)
+	}
+}
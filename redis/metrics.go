@@ -0,0 +1,226 @@
+package redis
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultLatencyBuckets 默认命令耗时直方图桶（秒）
+var defaultLatencyBuckets = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+
+// Metrics 命令指标收集接口
+// 每条命令（管道整体作为 pipeline 命令）执行后调用一次，err 为nil表示成功（redis.Nil 视为成功）
+type Metrics interface {
+	ObserveCommand(instance, command string, duration time.Duration, err error)
+}
+
+// metricsHolder 包装 Metrics 以存入 atomic.Value
+type metricsHolder struct {
+	metrics Metrics
+}
+
+// currentMetrics 当前使用的指标收集器
+var currentMetrics atomic.Value
+
+// SetMetrics 设置命令指标收集器，对所有已注册和之后注册的客户端生效，nil 为关闭收集
+func SetMetrics(m Metrics) {
+	currentMetrics.Store(metricsHolder{metrics: m})
+}
+
+// observeCommand 上报命令耗时和错误
+func observeCommand(instance, command string, duration time.Duration, err error) {
+	holder, _ := currentMetrics.Load().(metricsHolder)
+	if holder.metrics != nil {
+		holder.metrics.ObserveCommand(instance, command, duration, err)
+	}
+}
+
+// PoolStats 获取所有命名实例（包括 Register 注册的数据库）的连接池统计，不可用的实例不包含在内
+func PoolStats() map[string]*redis.PoolStats {
+	instancesMu.RLock()
+	defer instancesMu.RUnlock()
+
+	stats := make(map[string]*redis.PoolStats, len(instances))
+	for name, inst := range instances {
+		if inst.client != nil {
+			stats[name] = inst.client.PoolStats()
+		}
+	}
+	return stats
+}
+
+// commandSeries 命令指标的标签
+type commandSeries struct {
+	instance string
+	command  string
+}
+
+// commandStats 命令指标
+type commandStats struct {
+	buckets []uint64 // 各桶的计数（非累积）
+	count   uint64   // 命令数量
+	sum     float64  // 总耗时（秒）
+	errors  uint64   // 错误数量
+}
+
+// PrometheusMetrics 内置的 Prometheus 指标收集器，实现 Metrics 和 http.Handler
+// 以 Prometheus 文本格式输出命令耗时直方图、错误计数和连接池统计
+type PrometheusMetrics struct {
+	buckets  []float64
+	mu       sync.Mutex
+	commands map[commandSeries]*commandStats
+}
+
+// NewPrometheusMetrics 创建 Prometheus 指标收集器，buckets 为耗时直方图桶上界（秒，默认：0.5ms - 2.5s）
+func NewPrometheusMetrics(buckets ...float64) *PrometheusMetrics {
+	if len(buckets) == 0 {
+		buckets = defaultLatencyBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &PrometheusMetrics{
+		buckets:  buckets,
+		commands: make(map[commandSeries]*commandStats),
+	}
+}
+
+// ObserveCommand 记录命令耗时和错误
+func (m *PrometheusMetrics) ObserveCommand(instance, command string, duration time.Duration, err error) {
+	seconds := duration.Seconds()
+	series := commandSeries{instance: instance, command: command}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.commands[series]
+	if !ok {
+		stats = &commandStats{buckets: make([]uint64, len(m.buckets))}
+		m.commands[series] = stats
+	}
+	if i := sort.SearchFloat64s(m.buckets, seconds); i < len(m.buckets) {
+		stats.buckets[i]++
+	}
+	stats.count++
+	stats.sum += seconds
+	if err != nil {
+		stats.errors++
+	}
+}
+
+// WriteTo 以 Prometheus 文本格式输出所有指标
+func (m *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	m.writeCommands(&buf)
+	writePoolStats(&buf, PoolStats())
+	return buf.WriteTo(w)
+}
+
+// ServeHTTP 输出 Prometheus 文本格式指标，可直接挂载为 /metrics 接口
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(w)
+}
+
+// writeCommands 输出命令耗时直方图和错误计数
+func (m *PrometheusMetrics) writeCommands(buf *bytes.Buffer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	series := make([]commandSeries, 0, len(m.commands))
+	for s := range m.commands {
+		series = append(series, s)
+	}
+	sort.Slice(series, func(i, j int) bool {
+		if series[i].instance != series[j].instance {
+			return series[i].instance < series[j].instance
+		}
+		return series[i].command < series[j].command
+	})
+
+	buf.WriteString("# HELP redis_command_duration_seconds Redis命令耗时\n")
+	buf.WriteString("# TYPE redis_command_duration_seconds histogram\n")
+	for _, s := range series {
+		stats := m.commands[s]
+		labels := promLabels("instance", s.instance, "command", s.command)
+
+		var cumulative uint64
+		for i, bound := range m.buckets {
+			cumulative += stats.buckets[i]
+			fmt.Fprintf(buf, "redis_command_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, formatFloat(bound), cumulative)
+		}
+		fmt.Fprintf(buf, "redis_command_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, stats.count)
+		fmt.Fprintf(buf, "redis_command_duration_seconds_sum{%s} %s\n", labels, formatFloat(stats.sum))
+		fmt.Fprintf(buf, "redis_command_duration_seconds_count{%s} %d\n", labels, stats.count)
+	}
+
+	buf.WriteString("# HELP redis_command_errors_total Redis命令错误数量\n")
+	buf.WriteString("# TYPE redis_command_errors_total counter\n")
+	for _, s := range series {
+		fmt.Fprintf(buf, "redis_command_errors_total{%s} %d\n", promLabels("instance", s.instance, "command", s.command), m.commands[s].errors)
+	}
+}
+
+// writePoolStats 输出连接池统计
+func writePoolStats(buf *bytes.Buffer, stats map[string]*redis.PoolStats) {
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	metrics := []struct {
+		name  string
+		kind  string
+		help  string
+		value func(*redis.PoolStats) uint64
+	}{
+		{"redis_pool_hits_total", "counter", "连接池命中次数", func(s *redis.PoolStats) uint64 { return uint64(s.Hits) }},
+		{"redis_pool_misses_total", "counter", "连接池未命中次数", func(s *redis.PoolStats) uint64 { return uint64(s.Misses) }},
+		{"redis_pool_timeouts_total", "counter", "获取连接超时次数", func(s *redis.PoolStats) uint64 { return uint64(s.Timeouts) }},
+		{"redis_pool_stale_conns_total", "counter", "移除的失效连接数量", func(s *redis.PoolStats) uint64 { return uint64(s.StaleConns) }},
+		{"redis_pool_total_conns", "gauge", "连接池连接总数", func(s *redis.PoolStats) uint64 { return uint64(s.TotalConns) }},
+		{"redis_pool_idle_conns", "gauge", "连接池空闲连接数", func(s *redis.PoolStats) uint64 { return uint64(s.IdleConns) }},
+	}
+
+	for _, metric := range metrics {
+		fmt.Fprintf(buf, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(buf, "# TYPE %s %s\n", metric.name, metric.kind)
+		for _, name := range names {
+			fmt.Fprintf(buf, "%s{%s} %d\n", metric.name, promLabels("instance", name), metric.value(stats[name]))
+		}
+	}
+}
+
+// promLabels 生成 Prometheus 标签字符串，参数为名称、值交替
+func promLabels(pairs ...string) string {
+	var sb strings.Builder
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(pairs[i])
+		sb.WriteString(`="`)
+		sb.WriteString(promEscaper.Replace(pairs[i+1]))
+		sb.WriteByte('"')
+	}
+	return sb.String()
+}
+
+// promEscaper 转义标签值中的反斜杠、双引号和换行
+var promEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatFloat 格式化浮点数
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
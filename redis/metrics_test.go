@@ -0,0 +1,185 @@
+package redis_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nilchaosky/go-nexus/logz"
+	"github.com/nilchaosky/go-nexus/redis"
+	"github.com/nilchaosky/go-nexus/redis/redistest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestPrometheusMetrics 测试直方图累积计数、错误计数和标签转义
+func TestPrometheusMetrics(t *testing.T) {
+	metrics := redis.NewPrometheusMetrics(0.01, 0.001)
+	metrics.ObserveCommand("main", "get", 500*time.Microsecond, nil)
+	metrics.ObserveCommand("main", "get", 5*time.Millisecond, errors.New("boom"))
+	metrics.ObserveCommand("main", "set", 2*time.Second, nil)
+	metrics.ObserveCommand(`a"b`, "get", time.Millisecond, nil)
+
+	var buf bytes.Buffer
+	if _, err := metrics.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo失败: %v", err)
+	}
+	out := buf.String()
+
+	for _, line := range []string{
+		"# TYPE redis_command_duration_seconds histogram",
+		`redis_command_duration_seconds_bucket{instance="main",command="get",le="0.001"} 1`,
+		`redis_command_duration_seconds_bucket{instance="main",command="get",le="0.01"} 2`,
+		`redis_command_duration_seconds_bucket{instance="main",command="get",le="+Inf"} 2`,
+		`redis_command_duration_seconds_sum{instance="main",command="get"} 0.0055`,
+		`redis_command_duration_seconds_count{instance="main",command="get"} 2`,
+		`redis_command_duration_seconds_bucket{instance="main",command="set",le="0.01"} 0`,
+		`redis_command_duration_seconds_bucket{instance="main",command="set",le="+Inf"} 1`,
+		`redis_command_duration_seconds_bucket{instance="a\"b",command="get",le="0.001"} 1`,
+		`redis_command_errors_total{instance="main",command="get"} 1`,
+		`redis_command_errors_total{instance="main",command="set"} 0`,
+		"# TYPE redis_pool_total_conns gauge",
+	} {
+		if !strings.Contains(out, line+"\n") {
+			t.Errorf("输出缺少: %s", line)
+		}
+	}
+	if strings.Index(out, `instance="a\"b"`) > strings.Index(out, `instance="main"`) {
+		t.Error("期望按实例名称排序输出")
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type不符: %s", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "redis_command_errors_total") {
+		t.Error("期望HTTP接口输出命令指标")
+	}
+}
+
+// commandRecord 记录的命令指标
+type commandRecord struct {
+	command string
+	err     error
+}
+
+// recordingMetrics 记录指定实例命令指标的 Metrics
+type recordingMetrics struct {
+	instance string
+	mu       sync.Mutex
+	records  []commandRecord
+}
+
+// ObserveCommand 实现 redis.Metrics
+func (m *recordingMetrics) ObserveCommand(instance, command string, _ time.Duration, err error) {
+	if instance != m.instance {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = append(m.records, commandRecord{command: command, err: err})
+}
+
+// list 获取记录的副本
+func (m *recordingMetrics) list() []commandRecord {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]commandRecord(nil), m.records...)
+}
+
+// TestInstrumentHook 测试注册实例的命令埋点：命令指标、redis.Nil 不计为错误、管道整体上报、慢命令日志脱敏和连接池统计
+func TestInstrumentHook(t *testing.T) {
+	ctx := context.Background()
+	server := redistest.Run(t)
+	host, port, _ := net.SplitHostPort(server.Addr())
+	portNum, _ := strconv.Atoi(port)
+
+	recorder := &recordingMetrics{instance: "metrics"}
+	redis.SetMetrics(recorder)
+	t.Cleanup(func() { redis.SetMetrics(nil) })
+
+	core, logs := observer.New(zap.WarnLevel)
+	logger := logz.Logger
+	logz.Logger = zap.New(core)
+	t.Cleanup(func() { logz.Logger = logger })
+
+	config := redis.Config{Address: host, Port: portNum, SlowThreshold: 1, RedactKeys: []string{"USER:*:Token"}}
+	if err := redis.RegisterInstance("metrics", config); err != nil {
+		t.Fatalf("RegisterInstance失败: %v", err)
+	}
+	t.Cleanup(func() { _ = redis.Unregister("metrics") })
+	client, err := redis.Get("metrics")
+	if err != nil {
+		t.Fatalf("Get失败: %v", err)
+	}
+	rdb := client.GetRawClient()
+	// 跳过建立连接时的握手命令
+	start := len(recorder.list())
+
+	if _, err := client.Get(ctx, "missing"); !redis.IsNotFound(err) {
+		t.Fatalf("期望ErrNotFound，实际: %v", err)
+	}
+	if _, err := client.RPush(ctx, "list", "a"); err != nil {
+		t.Fatalf("RPush失败: %v", err)
+	}
+	if _, err := client.Get(ctx, "list"); err == nil {
+		t.Fatal("期望WRONGTYPE错误")
+	}
+	pipe := rdb.Pipeline()
+	pipe.Set(ctx, "a", "1", 0)
+	pipe.Incr(ctx, "list")
+	_, _ = pipe.Exec(ctx)
+
+	// 脚本中的空循环耗时超过1毫秒，触发慢命令日志
+	if err := rdb.Eval(ctx, "for i = 1, 200000 do end return 1", []string{"USER:42:Token"}).Err(); err != nil {
+		t.Fatalf("Eval失败: %v", err)
+	}
+
+	var gets, getErrors, pipelines, pipelineErrors int
+	for _, r := range recorder.list()[start:] {
+		switch r.command {
+		case "get":
+			gets++
+			if r.err != nil {
+				getErrors++
+			}
+		case "pipeline":
+			pipelines++
+			if r.err != nil {
+				pipelineErrors++
+			}
+		}
+	}
+	if gets != 2 || getErrors != 1 {
+		t.Errorf("期望2次get其中1次错误（redis.Nil不计为错误），实际: %d, %d", gets, getErrors)
+	}
+	if pipelines != 1 || pipelineErrors != 1 {
+		t.Errorf("期望管道整体上报1次且含错误，实际: %d, %d", pipelines, pipelineErrors)
+	}
+
+	slow := logs.FilterMessage("Redis慢命令").FilterField(zap.String("command", "eval")).All()
+	if len(slow) == 0 {
+		t.Fatal("期望记录慢命令日志")
+	}
+	if key := slow[0].ContextMap()["key"]; key != "USER:*:Token" {
+		t.Errorf("期望键名以脱敏模式代替，实际: %v", key)
+	}
+
+	stats := redis.PoolStats()["metrics"]
+	if stats == nil || stats.TotalConns == 0 {
+		t.Errorf("期望包含实例的连接池统计，实际: %+v", stats)
+	}
+	var buf bytes.Buffer
+	_, _ = redis.NewPrometheusMetrics().WriteTo(&buf)
+	if !strings.Contains(buf.String(), `redis_pool_total_conns{instance="metrics"}`) {
+		t.Error("期望Prometheus输出包含实例的连接池统计")
+	}
+}
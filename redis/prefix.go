@@ -0,0 +1,137 @@
+package redis
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// WithPrefix 返回带键前缀的客户端视图，与原客户端共享连接
+// 视图中 Generic、String、List、Set、Hash、ZSet 等命令的所有键参数自动添加前缀，Keys、Scan 返回的键去除前缀
+// 在已有前缀的视图上调用时前缀叠加，例如：c.WithPrefix("order:").WithPrefix("item:") 的前缀为 "order:item:"
+func (c *Client) WithPrefix(prefix string) *Client {
+	view := *c
+	view.prefix = c.prefix + prefix
+	return &view
+}
+
+// Prefix 获取客户端的键前缀
+func (c *Client) Prefix() string {
+	return c.prefix
+}
+
+// key 为键添加前缀
+func (c *Client) key(key string) string {
+	if c.prefix == "" {
+		return key
+	}
+	return c.prefix + key
+}
+
+// keys 为多个键添加前缀
+func (c *Client) keys(keys []string) []string {
+	if c.prefix == "" {
+		return keys
+	}
+	results := make([]string, len(keys))
+	for i, key := range keys {
+		results[i] = c.prefix + key
+	}
+	return results
+}
+
+// pattern 为匹配模式添加前缀，空模式匹配前缀下的所有键
+// 前缀中的通配符会被转义
+func (c *Client) pattern(pattern string) string {
+	if c.prefix == "" {
+		return pattern
+	}
+	if pattern == "" {
+		pattern = "*"
+	}
+	return escapePattern(c.prefix) + pattern
+}
+
+// stripKey 去除键的前缀，不带前缀的键原样返回
+func (c *Client) stripKey(key string) string {
+	if c.prefix == "" {
+		return key
+	}
+	return strings.TrimPrefix(key, c.prefix)
+}
+
+// stripKeys 去除多个键的前缀
+func (c *Client) stripKeys(keys []string) []string {
+	if c.prefix == "" {
+		return keys
+	}
+	for i, key := range keys {
+		keys[i] = strings.TrimPrefix(key, c.prefix)
+	}
+	return keys
+}
+
+// zstore 为有序集合聚合参数中的键添加前缀
+func (c *Client) zstore(store *redis.ZStore) *redis.ZStore {
+	if c.prefix == "" || store == nil {
+		return store
+	}
+	view := *store
+	view.Keys = c.keys(store.Keys)
+	return &view
+}
+
+// keyValuePairs 序列化键值对中的值，并为键添加前缀
+// 支持 "key1", "value1", "key2", "value2" 或单个 map 参数，map 参数无论是否有前缀都展开后按相同规则序列化
+func (c *Client) keyValuePairs(values []interface{}) ([]interface{}, error) {
+	if len(values) == 1 {
+		pairs, err := mapPairs(values[0])
+		if err == nil {
+			values = pairs
+		} else if c.prefix != "" {
+			return nil, err
+		}
+	}
+
+	values, err := c.marshalPairs(values)
+	if err != nil || c.prefix == "" || len(values) <= 1 {
+		return values, err
+	}
+
+	for i := 0; i < len(values); i += 2 {
+		values[i] = c.prefix + fmt.Sprint(values[i])
+	}
+	return values, nil
+}
+
+// mapPairs 将字符串键的 map 展开为键值对切片
+func mapPairs(value interface{}) ([]interface{}, error) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("不支持的键值对类型: %T", value)
+	}
+
+	pairs := make([]interface{}, 0, rv.Len()*2)
+	iter := rv.MapRange()
+	for iter.Next() {
+		pairs = append(pairs, iter.Key().String(), iter.Value().Interface())
+	}
+	return pairs, nil
+}
+
+// escapePattern 转义匹配模式中的通配符
+func escapePattern(s string) string {
+	if !strings.ContainsAny(s, `*?[]\`) {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -4,9 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/nilchaosky/go-nexus/logz"
-	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
@@ -15,6 +15,8 @@ var (
 	Clients []*Client
 	// index 默认客户端索引（默认：0）
 	index int
+	// clientsMu 保护 Clients，健康检查重新连接时会更新其中的客户端
+	clientsMu sync.RWMutex
 )
 
 // init 包加载时自动初始化
@@ -25,32 +27,26 @@ func init() {
 }
 
 // Register 注册客户端
+// 兼容按数据库编号访问的方式，每个数据库同时以 DBInstanceName(db) 注册为命名实例
 func Register(config Config) error {
-	// 验证地址和端口
-	if config.Address == "" {
-		return errors.New("地址不能为空")
-	}
-
-	if config.Port == 0 {
-		return errors.New("端口不能为空")
+	// 验证配置
+	if err := config.validate(); err != nil {
+		return err
 	}
 
 	ctx := context.Background()
 
 	// 如果DB列表为空，直接注册到索引0，连接失败则报错
 	if len(config.DB) == 0 {
-		client := redis.NewClient(&redis.Options{
-			Addr:     fmt.Sprintf("%s:%d", config.Address, config.Port),
-			Password: config.Password,
-			DB:       0,
-		})
+		// 客户端由 setInstance 存放到 Clients[0]，连接失败时由健康检查重新连接
+		client, err := connect(ctx, DBInstanceName(0), config, 0)
+		setInstance(DBInstanceName(0), config, 0, true, client, err)
 
 		// 测试连接，失败则报错
-		if err := client.Ping(ctx).Err(); err != nil {
+		if err != nil {
 			return fmt.Errorf("数据库0连接失败: %w", err)
 		}
 
-		Clients[0] = NewClient(client)
 		return nil
 	}
 
@@ -66,37 +62,51 @@ func Register(config Config) error {
 			continue
 		}
 
-		client := redis.NewClient(&redis.Options{
-			Addr:     fmt.Sprintf("%s:%d", config.Address, config.Port),
-			Password: config.Password,
-			DB:       db,
-		})
+		client, err := connect(ctx, DBInstanceName(db), config, db)
+		// 记录到命名实例注册表并存储到对应索引位置，连接失败的数据库标记为不可用
+		setInstance(DBInstanceName(db), config, db, true, client, err)
 
-		// 测试连接，失败则跳过
-		if err := client.Ping(ctx).Err(); err != nil {
+		// 测试连接，失败则跳过，由健康检查重新连接
+		if err != nil {
 			logz.Logger.Warn("数据库连接失败，已跳过",
 				zap.Int("db", db),
 				zap.Error(err),
 			)
-			continue
 		}
-
-		// 将客户端存储到对应索引位置
-		Clients[db] = NewClient(client)
 	}
 
 	return nil
 }
 
 // GetClient 获取指定数据库的客户端
+// 数据库未注册返回 ErrInstanceNotFound，连接失败返回 ErrInstanceUnhealthy
 func GetClient(db int) (*Client, error) {
 	if db < 0 || db >= len(Clients) {
 		return nil, errors.New("数据库超出范围")
 	}
+	clientsMu.RLock()
 	client := Clients[db]
+	clientsMu.RUnlock()
+	if client == nil {
+		return nil, indexedError(db)
+	}
 	return client, nil
 }
 
+// indexedError 返回 Clients[db] 为空时的错误：未通过 Register 注册为 ErrInstanceNotFound，否则为 ErrInstanceUnhealthy
+func indexedError(db int) error {
+	name := DBInstanceName(db)
+
+	instancesMu.RLock()
+	defer instancesMu.RUnlock()
+
+	inst, ok := instances[name]
+	if !ok || !inst.indexed {
+		return &InstanceError{Name: name, Kind: ErrInstanceNotFound}
+	}
+	return &InstanceError{Name: name, Kind: ErrInstanceUnhealthy, Cause: inst.err}
+}
+
 // SetIndex 设置默认客户端索引
 func SetIndex(i int) error {
 	if i < 0 || i >= len(Clients) {
@@ -107,7 +117,29 @@ func SetIndex(i int) error {
 }
 
 // GetDefaultClient 获取默认客户端（使用index索引）
+// 默认数据库未注册或不可用时返回nil，需要区分原因时使用 DefaultClient
 func GetDefaultClient() *Client {
-	client := Clients[index]
+	client, _ := DefaultClient()
 	return client
 }
+
+// DefaultClient 获取默认客户端（使用index索引）
+// 默认数据库未注册返回 ErrInstanceNotFound，连接失败返回 ErrInstanceUnhealthy
+func DefaultClient() (*Client, error) {
+	return GetClient(index)
+}
+
+// setIndexedClient 将 Clients[db] 从 old 替换为 client
+// old 不为 nil 时仅在 Clients[db] 仍为 old 时替换，避免覆盖重新注册的客户端
+func setIndexedClient(db int, old, client *Client) {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+
+	if db < 0 || db >= len(Clients) {
+		return
+	}
+	if old != nil && Clients[db] != old {
+		return
+	}
+	Clients[db] = client
+}
@@ -0,0 +1,39 @@
+package redistest
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock 可控时钟，驱动测试服务器中键的过期时间
+// 时钟不会自动前进，需通过 Advance 或 Set 调整
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock 创建以指定时间开始的时钟
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now 获取当前时间
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance 将时钟前进指定时长
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set 将时钟设置为指定时间
+func (c *Clock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
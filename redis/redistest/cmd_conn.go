@@ -0,0 +1,214 @@
+package redistest
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// command 命令定义
+type command struct {
+	fn      func(c *conn, args []string) error
+	arity   int  // 参数数量（含命令名），负数表示最少数量
+	control bool // 事务控制命令，MULTI 中立即执行而不入队
+}
+
+// commands 支持的命令表
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		// 连接与服务器
+		"ping":     {fn: cmdPing, arity: -1},
+		"echo":     {fn: cmdEcho, arity: 2},
+		"select":   {fn: cmdSelect, arity: 2},
+		"client":   {fn: cmdOK, arity: -2},
+		"quit":     {fn: cmdOK, arity: -1, control: true},
+		"dbsize":   {fn: cmdDBSize, arity: 1},
+		"flushdb":  {fn: cmdFlushDB, arity: -1},
+		"flushall": {fn: cmdFlushAll, arity: -1},
+		"time":     {fn: cmdTime, arity: 1},
+
+		// 事务
+		"multi":   {fn: cmdMulti, arity: 1, control: true},
+		"exec":    {fn: cmdExec, arity: 1, control: true},
+		"discard": {fn: cmdDiscard, arity: 1, control: true},
+		"watch":   {fn: cmdWatch, arity: -2, control: true},
+		"unwatch": {fn: cmdUnwatch, arity: 1},
+	}
+
+	for _, table := range []map[string]command{genericCommands, stringCommands, listCommands, setCommands, hashCommands, zsetCommands, streamCommands, pubsubCommands, scriptCommands} {
+		for name, cmd := range table {
+			commands[name] = cmd
+		}
+	}
+}
+
+// cmdOK 直接返回 OK 的命令
+func cmdOK(c *conn, _ []string) error {
+	c.w.ok()
+	return nil
+}
+
+// cmdPing PING [message]
+func cmdPing(c *conn, args []string) error {
+	if c.subscribed() {
+		c.pong(args)
+		return nil
+	}
+	if len(args) > 1 {
+		c.w.bulk(args[1])
+		return nil
+	}
+	c.w.simple("PONG")
+	return nil
+}
+
+// cmdEcho ECHO message
+func cmdEcho(c *conn, args []string) error {
+	c.w.bulk(args[1])
+	return nil
+}
+
+// cmdSelect SELECT index
+func cmdSelect(c *conn, args []string) error {
+	index, err := strconv.Atoi(args[1])
+	if err != nil || index < 0 || index >= dbCount {
+		return errInvalidDB
+	}
+	c.dbIndex = index
+	c.w.ok()
+	return nil
+}
+
+// cmdDBSize DBSIZE
+func cmdDBSize(c *conn, _ []string) error {
+	c.w.int(int64(len(c.db().keys())))
+	return nil
+}
+
+// cmdFlushDB FLUSHDB [ASYNC|SYNC]
+func cmdFlushDB(c *conn, _ []string) error {
+	c.db().flush()
+	c.w.ok()
+	return nil
+}
+
+// cmdFlushAll FLUSHALL [ASYNC|SYNC]
+func cmdFlushAll(c *conn, _ []string) error {
+	c.server.store.flush()
+	c.w.ok()
+	return nil
+}
+
+// cmdTime TIME，返回时钟的当前时间
+func cmdTime(c *conn, _ []string) error {
+	now := c.server.clock.Now()
+	c.w.strings([]string{
+		strconv.FormatInt(now.Unix(), 10),
+		strconv.FormatInt(int64(now.Nanosecond()/1000), 10),
+	})
+	return nil
+}
+
+// cmdMulti MULTI
+func cmdMulti(c *conn, _ []string) error {
+	if c.multi {
+		return errors.New("ERR MULTI calls can not be nested")
+	}
+	c.multi = true
+	c.dirty = false
+	c.queued = nil
+	c.w.ok()
+	return nil
+}
+
+// cmdExec EXEC，WATCH 的键被修改时返回空数组
+func cmdExec(c *conn, _ []string) error {
+	if !c.multi {
+		return errors.New("ERR EXEC without MULTI")
+	}
+
+	queued, dirty, watched := c.queued, c.dirty, c.watched
+	c.multi, c.dirty, c.queued, c.watched = false, false, nil, nil
+
+	if dirty {
+		return errors.New("EXECABORT Transaction discarded because of previous errors.")
+	}
+
+	for wk, version := range watched {
+		d := c.server.store.dbs[wk.db]
+		d.get(wk.key)
+		if d.versions[wk.key] != version {
+			c.w.nullArray()
+			return nil
+		}
+	}
+
+	c.inExec = true
+	defer func() { c.inExec = false }()
+
+	c.w.array(len(queued))
+	for _, args := range queued {
+		if err := commands[strings.ToLower(args[0])].fn(c, args); err != nil {
+			c.w.error(err)
+		}
+	}
+	return nil
+}
+
+// cmdDiscard DISCARD
+func cmdDiscard(c *conn, _ []string) error {
+	if !c.multi {
+		return errors.New("ERR DISCARD without MULTI")
+	}
+	c.multi, c.dirty, c.queued, c.watched = false, false, nil, nil
+	c.w.ok()
+	return nil
+}
+
+// cmdWatch WATCH key [key ...]
+func cmdWatch(c *conn, args []string) error {
+	if c.multi {
+		return errors.New("ERR WATCH inside MULTI is not allowed")
+	}
+	if c.watched == nil {
+		c.watched = make(map[watchKey]uint64)
+	}
+	d := c.db()
+	for _, key := range args[1:] {
+		d.get(key)
+		c.watched[watchKey{db: c.dbIndex, key: key}] = d.versions[key]
+	}
+	c.w.ok()
+	return nil
+}
+
+// cmdUnwatch UNWATCH
+func cmdUnwatch(c *conn, _ []string) error {
+	c.watched = nil
+	c.w.ok()
+	return nil
+}
+
+// deadline 解析阻塞命令的超时时间（秒，可为小数），返回真实时间的截止时间，0 为一直等待
+func deadline(s string) (time.Time, error) {
+	timeout, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, errors.New("ERR timeout is not a float or out of range")
+	}
+	if timeout < 0 {
+		return time.Time{}, errNegativeTime
+	}
+	if timeout == 0 {
+		return time.Time{}, nil
+	}
+	return time.Now().Add(time.Duration(timeout * float64(time.Second))), nil
+}
+
+// wrongArgs 返回参数数量错误
+func wrongArgs(name string) error {
+	return fmt.Errorf("ERR wrong number of arguments for '%s' command", strings.ToLower(name))
+}
@@ -0,0 +1,394 @@
+package redistest
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dumpPrefix DUMP 序列化值的前缀，用于 RESTORE 校验
+const dumpPrefix = "redistest:"
+
+// genericCommands 通用命令
+var genericCommands = map[string]command{
+	"del":       {fn: cmdDel, arity: -2},
+	"unlink":    {fn: cmdDel, arity: -2},
+	"exists":    {fn: cmdExists, arity: -2},
+	"expire":    {fn: cmdExpire, arity: -3},
+	"pexpire":   {fn: cmdExpire, arity: -3},
+	"expireat":  {fn: cmdExpire, arity: -3},
+	"pexpireat": {fn: cmdExpire, arity: -3},
+	"ttl":       {fn: cmdTTL, arity: 2},
+	"pttl":      {fn: cmdTTL, arity: 2},
+	"persist":   {fn: cmdPersist, arity: 2},
+	"keys":      {fn: cmdKeys, arity: 2},
+	"scan":      {fn: cmdScan, arity: -2},
+	"type":      {fn: cmdType, arity: 2},
+	"rename":    {fn: cmdRename, arity: 3},
+	"renamenx":  {fn: cmdRename, arity: 3},
+	"move":      {fn: cmdMove, arity: 3},
+	"randomkey": {fn: cmdRandomKey, arity: 1},
+	"dump":      {fn: cmdDump, arity: 2},
+	"restore":   {fn: cmdRestore, arity: -4},
+}
+
+// cmdDel DEL/UNLINK key [key ...]
+func cmdDel(c *conn, args []string) error {
+	d := c.db()
+	var n int64
+	for _, key := range args[1:] {
+		if d.remove(key) {
+			n++
+		}
+	}
+	c.w.int(n)
+	return nil
+}
+
+// cmdExists EXISTS key [key ...]
+func cmdExists(c *conn, args []string) error {
+	d := c.db()
+	var n int64
+	for _, key := range args[1:] {
+		if d.get(key) != nil {
+			n++
+		}
+	}
+	c.w.int(n)
+	return nil
+}
+
+// cmdExpire EXPIRE/PEXPIRE/EXPIREAT/PEXPIREAT key value [NX|XX|GT|LT]
+func cmdExpire(c *conn, args []string) error {
+	value, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+
+	now := c.server.clock.Now()
+	var at time.Time
+	switch strings.ToLower(args[0]) {
+	case "expire":
+		at = now.Add(time.Duration(value) * time.Second)
+	case "pexpire":
+		at = now.Add(time.Duration(value) * time.Millisecond)
+	case "expireat":
+		at = time.Unix(value, 0)
+	default:
+		at = time.UnixMilli(value)
+	}
+
+	d := c.db()
+	key := args[1]
+	e := d.get(key)
+	if e == nil {
+		c.w.int(0)
+		return nil
+	}
+
+	for _, opt := range args[3:] {
+		var ok bool
+		switch strings.ToLower(opt) {
+		case "nx":
+			ok = e.expireAt.IsZero()
+		case "xx":
+			ok = !e.expireAt.IsZero()
+		case "gt":
+			ok = !e.expireAt.IsZero() && at.After(e.expireAt)
+		case "lt":
+			ok = e.expireAt.IsZero() || at.Before(e.expireAt)
+		default:
+			return errSyntax
+		}
+		if !ok {
+			c.w.int(0)
+			return nil
+		}
+	}
+
+	if !at.After(now) {
+		d.remove(key)
+	} else {
+		e.expireAt = at
+		d.touch(key)
+	}
+	c.w.int(1)
+	return nil
+}
+
+// cmdTTL TTL/PTTL key，不存在返回-2，未设置过期时间返回-1
+func cmdTTL(c *conn, args []string) error {
+	e := c.db().get(args[1])
+	switch {
+	case e == nil:
+		c.w.int(-2)
+	case e.expireAt.IsZero():
+		c.w.int(-1)
+	default:
+		ttl := e.expireAt.Sub(c.server.clock.Now())
+		if strings.EqualFold(args[0], "pttl") {
+			c.w.int(ttl.Milliseconds())
+		} else {
+			c.w.int(int64((ttl + time.Second/2) / time.Second))
+		}
+	}
+	return nil
+}
+
+// cmdPersist PERSIST key
+func cmdPersist(c *conn, args []string) error {
+	d := c.db()
+	e := d.get(args[1])
+	if e == nil || e.expireAt.IsZero() {
+		c.w.int(0)
+		return nil
+	}
+	e.expireAt = time.Time{}
+	d.touch(args[1])
+	c.w.int(1)
+	return nil
+}
+
+// cmdKeys KEYS pattern
+func cmdKeys(c *conn, args []string) error {
+	keys := make([]string, 0)
+	for _, key := range c.db().keys() {
+		if globMatch(args[1], key) {
+			keys = append(keys, key)
+		}
+	}
+	c.w.strings(keys)
+	return nil
+}
+
+// cmdScan SCAN cursor [MATCH pattern] [COUNT count] [TYPE type]
+// 游标为按键名排序后的偏移量
+func cmdScan(c *conn, args []string) error {
+	cursor, err := strconv.Atoi(args[1])
+	if err != nil || cursor < 0 {
+		return errors.New("ERR invalid cursor")
+	}
+	match, count, keyType, err := parseScanArgs(args[2:], true)
+	if err != nil {
+		return err
+	}
+
+	d := c.db()
+	all := d.keys()
+	end := min(cursor+count, len(all))
+	next := end
+	if end >= len(all) {
+		next = 0
+	}
+
+	keys := make([]string, 0)
+	for _, key := range all[min(cursor, len(all)):end] {
+		if match != "" && !globMatch(match, key) {
+			continue
+		}
+		if keyType != "" && !strings.EqualFold(d.get(key).kind.String(), keyType) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	c.w.array(2)
+	c.w.bulk(strconv.Itoa(next))
+	c.w.strings(keys)
+	return nil
+}
+
+// parseScanArgs 解析 SCAN 系列命令的 MATCH、COUNT、TYPE 选项
+func parseScanArgs(args []string, allowType bool) (match string, count int, keyType string, err error) {
+	count = 10
+	for i := 0; i < len(args); i += 2 {
+		if i+1 >= len(args) {
+			return "", 0, "", errSyntax
+		}
+		switch strings.ToLower(args[i]) {
+		case "match":
+			match = args[i+1]
+		case "count":
+			count, err = strconv.Atoi(args[i+1])
+			if err != nil || count < 1 {
+				return "", 0, "", errSyntax
+			}
+		case "type":
+			if !allowType {
+				return "", 0, "", errSyntax
+			}
+			keyType = args[i+1]
+		default:
+			return "", 0, "", errSyntax
+		}
+	}
+	return match, count, keyType, nil
+}
+
+// cmdType TYPE key
+func cmdType(c *conn, args []string) error {
+	e := c.db().get(args[1])
+	if e == nil {
+		c.w.simple("none")
+		return nil
+	}
+	c.w.simple(e.kind.String())
+	return nil
+}
+
+// cmdRename RENAME/RENAMENX key newkey
+func cmdRename(c *conn, args []string) error {
+	d := c.db()
+	e := d.get(args[1])
+	if e == nil {
+		return errNoSuchKey
+	}
+
+	nx := strings.EqualFold(args[0], "renamenx")
+	if nx && d.get(args[2]) != nil {
+		c.w.int(0)
+		return nil
+	}
+	if args[1] != args[2] {
+		d.remove(args[1])
+		d.set(args[2], e)
+	}
+
+	if nx {
+		c.w.int(1)
+	} else {
+		c.w.ok()
+	}
+	return nil
+}
+
+// cmdMove MOVE key db
+func cmdMove(c *conn, args []string) error {
+	index, err := strconv.Atoi(args[2])
+	if err != nil || index < 0 || index >= dbCount {
+		return errInvalidDB
+	}
+	if index == c.dbIndex {
+		return errors.New("ERR source and destination objects are the same")
+	}
+
+	src, dst := c.db(), c.server.store.dbs[index]
+	e := src.get(args[1])
+	if e == nil || dst.get(args[1]) != nil {
+		c.w.int(0)
+		return nil
+	}
+	src.remove(args[1])
+	dst.set(args[1], e)
+	c.w.int(1)
+	return nil
+}
+
+// cmdRandomKey RANDOMKEY
+func cmdRandomKey(c *conn, _ []string) error {
+	keys := c.db().keys()
+	if len(keys) == 0 {
+		c.w.null()
+		return nil
+	}
+	c.w.bulk(keys[rand.IntN(len(keys))])
+	return nil
+}
+
+// dumpValue DUMP 序列化的值
+type dumpValue struct {
+	Kind kind              `json:"kind"`
+	Str  string            `json:"str,omitempty"`
+	List []string          `json:"list,omitempty"`
+	Set  []string          `json:"set,omitempty"`
+	Hash map[string]string `json:"hash,omitempty"`
+	ZSet map[string]string `json:"zset,omitempty"`
+}
+
+// cmdDump DUMP key，序列化格式仅用于本服务器的 RESTORE
+func cmdDump(c *conn, args []string) error {
+	e := c.db().get(args[1])
+	if e == nil {
+		c.w.null()
+		return nil
+	}
+	if e.kind == kindStream {
+		return errors.New("ERR DUMP of stream values is not supported")
+	}
+
+	v := dumpValue{Kind: e.kind, Str: e.str, List: e.list, Hash: e.hash}
+	for member := range e.set {
+		v.Set = append(v.Set, member)
+	}
+	if e.kind == kindZSet {
+		v.ZSet = make(map[string]string, len(e.zset))
+		for member, score := range e.zset {
+			v.ZSet[member] = formatFloat(score)
+		}
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return errors.New("ERR " + err.Error())
+	}
+	c.w.bulk(dumpPrefix + string(data))
+	return nil
+}
+
+// cmdRestore RESTORE key ttl serialized-value [REPLACE]
+func cmdRestore(c *conn, args []string) error {
+	ttl, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+	if ttl < 0 {
+		return errInvalidTTL
+	}
+
+	replace := false
+	for _, opt := range args[4:] {
+		if !strings.EqualFold(opt, "replace") {
+			return errSyntax
+		}
+		replace = true
+	}
+
+	d := c.db()
+	if !replace && d.get(args[1]) != nil {
+		return errBusyKey
+	}
+
+	data, ok := strings.CutPrefix(args[3], dumpPrefix)
+	if !ok {
+		return errBadDump
+	}
+	var v dumpValue
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		return errBadDump
+	}
+
+	e := newEntry(v.Kind)
+	e.str = v.Str
+	e.list = append([]string(nil), v.List...)
+	for _, member := range v.Set {
+		e.set[member] = struct{}{}
+	}
+	for field, value := range v.Hash {
+		e.hash[field] = value
+	}
+	for member, score := range v.ZSet {
+		if e.zset[member], err = parseFloat(score); err != nil {
+			return errBadDump
+		}
+	}
+	if ttl > 0 {
+		e.expireAt = c.server.clock.Now().Add(time.Duration(ttl) * time.Millisecond)
+	}
+
+	d.set(args[1], e)
+	c.w.ok()
+	return nil
+}
@@ -0,0 +1,325 @@
+package redistest
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// hashCommands 哈希命令
+var hashCommands = map[string]command{
+	"hset":         {fn: cmdHSet, arity: -4},
+	"hmset":        {fn: cmdHSet, arity: -4},
+	"hsetnx":       {fn: cmdHSetNX, arity: 4},
+	"hget":         {fn: cmdHGet, arity: 3},
+	"hmget":        {fn: cmdHMGet, arity: -3},
+	"hgetall":      {fn: cmdHGetAll, arity: 2},
+	"hdel":         {fn: cmdHDel, arity: -3},
+	"hexists":      {fn: cmdHExists, arity: 3},
+	"hincrby":      {fn: cmdHIncrBy, arity: 4},
+	"hincrbyfloat": {fn: cmdHIncrByFloat, arity: 4},
+	"hkeys":        {fn: cmdHKeys, arity: 2},
+	"hvals":        {fn: cmdHVals, arity: 2},
+	"hlen":         {fn: cmdHLen, arity: 2},
+	"hstrlen":      {fn: cmdHStrlen, arity: 3},
+	"hscan":        {fn: cmdHScan, arity: -3},
+}
+
+// getHash 获取哈希类型的键值
+func getHash(d *db, key string) (*entry, error) {
+	return d.getKind(key, kindHash)
+}
+
+// sortedFields 获取哈希的所有字段（已排序）
+func sortedFields(hash map[string]string) []string {
+	fields := make([]string, 0, len(hash))
+	for field := range hash {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// cmdHSet HSET/HMSET key field value [field value ...]
+func cmdHSet(c *conn, args []string) error {
+	if len(args)%2 != 0 {
+		return wrongArgs(args[0])
+	}
+	d := c.db()
+	e, err := d.getOrCreate(args[1], kindHash)
+	if err != nil {
+		return err
+	}
+	var n int64
+	for i := 2; i < len(args); i += 2 {
+		if _, ok := e.hash[args[i]]; !ok {
+			n++
+		}
+		e.hash[args[i]] = args[i+1]
+	}
+	d.modified(args[1])
+
+	if strings.EqualFold(args[0], "hmset") {
+		c.w.ok()
+	} else {
+		c.w.int(n)
+	}
+	return nil
+}
+
+// cmdHSetNX HSETNX key field value
+func cmdHSetNX(c *conn, args []string) error {
+	d := c.db()
+	e, err := getHash(d, args[1])
+	if err != nil {
+		return err
+	}
+	if e != nil {
+		if _, ok := e.hash[args[2]]; ok {
+			c.w.int(0)
+			return nil
+		}
+	} else {
+		e, _ = d.getOrCreate(args[1], kindHash)
+	}
+	e.hash[args[2]] = args[3]
+	d.modified(args[1])
+	c.w.int(1)
+	return nil
+}
+
+// cmdHGet HGET key field
+func cmdHGet(c *conn, args []string) error {
+	e, err := getHash(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.null()
+		return nil
+	}
+	value, ok := e.hash[args[2]]
+	if !ok {
+		c.w.null()
+		return nil
+	}
+	c.w.bulk(value)
+	return nil
+}
+
+// cmdHMGet HMGET key field [field ...]
+func cmdHMGet(c *conn, args []string) error {
+	e, err := getHash(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	c.w.array(len(args) - 2)
+	for _, field := range args[2:] {
+		value, ok := "", false
+		if e != nil {
+			value, ok = e.hash[field]
+		}
+		if ok {
+			c.w.bulk(value)
+		} else {
+			c.w.null()
+		}
+	}
+	return nil
+}
+
+// cmdHGetAll HGETALL key，字段按字典序返回
+func cmdHGetAll(c *conn, args []string) error {
+	e, err := getHash(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.strings(nil)
+		return nil
+	}
+	fields := sortedFields(e.hash)
+	c.w.array(len(fields) * 2)
+	for _, field := range fields {
+		c.w.bulk(field)
+		c.w.bulk(e.hash[field])
+	}
+	return nil
+}
+
+// cmdHDel HDEL key field [field ...]
+func cmdHDel(c *conn, args []string) error {
+	d := c.db()
+	e, err := getHash(d, args[1])
+	if err != nil {
+		return err
+	}
+	var n int64
+	if e != nil {
+		for _, field := range args[2:] {
+			if _, ok := e.hash[field]; ok {
+				delete(e.hash, field)
+				n++
+			}
+		}
+	}
+	if n > 0 {
+		d.modified(args[1])
+	}
+	c.w.int(n)
+	return nil
+}
+
+// cmdHExists HEXISTS key field
+func cmdHExists(c *conn, args []string) error {
+	e, err := getHash(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	ok := false
+	if e != nil {
+		_, ok = e.hash[args[2]]
+	}
+	c.w.bool(ok)
+	return nil
+}
+
+// cmdHIncrBy HINCRBY key field increment
+func cmdHIncrBy(c *conn, args []string) error {
+	delta, err := parseInt(args[3])
+	if err != nil {
+		return err
+	}
+	d := c.db()
+	e, err := d.getOrCreate(args[1], kindHash)
+	if err != nil {
+		return err
+	}
+
+	var value int64
+	if s, ok := e.hash[args[2]]; ok {
+		if value, err = strconv.ParseInt(s, 10, 64); err != nil {
+			d.modified(args[1])
+			return errors.New("ERR hash value is not an integer")
+		}
+	}
+	if (delta > 0 && value > math.MaxInt64-delta) || (delta < 0 && value < math.MinInt64-delta) {
+		d.modified(args[1])
+		return errors.New("ERR increment or decrement would overflow")
+	}
+	value += delta
+	e.hash[args[2]] = strconv.FormatInt(value, 10)
+	d.modified(args[1])
+	c.w.int(value)
+	return nil
+}
+
+// cmdHIncrByFloat HINCRBYFLOAT key field increment
+func cmdHIncrByFloat(c *conn, args []string) error {
+	delta, err := parseFloat(args[3])
+	if err != nil {
+		return err
+	}
+	d := c.db()
+	e, err := d.getOrCreate(args[1], kindHash)
+	if err != nil {
+		return err
+	}
+
+	var value float64
+	if s, ok := e.hash[args[2]]; ok {
+		if value, err = parseFloat(s); err != nil {
+			d.modified(args[1])
+			return errors.New("ERR hash value is not a float")
+		}
+	}
+	value += delta
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		d.modified(args[1])
+		return errors.New("ERR increment would produce NaN or Infinity")
+	}
+	e.hash[args[2]] = formatFloat(value)
+	d.modified(args[1])
+	c.w.bulk(e.hash[args[2]])
+	return nil
+}
+
+// cmdHKeys HKEYS key
+func cmdHKeys(c *conn, args []string) error {
+	e, err := getHash(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.strings(nil)
+		return nil
+	}
+	c.w.strings(sortedFields(e.hash))
+	return nil
+}
+
+// cmdHVals HVALS key，按字段的字典序返回值
+func cmdHVals(c *conn, args []string) error {
+	e, err := getHash(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.strings(nil)
+		return nil
+	}
+	fields := sortedFields(e.hash)
+	values := make([]string, len(fields))
+	for i, field := range fields {
+		values[i] = e.hash[field]
+	}
+	c.w.strings(values)
+	return nil
+}
+
+// cmdHLen HLEN key
+func cmdHLen(c *conn, args []string) error {
+	e, err := getHash(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.int(0)
+		return nil
+	}
+	c.w.int(int64(len(e.hash)))
+	return nil
+}
+
+// cmdHStrlen HSTRLEN key field
+func cmdHStrlen(c *conn, args []string) error {
+	e, err := getHash(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.int(0)
+		return nil
+	}
+	c.w.int(int64(len(e.hash[args[2]])))
+	return nil
+}
+
+// cmdHScan HSCAN key cursor [MATCH pattern] [COUNT count]
+func cmdHScan(c *conn, args []string) error {
+	e, err := getHash(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	var fields, values []string
+	if e != nil {
+		fields = sortedFields(e.hash)
+		values = make([]string, len(fields))
+		for i, field := range fields {
+			values[i] = e.hash[field]
+		}
+	}
+	return c.scanReply(args[2], args[3:], fields, values)
+}
@@ -0,0 +1,545 @@
+package redistest
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+// listCommands 列表命令
+var listCommands = map[string]command{
+	"lpush":      {fn: cmdPush, arity: -3},
+	"rpush":      {fn: cmdPush, arity: -3},
+	"lpushx":     {fn: cmdPush, arity: -3},
+	"rpushx":     {fn: cmdPush, arity: -3},
+	"lpop":       {fn: cmdPop, arity: -2},
+	"rpop":       {fn: cmdPop, arity: -2},
+	"llen":       {fn: cmdLLen, arity: 2},
+	"lrange":     {fn: cmdLRange, arity: 4},
+	"lindex":     {fn: cmdLIndex, arity: 3},
+	"lset":       {fn: cmdLSet, arity: 4},
+	"lrem":       {fn: cmdLRem, arity: 4},
+	"ltrim":      {fn: cmdLTrim, arity: 4},
+	"linsert":    {fn: cmdLInsert, arity: 5},
+	"lpos":       {fn: cmdLPos, arity: -3},
+	"blpop":      {fn: cmdBPop, arity: -3},
+	"brpop":      {fn: cmdBPop, arity: -3},
+	"rpoplpush":  {fn: cmdRPopLPush, arity: 3},
+	"brpoplpush": {fn: cmdBRPopLPush, arity: 4},
+	"lmove":      {fn: cmdLMove, arity: 5},
+	"blmove":     {fn: cmdBLMove, arity: 6},
+}
+
+// getList 获取列表类型的键值
+func getList(d *db, key string) (*entry, error) {
+	return d.getKind(key, kindList)
+}
+
+// parseSide 解析 LEFT 或 RIGHT，返回是否为 LEFT
+func parseSide(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "left":
+		return true, nil
+	case "right":
+		return false, nil
+	default:
+		return false, errSyntax
+	}
+}
+
+// pop 从列表头部或尾部弹出元素，调用方负责 modified
+func (e *entry) pop(left bool) string {
+	var value string
+	if left {
+		value, e.list = e.list[0], e.list[1:]
+	} else {
+		last := len(e.list) - 1
+		value, e.list = e.list[last], e.list[:last]
+	}
+	return value
+}
+
+// push 向列表头部或尾部追加元素
+func (e *entry) push(left bool, values ...string) {
+	if !left {
+		e.list = append(e.list, values...)
+		return
+	}
+	head := make([]string, 0, len(values)+len(e.list))
+	for i := len(values) - 1; i >= 0; i-- {
+		head = append(head, values[i])
+	}
+	e.list = append(head, e.list...)
+}
+
+// cmdPush LPUSH/RPUSH/LPUSHX/RPUSHX key element [element ...]
+func cmdPush(c *conn, args []string) error {
+	name := strings.ToLower(args[0])
+	d := c.db()
+	e, err := getList(d, args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		if strings.HasSuffix(name, "x") {
+			c.w.int(0)
+			return nil
+		}
+		e, _ = d.getOrCreate(args[1], kindList)
+	}
+
+	e.push(name[0] == 'l', args[2:]...)
+	d.modified(args[1])
+	c.w.int(int64(len(e.list)))
+	return nil
+}
+
+// cmdPop LPOP/RPOP key [count]
+func cmdPop(c *conn, args []string) error {
+	if len(args) > 3 {
+		return wrongArgs(args[0])
+	}
+	count := int64(-1)
+	if len(args) == 3 {
+		var err error
+		if count, err = parseInt(args[2]); err != nil || count < 0 {
+			return errors.New("ERR value is out of range, must be positive")
+		}
+	}
+
+	d := c.db()
+	e, err := getList(d, args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		if count < 0 {
+			c.w.null()
+		} else {
+			c.w.nullArray()
+		}
+		return nil
+	}
+
+	left := strings.EqualFold(args[0], "lpop")
+	if count < 0 {
+		c.w.bulk(e.pop(left))
+		d.modified(args[1])
+		return nil
+	}
+
+	values := make([]string, 0, min(int(count), len(e.list)))
+	for len(values) < cap(values) {
+		values = append(values, e.pop(left))
+	}
+	d.modified(args[1])
+	c.w.strings(values)
+	return nil
+}
+
+// cmdLLen LLEN key
+func cmdLLen(c *conn, args []string) error {
+	e, err := getList(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.int(0)
+		return nil
+	}
+	c.w.int(int64(len(e.list)))
+	return nil
+}
+
+// cmdLRange LRANGE key start stop
+func cmdLRange(c *conn, args []string) error {
+	start, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+	stop, err := parseInt(args[3])
+	if err != nil {
+		return err
+	}
+	e, err := getList(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.strings(nil)
+		return nil
+	}
+
+	s, t, ok := normalizeRange(start, stop, int64(len(e.list)))
+	if !ok {
+		c.w.strings(nil)
+		return nil
+	}
+	c.w.strings(e.list[s : t+1])
+	return nil
+}
+
+// listIndex 将可为负数的下标转换为列表下标，越界时返回 false
+func listIndex(index int64, length int) (int, bool) {
+	if index < 0 {
+		index += int64(length)
+	}
+	if index < 0 || index >= int64(length) {
+		return 0, false
+	}
+	return int(index), true
+}
+
+// cmdLIndex LINDEX key index
+func cmdLIndex(c *conn, args []string) error {
+	index, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+	e, err := getList(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.null()
+		return nil
+	}
+	i, ok := listIndex(index, len(e.list))
+	if !ok {
+		c.w.null()
+		return nil
+	}
+	c.w.bulk(e.list[i])
+	return nil
+}
+
+// cmdLSet LSET key index element
+func cmdLSet(c *conn, args []string) error {
+	index, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+	d := c.db()
+	e, err := getList(d, args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		return errNoSuchKey
+	}
+	i, ok := listIndex(index, len(e.list))
+	if !ok {
+		return errOutOfRange
+	}
+	e.list[i] = args[3]
+	d.modified(args[1])
+	c.w.ok()
+	return nil
+}
+
+// cmdLRem LREM key count element
+func cmdLRem(c *conn, args []string) error {
+	count, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+	d := c.db()
+	e, err := getList(d, args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.int(0)
+		return nil
+	}
+
+	limit := count
+	if limit < 0 {
+		limit = -limit
+	}
+	list := slices.Clone(e.list)
+	if count < 0 {
+		slices.Reverse(list)
+	}
+
+	kept := make([]string, 0, len(list))
+	var removed int64
+	for _, v := range list {
+		if v == args[3] && (limit == 0 || removed < limit) {
+			removed++
+			continue
+		}
+		kept = append(kept, v)
+	}
+	if count < 0 {
+		slices.Reverse(kept)
+	}
+
+	if removed > 0 {
+		e.list = kept
+		d.modified(args[1])
+	}
+	c.w.int(removed)
+	return nil
+}
+
+// cmdLTrim LTRIM key start stop
+func cmdLTrim(c *conn, args []string) error {
+	start, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+	stop, err := parseInt(args[3])
+	if err != nil {
+		return err
+	}
+	d := c.db()
+	e, err := getList(d, args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.ok()
+		return nil
+	}
+
+	s, t, ok := normalizeRange(start, stop, int64(len(e.list)))
+	if ok {
+		e.list = slices.Clone(e.list[s : t+1])
+	} else {
+		e.list = nil
+	}
+	d.modified(args[1])
+	c.w.ok()
+	return nil
+}
+
+// cmdLInsert LINSERT key BEFORE|AFTER pivot element
+func cmdLInsert(c *conn, args []string) error {
+	var after bool
+	switch strings.ToLower(args[2]) {
+	case "before":
+	case "after":
+		after = true
+	default:
+		return errSyntax
+	}
+
+	d := c.db()
+	e, err := getList(d, args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.int(0)
+		return nil
+	}
+
+	i := slices.Index(e.list, args[3])
+	if i < 0 {
+		c.w.int(-1)
+		return nil
+	}
+	if after {
+		i++
+	}
+	e.list = slices.Insert(e.list, i, args[4])
+	d.modified(args[1])
+	c.w.int(int64(len(e.list)))
+	return nil
+}
+
+// cmdLPos LPOS key element [RANK rank] [COUNT num-matches] [MAXLEN len]
+func cmdLPos(c *conn, args []string) error {
+	rank, count, maxLen := int64(1), int64(-1), int64(0)
+	for i := 3; i < len(args); i += 2 {
+		if i+1 >= len(args) {
+			return errSyntax
+		}
+		value, err := parseInt(args[i+1])
+		if err != nil {
+			return err
+		}
+		switch strings.ToLower(args[i]) {
+		case "rank":
+			if value == 0 {
+				return errors.New("ERR RANK can't be zero: use 1 to start from the first match, 2 from the second ... or use negative to start from the end of the list")
+			}
+			rank = value
+		case "count":
+			if value < 0 {
+				return errors.New("ERR COUNT can't be negative")
+			}
+			count = value
+		case "maxlen":
+			if value < 0 {
+				return errors.New("ERR MAXLEN can't be negative")
+			}
+			maxLen = value
+		default:
+			return errSyntax
+		}
+	}
+
+	e, err := getList(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+
+	var positions []int64
+	if e != nil {
+		n := len(e.list)
+		skip := rank - 1
+		step, i := 1, 0
+		if rank < 0 {
+			skip = -rank - 1
+			step, i = -1, n-1
+		}
+		for scanned := int64(0); i >= 0 && i < n; i, scanned = i+step, scanned+1 {
+			if maxLen > 0 && scanned >= maxLen {
+				break
+			}
+			if e.list[i] != args[2] {
+				continue
+			}
+			if skip > 0 {
+				skip--
+				continue
+			}
+			positions = append(positions, int64(i))
+			// 未指定 COUNT 时只返回第一个匹配，COUNT 0 返回全部匹配
+			if count < 0 || (count > 0 && int64(len(positions)) >= count) {
+				break
+			}
+		}
+	}
+
+	if count < 0 {
+		if len(positions) == 0 {
+			c.w.null()
+		} else {
+			c.w.int(positions[0])
+		}
+		return nil
+	}
+	c.w.array(len(positions))
+	for _, pos := range positions {
+		c.w.int(pos)
+	}
+	return nil
+}
+
+// move 从源列表弹出元素并推入目标列表，源列表不存在时返回 false
+func move(d *db, src, dst string, fromLeft, toLeft bool) (string, bool, error) {
+	e, err := getList(d, src)
+	if err != nil {
+		return "", false, err
+	}
+	if _, err := getList(d, dst); err != nil {
+		return "", false, err
+	}
+	if e == nil {
+		return "", false, nil
+	}
+
+	value := e.pop(fromLeft)
+	d.modified(src)
+	target, _ := d.getOrCreate(dst, kindList)
+	target.push(toLeft, value)
+	d.modified(dst)
+	return value, true, nil
+}
+
+// cmdRPopLPush RPOPLPUSH source destination
+func cmdRPopLPush(c *conn, args []string) error {
+	return c.move(args[1], args[2], false, true, false, "")
+}
+
+// cmdBRPopLPush BRPOPLPUSH source destination timeout
+func cmdBRPopLPush(c *conn, args []string) error {
+	return c.move(args[1], args[2], false, true, true, args[3])
+}
+
+// cmdLMove LMOVE source destination LEFT|RIGHT LEFT|RIGHT
+func cmdLMove(c *conn, args []string) error {
+	fromLeft, err := parseSide(args[3])
+	if err != nil {
+		return err
+	}
+	toLeft, err := parseSide(args[4])
+	if err != nil {
+		return err
+	}
+	return c.move(args[1], args[2], fromLeft, toLeft, false, "")
+}
+
+// cmdBLMove BLMOVE source destination LEFT|RIGHT LEFT|RIGHT timeout
+func cmdBLMove(c *conn, args []string) error {
+	fromLeft, err := parseSide(args[3])
+	if err != nil {
+		return err
+	}
+	toLeft, err := parseSide(args[4])
+	if err != nil {
+		return err
+	}
+	return c.move(args[1], args[2], fromLeft, toLeft, true, args[5])
+}
+
+// move 执行 (B)RPOPLPUSH/(B)LMOVE，blocking 为 true 时在源列表为空时阻塞等待
+func (c *conn) move(src, dst string, fromLeft, toLeft, blocking bool, timeout string) error {
+	var until time.Time
+	if blocking {
+		var err error
+		if until, err = deadline(timeout); err != nil {
+			return err
+		}
+	}
+
+	for {
+		value, ok, err := move(c.db(), src, dst, fromLeft, toLeft)
+		if err != nil {
+			return err
+		}
+		if ok {
+			c.w.bulk(value)
+			return nil
+		}
+		if !blocking || !c.wait(until) {
+			c.w.null()
+			return nil
+		}
+	}
+}
+
+// cmdBPop BLPOP/BRPOP key [key ...] timeout，按键的顺序弹出第一个非空列表
+func cmdBPop(c *conn, args []string) error {
+	until, err := deadline(args[len(args)-1])
+	if err != nil {
+		return err
+	}
+	keys := args[1 : len(args)-1]
+	left := strings.EqualFold(args[0], "blpop")
+
+	for {
+		d := c.db()
+		for _, key := range keys {
+			e, err := getList(d, key)
+			if err != nil {
+				return err
+			}
+			if e == nil {
+				continue
+			}
+			value := e.pop(left)
+			d.modified(key)
+			c.w.strings([]string{key, value})
+			return nil
+		}
+		if !c.wait(until) {
+			c.w.nullArray()
+			return nil
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package redistest
+
+import (
+	"fmt"
+	"sort"
+)
+
+// pubsubCommands 发布订阅命令
+var pubsubCommands = map[string]command{
+	"subscribe":    {fn: cmdSubscribe, arity: -2},
+	"unsubscribe":  {fn: cmdUnsubscribe, arity: -1},
+	"psubscribe":   {fn: cmdPSubscribe, arity: -2},
+	"punsubscribe": {fn: cmdPUnsubscribe, arity: -1},
+	"publish":      {fn: cmdPublish, arity: 3},
+}
+
+// subscribeAllowed 订阅状态下允许执行的命令
+var subscribeAllowed = map[string]bool{
+	"subscribe":    true,
+	"unsubscribe":  true,
+	"psubscribe":   true,
+	"punsubscribe": true,
+	"ping":         true,
+	"quit":         true,
+}
+
+// subscriptions 频道或模式到订阅连接的映射
+type subscriptions map[string]map[*conn]struct{}
+
+// add 添加订阅
+func (s subscriptions) add(name string, c *conn) {
+	conns, ok := s[name]
+	if !ok {
+		conns = make(map[*conn]struct{})
+		s[name] = conns
+	}
+	conns[c] = struct{}{}
+}
+
+// remove 移除订阅
+func (s subscriptions) remove(name string, c *conn) {
+	delete(s[name], c)
+	if len(s[name]) == 0 {
+		delete(s, name)
+	}
+}
+
+// subscribed 连接是否处于订阅状态
+func (c *conn) subscribed() bool {
+	return len(c.channels)+len(c.patterns) > 0
+}
+
+// subscriptionCount 连接订阅的频道和模式总数
+func (c *conn) subscriptionCount() int64 {
+	return int64(len(c.channels) + len(c.patterns))
+}
+
+// unsubscribeAll 取消连接的所有订阅，连接关闭时调用（调用方持有 server.mu）
+func (c *conn) unsubscribeAll() {
+	for channel := range c.channels {
+		c.server.channels.remove(channel, c)
+	}
+	for pattern := range c.patterns {
+		c.server.patterns.remove(pattern, c)
+	}
+	c.channels, c.patterns = nil, nil
+}
+
+// subscriptionReply 写入订阅确认 [kind, name, count]
+func (c *conn) subscriptionReply(kind string, name *string) {
+	c.w.array(3)
+	c.w.bulk(kind)
+	if name == nil {
+		c.w.null()
+	} else {
+		c.w.bulk(*name)
+	}
+	c.w.int(c.subscriptionCount())
+}
+
+// cmdSubscribe SUBSCRIBE channel [channel ...]
+func cmdSubscribe(c *conn, args []string) error {
+	if c.channels == nil {
+		c.channels = make(map[string]struct{})
+	}
+	for _, channel := range args[1:] {
+		c.channels[channel] = struct{}{}
+		c.server.channels.add(channel, c)
+		c.subscriptionReply("subscribe", &channel)
+	}
+	return nil
+}
+
+// cmdUnsubscribe UNSUBSCRIBE [channel ...]，不指定频道时取消所有频道订阅
+func cmdUnsubscribe(c *conn, args []string) error {
+	channels := args[1:]
+	if len(channels) == 0 {
+		channels = sortedNames(c.channels)
+	}
+	if len(channels) == 0 {
+		c.subscriptionReply("unsubscribe", nil)
+		return nil
+	}
+	for _, channel := range channels {
+		delete(c.channels, channel)
+		c.server.channels.remove(channel, c)
+		c.subscriptionReply("unsubscribe", &channel)
+	}
+	return nil
+}
+
+// cmdPSubscribe PSUBSCRIBE pattern [pattern ...]
+func cmdPSubscribe(c *conn, args []string) error {
+	if c.patterns == nil {
+		c.patterns = make(map[string]struct{})
+	}
+	for _, pattern := range args[1:] {
+		c.patterns[pattern] = struct{}{}
+		c.server.patterns.add(pattern, c)
+		c.subscriptionReply("psubscribe", &pattern)
+	}
+	return nil
+}
+
+// cmdPUnsubscribe PUNSUBSCRIBE [pattern ...]，不指定模式时取消所有模式订阅
+func cmdPUnsubscribe(c *conn, args []string) error {
+	patterns := args[1:]
+	if len(patterns) == 0 {
+		patterns = sortedNames(c.patterns)
+	}
+	if len(patterns) == 0 {
+		c.subscriptionReply("punsubscribe", nil)
+		return nil
+	}
+	for _, pattern := range patterns {
+		delete(c.patterns, pattern)
+		c.server.patterns.remove(pattern, c)
+		c.subscriptionReply("punsubscribe", &pattern)
+	}
+	return nil
+}
+
+// cmdPublish PUBLISH channel message，返回接收到消息的订阅数量
+func cmdPublish(c *conn, args []string) error {
+	channel, message := args[1], args[2]
+
+	var count int64
+	for sub := range c.server.channels[channel] {
+		sub.w.push([]string{"message", channel, message})
+		count++
+	}
+	for pattern, conns := range c.server.patterns {
+		if !globMatch(pattern, channel) {
+			continue
+		}
+		for sub := range conns {
+			sub.w.push([]string{"pmessage", pattern, channel, message})
+			count++
+		}
+	}
+
+	c.w.int(count)
+	return nil
+}
+
+// subscribeModeError 订阅状态下执行不允许的命令时返回的错误
+func subscribeModeError(name string) error {
+	return fmt.Errorf("ERR Can't execute '%s': only (P)SUBSCRIBE / (P)UNSUBSCRIBE / PING / QUIT are allowed in this context", name)
+}
+
+// sortedNames 返回排序后的订阅名称
+func sortedNames(names map[string]struct{}) []string {
+	result := make([]string, 0, len(names))
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// pong 订阅状态下的 PING 回复 ["pong", message]
+func (c *conn) pong(args []string) {
+	message := ""
+	if len(args) > 1 {
+		message = args[1]
+	}
+	c.w.strings([]string{"pong", message})
+}
@@ -0,0 +1,360 @@
+package redistest
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// scriptCommands 脚本命令
+var scriptCommands = map[string]command{
+	"eval":       {fn: cmdEval, arity: -3},
+	"evalsha":    {fn: cmdEvalSHA, arity: -3},
+	"eval_ro":    {fn: cmdEval, arity: -3},
+	"evalsha_ro": {fn: cmdEvalSHA, arity: -3},
+	"script":     {fn: cmdScript, arity: -2},
+}
+
+// scriptDenied 脚本中不允许调用的命令
+var scriptDenied = map[string]bool{
+	"multi": true, "exec": true, "discard": true, "watch": true, "unwatch": true,
+	"subscribe": true, "unsubscribe": true, "psubscribe": true, "punsubscribe": true,
+	"eval": true, "evalsha": true, "eval_ro": true, "evalsha_ro": true, "script": true,
+	"quit": true,
+}
+
+// sha1hex 计算脚本的 SHA1 摘要（小写十六进制）
+func sha1hex(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// cmdEval EVAL script numkeys [key ...] [arg ...]，编译后的脚本按 SHA1 缓存
+func cmdEval(c *conn, args []string) error {
+	sha := sha1hex(args[1])
+	proto, err := c.server.loadScript(sha, args[1])
+	if err != nil {
+		return err
+	}
+	return c.runScript(sha, proto, args[2:])
+}
+
+// cmdEvalSHA EVALSHA sha1 numkeys [key ...] [arg ...]
+func cmdEvalSHA(c *conn, args []string) error {
+	sha := strings.ToLower(args[1])
+	proto, ok := c.server.scripts[sha]
+	if !ok {
+		return errors.New("NOSCRIPT No matching script. Please use EVAL.")
+	}
+	return c.runScript(sha, proto, args[2:])
+}
+
+// cmdScript SCRIPT LOAD|EXISTS|FLUSH|KILL
+func cmdScript(c *conn, args []string) error {
+	switch strings.ToLower(args[1]) {
+	case "load":
+		if len(args) != 3 {
+			return errWrongArgs("script|load")
+		}
+		sha := sha1hex(args[2])
+		if _, err := c.server.loadScript(sha, args[2]); err != nil {
+			return err
+		}
+		c.w.bulk(sha)
+	case "exists":
+		if len(args) < 3 {
+			return errWrongArgs("script|exists")
+		}
+		c.w.array(len(args) - 2)
+		for _, sha := range args[2:] {
+			_, ok := c.server.scripts[strings.ToLower(sha)]
+			c.w.bool(ok)
+		}
+	case "flush":
+		c.server.scripts = make(map[string]*luaProto)
+		c.w.ok()
+	case "kill":
+		return errors.New("NOTBUSY No scripts in execution right now.")
+	default:
+		return fmt.Errorf("ERR unknown subcommand '%s'. Try SCRIPT HELP.", args[1])
+	}
+	return nil
+}
+
+// errWrongArgs 参数数量错误
+func errWrongArgs(name string) error {
+	return fmt.Errorf("ERR wrong number of arguments for '%s' command", name)
+}
+
+// loadScript 编译并缓存脚本，已缓存时直接返回
+func (s *Server) loadScript(sha, src string) (*luaProto, error) {
+	if proto, ok := s.scripts[sha]; ok {
+		return proto, nil
+	}
+	proto, err := compileLua(src)
+	if err != nil {
+		return nil, fmt.Errorf("ERR Error compiling script (new function): %v", err)
+	}
+	s.scripts[sha] = proto
+	return proto, nil
+}
+
+// runScript 解析 numkeys 后执行脚本并写入回复
+// 脚本在持有 server.mu 时执行，期间不会穿插其他连接的命令
+func (c *conn) runScript(sha string, proto *luaProto, args []string) error {
+	numKeys, err := strconv.Atoi(args[0])
+	if err != nil {
+		return errNotInteger
+	}
+	if numKeys < 0 {
+		return errors.New("ERR Number of keys can't be negative")
+	}
+	if numKeys > len(args)-1 {
+		return errors.New("ERR Number of keys can't be greater than number of args")
+	}
+
+	run := &scriptRun{
+		conn: &conn{server: c.server, dbIndex: c.dbIndex, inExec: true},
+	}
+	run.conn.w = &writer{w: bufio.NewWriter(&run.buf)}
+
+	globals := luaLibrary()
+	globals["KEYS"] = luaStrings(args[1 : 1+numKeys])
+	globals["ARGV"] = luaStrings(args[1+numKeys:])
+	globals["redis"] = run.library()
+	it := &luaInterp{globals: readonlyTable(globals)}
+
+	result, err := it.run(proto)
+	if err != nil {
+		var le *luaError
+		if !errors.As(err, &le) {
+			return err
+		}
+		msg := luaErrorMessage(le.value)
+		if _, ok := le.value.(*luaTable); !ok || !hasErrorCode(msg) {
+			msg = "ERR " + msg
+		}
+		return fmt.Errorf("%s script: %s, on @user_script:%d.", msg, sha, it.line)
+	}
+	writeLuaReply(c.w, result, 0)
+	return nil
+}
+
+// hasErrorCode 错误信息是否以大写的错误类型开头（如 ERR、WRONGTYPE）
+func hasErrorCode(msg string) bool {
+	code, _, _ := strings.Cut(msg, " ")
+	return code != "" && strings.ToUpper(code) == code
+}
+
+// luaStrings 将字符串列表转换为 Lua 数组
+func luaStrings(values []string) *luaTable {
+	t := newLuaTable()
+	for i, v := range values {
+		t.set(float64(i+1), v)
+	}
+	return t
+}
+
+// run 执行脚本主体，返回第一个返回值；脚本错误以 *luaError 返回
+func (it *luaInterp) run(proto *luaProto) (result luaValue, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			le, ok := r.(*luaError)
+			if !ok {
+				panic(r)
+			}
+			err = le
+		}
+	}()
+	values := it.call(&luaClosure{proto: proto}, nil)
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return values[0], nil
+}
+
+// Error 实现 error 接口
+func (e *luaError) Error() string {
+	return luaErrorMessage(e.value)
+}
+
+// scriptRun 一次脚本执行，redis.call 在内部连接上执行命令
+type scriptRun struct {
+	conn *conn
+	buf  bytes.Buffer
+}
+
+// library 创建脚本中的 redis 表
+func (r *scriptRun) library() *luaTable {
+	return readonlyTable(map[string]luaValue{
+		"call": goFunc("call", func(it *luaInterp, args []luaValue) []luaValue {
+			v := r.call(it, args)
+			if t, ok := v.(*luaTable); ok && t.get("err") != nil {
+				panic(&luaError{value: t})
+			}
+			return []luaValue{v}
+		}),
+		"pcall": goFunc("pcall", func(it *luaInterp, args []luaValue) []luaValue {
+			return []luaValue{r.call(it, args)}
+		}),
+		"error_reply": goFunc("error_reply", func(it *luaInterp, args []luaValue) []luaValue {
+			return []luaValue{replyTable("err", checkString(it, args, 1, "error_reply"))}
+		}),
+		"status_reply": goFunc("status_reply", func(it *luaInterp, args []luaValue) []luaValue {
+			return []luaValue{replyTable("ok", checkString(it, args, 1, "status_reply"))}
+		}),
+		"sha1hex": goFunc("sha1hex", func(it *luaInterp, args []luaValue) []luaValue {
+			return []luaValue{sha1hex(checkString(it, args, 1, "sha1hex"))}
+		}),
+		"log":                goFunc("log", func(*luaInterp, []luaValue) []luaValue { return nil }),
+		"replicate_commands": goFunc("replicate_commands", func(*luaInterp, []luaValue) []luaValue { return []luaValue{true} }),
+		"LOG_DEBUG":          0.0,
+		"LOG_VERBOSE":        1.0,
+		"LOG_NOTICE":         2.0,
+		"LOG_WARNING":        3.0,
+	})
+}
+
+// replyTable 创建 {err=...} 或 {ok=...} 形式的回复表
+func replyTable(field, msg string) *luaTable {
+	t := newLuaTable()
+	t.set(field, msg)
+	return t
+}
+
+// call 执行 redis.call / redis.pcall 的命令，错误以 {err=...} 表返回
+func (r *scriptRun) call(it *luaInterp, args []luaValue) luaValue {
+	if len(args) == 0 {
+		it.raise("Please specify at least one argument for this redis lib call")
+	}
+	cmdArgs := make([]string, len(args))
+	for i, v := range args {
+		switch x := v.(type) {
+		case string:
+			cmdArgs[i] = x
+		case float64:
+			if x == float64(int64(x)) {
+				cmdArgs[i] = strconv.FormatInt(int64(x), 10)
+			} else {
+				cmdArgs[i] = strconv.FormatFloat(x, 'g', -1, 64)
+			}
+		default:
+			it.raise("Lua redis lib command arguments must be strings or integers")
+		}
+	}
+
+	name := strings.ToLower(cmdArgs[0])
+	cmd, ok := commands[name]
+	switch {
+	case !ok:
+		return replyTable("err", "ERR Unknown Redis command called from script")
+	case scriptDenied[name]:
+		return replyTable("err", "ERR This Redis command is not allowed from script")
+	case (cmd.arity > 0 && len(cmdArgs) != cmd.arity) || (cmd.arity < 0 && len(cmdArgs) < -cmd.arity):
+		return replyTable("err", "ERR Wrong number of args calling Redis command from script")
+	}
+
+	if err := cmd.fn(r.conn, cmdArgs); err != nil {
+		r.conn.w.error(err)
+	}
+	_ = r.conn.w.flush()
+	v, err := readLuaReply(bufio.NewReader(&r.buf))
+	r.buf.Reset()
+	if err != nil {
+		panic(fmt.Sprintf("redistest: malformed reply from %s: %v", name, err))
+	}
+	return v
+}
+
+// readLuaReply 将 RESP2 回复转换为 Lua 值：状态和错误转换为 {ok=...} 和 {err=...} 表，空回复转换为 false
+func readLuaReply(r *bufio.Reader) (luaValue, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if line == "" {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	switch line[0] {
+	case '+':
+		return replyTable("ok", line[1:]), nil
+	case '-':
+		return replyTable("err", line[1:]), nil
+	case ':':
+		n, err := strconv.ParseInt(line[1:], 10, 64)
+		return float64(n), err
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return false, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n < 0 {
+			return false, err
+		}
+		t := newLuaTable()
+		for i := 1; i <= n; i++ {
+			v, err := readLuaReply(r)
+			if err != nil {
+				return nil, err
+			}
+			t.set(float64(i), v)
+		}
+		return t, nil
+	default:
+		return nil, fmt.Errorf("unexpected reply type %q", line[0])
+	}
+}
+
+// writeLuaReply 将脚本返回值写入回复：数值截断为整数，true 为 1，false 和 nil 为空回复，
+// {err=...} 和 {ok=...} 为错误和状态回复，其他表为数组（到第一个 nil 为止）
+func writeLuaReply(w *writer, v luaValue, depth int) {
+	switch x := v.(type) {
+	case float64:
+		w.int(int64(x))
+	case string:
+		w.bulk(x)
+	case bool:
+		if x {
+			w.int(1)
+		} else {
+			w.null()
+		}
+	case *luaTable:
+		if msg, ok := x.get("err").(string); ok {
+			w.error(errors.New(msg))
+			return
+		}
+		if msg, ok := x.get("ok").(string); ok {
+			w.simple(msg)
+			return
+		}
+		if depth > luaMaxDepth {
+			w.error(errors.New("ERR reached lua stack limit"))
+			return
+		}
+		n := 0
+		for x.get(float64(n+1)) != nil {
+			n++
+		}
+		w.array(n)
+		for i := 1; i <= n; i++ {
+			writeLuaReply(w, x.get(float64(i)), depth+1)
+		}
+	default:
+		w.null()
+	}
+}
@@ -0,0 +1,392 @@
+package redistest
+
+import (
+	"errors"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// setCommands 集合命令
+var setCommands = map[string]command{
+	"sadd":        {fn: cmdSAdd, arity: -3},
+	"srem":        {fn: cmdSRem, arity: -3},
+	"scard":       {fn: cmdSCard, arity: 2},
+	"sismember":   {fn: cmdSIsMember, arity: 3},
+	"smismember":  {fn: cmdSMIsMember, arity: -3},
+	"smembers":    {fn: cmdSMembers, arity: 2},
+	"spop":        {fn: cmdSPop, arity: -2},
+	"srandmember": {fn: cmdSRandMember, arity: -2},
+	"smove":       {fn: cmdSMove, arity: 4},
+	"sdiff":       {fn: cmdSetOp, arity: -2},
+	"sinter":      {fn: cmdSetOp, arity: -2},
+	"sunion":      {fn: cmdSetOp, arity: -2},
+	"sdiffstore":  {fn: cmdSetOpStore, arity: -3},
+	"sinterstore": {fn: cmdSetOpStore, arity: -3},
+	"sunionstore": {fn: cmdSetOpStore, arity: -3},
+	"sscan":       {fn: cmdSScan, arity: -3},
+}
+
+// getSet 获取集合类型的键值
+func getSet(d *db, key string) (*entry, error) {
+	return d.getKind(key, kindSet)
+}
+
+// sortedMembers 获取集合的所有成员（已排序）
+func sortedMembers(set map[string]struct{}) []string {
+	members := make([]string, 0, len(set))
+	for member := range set {
+		members = append(members, member)
+	}
+	sort.Strings(members)
+	return members
+}
+
+// cmdSAdd SADD key member [member ...]
+func cmdSAdd(c *conn, args []string) error {
+	d := c.db()
+	e, err := d.getOrCreate(args[1], kindSet)
+	if err != nil {
+		return err
+	}
+	var n int64
+	for _, member := range args[2:] {
+		if _, ok := e.set[member]; !ok {
+			e.set[member] = struct{}{}
+			n++
+		}
+	}
+	if n > 0 {
+		d.modified(args[1])
+	}
+	c.w.int(n)
+	return nil
+}
+
+// cmdSRem SREM key member [member ...]
+func cmdSRem(c *conn, args []string) error {
+	d := c.db()
+	e, err := getSet(d, args[1])
+	if err != nil {
+		return err
+	}
+	var n int64
+	if e != nil {
+		for _, member := range args[2:] {
+			if _, ok := e.set[member]; ok {
+				delete(e.set, member)
+				n++
+			}
+		}
+	}
+	if n > 0 {
+		d.modified(args[1])
+	}
+	c.w.int(n)
+	return nil
+}
+
+// cmdSCard SCARD key
+func cmdSCard(c *conn, args []string) error {
+	e, err := getSet(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.int(0)
+		return nil
+	}
+	c.w.int(int64(len(e.set)))
+	return nil
+}
+
+// cmdSIsMember SISMEMBER key member
+func cmdSIsMember(c *conn, args []string) error {
+	e, err := getSet(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.bool(false)
+		return nil
+	}
+	_, ok := e.set[args[2]]
+	c.w.bool(ok)
+	return nil
+}
+
+// cmdSMIsMember SMISMEMBER key member [member ...]
+func cmdSMIsMember(c *conn, args []string) error {
+	e, err := getSet(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	c.w.array(len(args) - 2)
+	for _, member := range args[2:] {
+		ok := false
+		if e != nil {
+			_, ok = e.set[member]
+		}
+		c.w.bool(ok)
+	}
+	return nil
+}
+
+// cmdSMembers SMEMBERS key，成员按字典序返回
+func cmdSMembers(c *conn, args []string) error {
+	e, err := getSet(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.strings(nil)
+		return nil
+	}
+	c.w.strings(sortedMembers(e.set))
+	return nil
+}
+
+// cmdSPop SPOP key [count]
+func cmdSPop(c *conn, args []string) error {
+	if len(args) > 3 {
+		return errSyntax
+	}
+	count := int64(-1)
+	if len(args) == 3 {
+		var err error
+		if count, err = parseInt(args[2]); err != nil || count < 0 {
+			return errors.New("ERR value is out of range, must be positive")
+		}
+	}
+
+	d := c.db()
+	e, err := getSet(d, args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		if count < 0 {
+			c.w.null()
+		} else {
+			c.w.strings(nil)
+		}
+		return nil
+	}
+
+	members := sortedMembers(e.set)
+	rand.Shuffle(len(members), func(i, j int) { members[i], members[j] = members[j], members[i] })
+	n := 1
+	if count >= 0 {
+		n = min(int(count), len(members))
+	}
+	members = members[:n]
+	for _, member := range members {
+		delete(e.set, member)
+	}
+	d.modified(args[1])
+
+	if count < 0 {
+		c.w.bulk(members[0])
+	} else {
+		c.w.strings(members)
+	}
+	return nil
+}
+
+// cmdSRandMember SRANDMEMBER key [count]，count 为负数时允许重复
+func cmdSRandMember(c *conn, args []string) error {
+	if len(args) > 3 {
+		return errSyntax
+	}
+	e, err := getSet(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 2 {
+		if e == nil {
+			c.w.null()
+			return nil
+		}
+		members := sortedMembers(e.set)
+		c.w.bulk(members[rand.IntN(len(members))])
+		return nil
+	}
+
+	count, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+	if e == nil || count == 0 {
+		c.w.strings(nil)
+		return nil
+	}
+
+	members := sortedMembers(e.set)
+	if count < 0 {
+		values := make([]string, -count)
+		for i := range values {
+			values[i] = members[rand.IntN(len(members))]
+		}
+		c.w.strings(values)
+		return nil
+	}
+	rand.Shuffle(len(members), func(i, j int) { members[i], members[j] = members[j], members[i] })
+	c.w.strings(members[:min(int(count), len(members))])
+	return nil
+}
+
+// cmdSMove SMOVE source destination member
+func cmdSMove(c *conn, args []string) error {
+	d := c.db()
+	src, err := getSet(d, args[1])
+	if err != nil {
+		return err
+	}
+	if _, err := getSet(d, args[2]); err != nil {
+		return err
+	}
+	if src == nil {
+		c.w.int(0)
+		return nil
+	}
+	if _, ok := src.set[args[3]]; !ok {
+		c.w.int(0)
+		return nil
+	}
+
+	delete(src.set, args[3])
+	d.modified(args[1])
+	dst, _ := d.getOrCreate(args[2], kindSet)
+	dst.set[args[3]] = struct{}{}
+	d.modified(args[2])
+	c.w.int(1)
+	return nil
+}
+
+// setOp 计算多个集合的差集、交集或并集，不存在的键视为空集合
+func setOp(d *db, op string, keys []string) (map[string]struct{}, error) {
+	sets := make([]map[string]struct{}, len(keys))
+	for i, key := range keys {
+		e, err := getSet(d, key)
+		if err != nil {
+			return nil, err
+		}
+		if e != nil {
+			sets[i] = e.set
+		}
+	}
+
+	result := make(map[string]struct{})
+	switch op {
+	case "sdiff":
+		for member := range sets[0] {
+			result[member] = struct{}{}
+		}
+		for _, set := range sets[1:] {
+			for member := range set {
+				delete(result, member)
+			}
+		}
+	case "sinter":
+		for member := range sets[0] {
+			in := true
+			for _, set := range sets[1:] {
+				if _, ok := set[member]; !ok {
+					in = false
+					break
+				}
+			}
+			if in {
+				result[member] = struct{}{}
+			}
+		}
+	default:
+		for _, set := range sets {
+			for member := range set {
+				result[member] = struct{}{}
+			}
+		}
+	}
+	return result, nil
+}
+
+// cmdSetOp SDIFF/SINTER/SUNION key [key ...]
+func cmdSetOp(c *conn, args []string) error {
+	result, err := setOp(c.db(), strings.ToLower(args[0]), args[1:])
+	if err != nil {
+		return err
+	}
+	c.w.strings(sortedMembers(result))
+	return nil
+}
+
+// cmdSetOpStore SDIFFSTORE/SINTERSTORE/SUNIONSTORE destination key [key ...]
+func cmdSetOpStore(c *conn, args []string) error {
+	op := strings.TrimSuffix(strings.ToLower(args[0]), "store")
+	d := c.db()
+	result, err := setOp(d, op, args[2:])
+	if err != nil {
+		return err
+	}
+
+	if len(result) == 0 {
+		d.remove(args[1])
+	} else {
+		e := newEntry(kindSet)
+		e.set = result
+		d.set(args[1], e)
+	}
+	c.w.int(int64(len(result)))
+	return nil
+}
+
+// cmdSScan SSCAN key cursor [MATCH pattern] [COUNT count]
+func cmdSScan(c *conn, args []string) error {
+	e, err := getSet(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	var members []string
+	if e != nil {
+		members = sortedMembers(e.set)
+	}
+	return c.scanReply(args[2], args[3:], members, nil)
+}
+
+// scanReply 按游标分页回复 SSCAN/HSCAN/ZSCAN，游标为按成员排序后的偏移量
+// values 不为 nil 时每个成员后附带对应的值
+func (c *conn) scanReply(cursorArg string, opts []string, members, values []string) error {
+	cursor, err := strconv.Atoi(cursorArg)
+	if err != nil || cursor < 0 {
+		return errors.New("ERR invalid cursor")
+	}
+	match, count, _, err := parseScanArgs(opts, false)
+	if err != nil {
+		return err
+	}
+
+	start := min(cursor, len(members))
+	end := min(start+count, len(members))
+	next := end
+	if end >= len(members) {
+		next = 0
+	}
+
+	items := make([]string, 0)
+	for i := start; i < end; i++ {
+		if match != "" && !globMatch(match, members[i]) {
+			continue
+		}
+		items = append(items, members[i])
+		if values != nil {
+			items = append(items, values[i])
+		}
+	}
+
+	c.w.array(2)
+	c.w.bulk(strconv.Itoa(next))
+	c.w.strings(items)
+	return nil
+}
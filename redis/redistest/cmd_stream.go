@@ -0,0 +1,1184 @@
+package redistest
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// streamCommands 流命令
+var streamCommands = map[string]command{
+	"xadd":       {fn: cmdXAdd, arity: -5},
+	"xlen":       {fn: cmdXLen, arity: 2},
+	"xrange":     {fn: cmdXRange, arity: -4},
+	"xrevrange":  {fn: cmdXRange, arity: -4},
+	"xdel":       {fn: cmdXDel, arity: -3},
+	"xtrim":      {fn: cmdXTrim, arity: -4},
+	"xgroup":     {fn: cmdXGroup, arity: -2},
+	"xread":      {fn: cmdXRead, arity: -4},
+	"xreadgroup": {fn: cmdXReadGroup, arity: -7},
+	"xack":       {fn: cmdXAck, arity: -4},
+	"xpending":   {fn: cmdXPending, arity: -3},
+	"xclaim":     {fn: cmdXClaim, arity: -6},
+	"xautoclaim": {fn: cmdXAutoClaim, arity: -6},
+}
+
+var (
+	errInvalidStreamID = errors.New("ERR Invalid stream ID specified as stream command argument")
+	errStreamIDSmall   = errors.New("ERR The ID specified in XADD is equal or smaller than the target stream top item")
+	errStreamIDZero    = errors.New("ERR The ID specified in XADD must be greater than 0-0")
+	errBusyGroup       = errors.New("BUSYGROUP Consumer Group name already exists")
+)
+
+// streamID 流消息ID（毫秒时间戳-序号）
+type streamID struct {
+	ms  uint64
+	seq uint64
+}
+
+// maxStreamID 最大的消息ID
+var maxStreamID = streamID{ms: math.MaxUint64, seq: math.MaxUint64}
+
+// String 返回 ms-seq 格式的ID
+func (id streamID) String() string {
+	return strconv.FormatUint(id.ms, 10) + "-" + strconv.FormatUint(id.seq, 10)
+}
+
+// less 判断ID是否小于另一个ID
+func (id streamID) less(other streamID) bool {
+	return id.ms < other.ms || (id.ms == other.ms && id.seq < other.seq)
+}
+
+// next 返回紧随其后的ID，已是最大ID时返回false
+func (id streamID) next() (streamID, bool) {
+	switch {
+	case id.seq < math.MaxUint64:
+		return streamID{ms: id.ms, seq: id.seq + 1}, true
+	case id.ms < math.MaxUint64:
+		return streamID{ms: id.ms + 1}, true
+	default:
+		return id, false
+	}
+}
+
+// parseStreamID 解析 ms-seq 或 ms 格式的ID，省略序号时使用 seq
+func parseStreamID(s string, seq uint64) (streamID, error) {
+	msPart, seqPart, hasSeq := strings.Cut(s, "-")
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return streamID{}, errInvalidStreamID
+	}
+	if hasSeq {
+		if seq, err = strconv.ParseUint(seqPart, 10, 64); err != nil {
+			return streamID{}, errInvalidStreamID
+		}
+	}
+	return streamID{ms: ms, seq: seq}, nil
+}
+
+// parseRangeID 解析范围查询的起止ID：支持 -、+ 和 ( 开头的开区间，省略序号时起始为0、结束为最大值
+// 返回false表示区间为空（开区间越界）
+func parseRangeID(s string, start bool) (streamID, bool, error) {
+	switch s {
+	case "-":
+		return streamID{}, true, nil
+	case "+":
+		return maxStreamID, true, nil
+	}
+
+	exclusive := strings.HasPrefix(s, "(")
+	s = strings.TrimPrefix(s, "(")
+	var seq uint64
+	if !start {
+		seq = math.MaxUint64
+	}
+	id, err := parseStreamID(s, seq)
+	if err != nil || !exclusive {
+		return id, true, err
+	}
+
+	if start {
+		id, ok := id.next()
+		return id, ok, nil
+	}
+	if id == (streamID{}) {
+		return id, false, nil
+	}
+	if id.seq > 0 {
+		return streamID{ms: id.ms, seq: id.seq - 1}, true, nil
+	}
+	return streamID{ms: id.ms - 1, seq: math.MaxUint64}, true, nil
+}
+
+// streamEntry 流消息
+type streamEntry struct {
+	id     streamID
+	fields []string
+}
+
+// pendingEntry 待处理消息（已投递未确认）
+type pendingEntry struct {
+	consumer  string
+	delivered time.Time // 最近一次投递时间（时钟时间）
+	count     int64     // 投递次数
+}
+
+// streamGroup 消费组
+type streamGroup struct {
+	lastID    streamID
+	pending   map[streamID]*pendingEntry
+	consumers map[string]time.Time // 消费者及其最近活动时间
+}
+
+// stream 流，消息按ID递增排列
+type stream struct {
+	entries []streamEntry
+	lastID  streamID
+	groups  map[string]*streamGroup
+}
+
+// getStream 获取流类型的键值
+func getStream(d *db, key string) (*entry, error) {
+	return d.getKind(key, kindStream)
+}
+
+// find 查找消息的下标，不存在时返回false
+func (s *stream) find(id streamID) (int, bool) {
+	i := sort.Search(len(s.entries), func(i int) bool { return !s.entries[i].id.less(id) })
+	return i, i < len(s.entries) && s.entries[i].id == id
+}
+
+// lookup 查找消息
+func (s *stream) lookup(id streamID) (streamEntry, bool) {
+	i, ok := s.find(id)
+	if !ok {
+		return streamEntry{}, false
+	}
+	return s.entries[i], true
+}
+
+// after 获取ID大于指定ID的消息，count 为0时不限制数量
+func (s *stream) after(id streamID, count int64) []streamEntry {
+	i := sort.Search(len(s.entries), func(i int) bool { return id.less(s.entries[i].id) })
+	entries := s.entries[i:]
+	if count > 0 && int64(len(entries)) > count {
+		entries = entries[:count]
+	}
+	return entries
+}
+
+// nextID 生成新消息ID：与 Redis 一致使用毫秒时间戳，同一毫秒内或时钟回拨时递增序号
+func (s *stream) nextID(now time.Time) streamID {
+	ms := uint64(now.UnixMilli())
+	if ms > s.lastID.ms {
+		return streamID{ms: ms}
+	}
+	id, _ := s.lastID.next()
+	return id
+}
+
+// trim 按 MAXLEN 或 MINID 裁剪流，返回删除的消息数；近似裁剪与精确裁剪行为相同
+func (s *stream) trim(maxLen int64, minID *streamID) int64 {
+	var n int
+	if minID != nil {
+		n = sort.Search(len(s.entries), func(i int) bool { return !s.entries[i].id.less(*minID) })
+	} else if maxLen >= 0 && int64(len(s.entries)) > maxLen {
+		n = len(s.entries) - int(maxLen)
+	}
+	s.entries = s.entries[n:]
+	return int64(n)
+}
+
+// group 获取消费组，流或消费组不存在时返回 NOGROUP 错误
+func (s *stream) group(key, name string) (*streamGroup, error) {
+	if s != nil {
+		if g, ok := s.groups[name]; ok {
+			return g, nil
+		}
+	}
+	return nil, fmt.Errorf("NOGROUP No such key '%s' or consumer group '%s'", key, name)
+}
+
+// pendingIDs 获取消费组中ID在 [start, end] 之间的待处理消息ID（已排序），consumer 为空时不限消费者
+func (g *streamGroup) pendingIDs(start, end streamID, consumer string) []streamID {
+	ids := make([]streamID, 0, len(g.pending))
+	for id, p := range g.pending {
+		if id.less(start) || end.less(id) || (consumer != "" && p.consumer != consumer) {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].less(ids[j]) })
+	return ids
+}
+
+// writeEntry 写入单条消息：[id, [field, value, ...]]
+func (w *writer) writeEntry(e streamEntry) {
+	w.array(2)
+	w.bulk(e.id.String())
+	w.strings(e.fields)
+}
+
+// writeEntries 写入消息数组
+func (w *writer) writeEntries(entries []streamEntry) {
+	w.array(len(entries))
+	for _, e := range entries {
+		w.writeEntry(e)
+	}
+}
+
+// trimArgs 解析 MAXLEN|MINID [=|~] threshold [LIMIT count]，返回消耗的参数数量
+func trimArgs(args []string) (maxLen int64, minID *streamID, n int, err error) {
+	if len(args) < 2 {
+		return 0, nil, 0, errSyntax
+	}
+	strategy := strings.ToLower(args[0])
+	n = 1
+	if args[n] == "=" || args[n] == "~" {
+		n++
+	}
+	if n >= len(args) {
+		return 0, nil, 0, errSyntax
+	}
+	threshold := args[n]
+	n++
+
+	switch strategy {
+	case "maxlen":
+		if maxLen, err = parseInt(threshold); err != nil {
+			return 0, nil, 0, err
+		}
+		if maxLen < 0 {
+			return 0, nil, 0, errors.New("ERR The MAXLEN argument must be >= 0.")
+		}
+	case "minid":
+		id, err := parseStreamID(threshold, 0)
+		if err != nil {
+			return 0, nil, 0, err
+		}
+		minID = &id
+	default:
+		return 0, nil, 0, errSyntax
+	}
+
+	if n+1 < len(args) && strings.EqualFold(args[n], "limit") {
+		if _, err := parseInt(args[n+1]); err != nil {
+			return 0, nil, 0, err
+		}
+		n += 2
+	}
+	return maxLen, minID, n, nil
+}
+
+// cmdXAdd XADD key [NOMKSTREAM] [MAXLEN|MINID [=|~] threshold [LIMIT count]] *|id field value [field value ...]
+func cmdXAdd(c *conn, args []string) error {
+	key := args[1]
+	i := 2
+	noMkStream := false
+	maxLen := int64(-1)
+	var minID *streamID
+options:
+	for i < len(args) {
+		switch strings.ToLower(args[i]) {
+		case "nomkstream":
+			noMkStream = true
+			i++
+		case "maxlen", "minid":
+			var n int
+			var err error
+			if maxLen, minID, n, err = trimArgs(args[i:]); err != nil {
+				return err
+			}
+			i += n
+		default:
+			break options
+		}
+	}
+
+	fields := args[i+1:]
+	if i >= len(args) || len(fields) == 0 || len(fields)%2 != 0 {
+		return wrongArgs(args[0])
+	}
+
+	d := c.db()
+	e, err := getStream(d, key)
+	if err != nil {
+		return err
+	}
+	if e == nil && noMkStream {
+		c.w.null()
+		return nil
+	}
+	if e == nil {
+		e = newEntry(kindStream)
+	}
+	s := e.stream
+
+	var id streamID
+	switch spec := args[i]; {
+	case spec == "*":
+		id = s.nextID(c.server.clock.Now())
+	case strings.HasSuffix(spec, "-*"):
+		ms, err := strconv.ParseUint(strings.TrimSuffix(spec, "-*"), 10, 64)
+		if err != nil {
+			return errInvalidStreamID
+		}
+		id = streamID{ms: ms}
+		if ms == s.lastID.ms {
+			id, _ = s.lastID.next()
+		}
+	default:
+		if id, err = parseStreamID(spec, 0); err != nil {
+			return err
+		}
+	}
+	if id == (streamID{}) {
+		return errStreamIDZero
+	}
+	if !s.lastID.less(id) {
+		return errStreamIDSmall
+	}
+
+	s.entries = append(s.entries, streamEntry{id: id, fields: append([]string(nil), fields...)})
+	s.lastID = id
+	s.trim(maxLen, minID)
+	if d.get(key) == nil {
+		d.set(key, e)
+	} else {
+		d.modified(key)
+	}
+
+	c.w.bulk(id.String())
+	return nil
+}
+
+// cmdXLen XLEN key
+func cmdXLen(c *conn, args []string) error {
+	e, err := getStream(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.int(0)
+		return nil
+	}
+	c.w.int(int64(len(e.stream.entries)))
+	return nil
+}
+
+// cmdXRange XRANGE key start end [COUNT count] / XREVRANGE key end start [COUNT count]
+func cmdXRange(c *conn, args []string) error {
+	rev := strings.EqualFold(args[0], "xrevrange")
+	startArg, endArg := args[2], args[3]
+	if rev {
+		startArg, endArg = endArg, startArg
+	}
+
+	count := int64(-1)
+	if len(args) > 4 {
+		if len(args) != 6 || !strings.EqualFold(args[4], "count") {
+			return errSyntax
+		}
+		var err error
+		if count, err = parseInt(args[5]); err != nil {
+			return err
+		}
+	}
+
+	start, ok1, err := parseRangeID(startArg, true)
+	if err != nil {
+		return err
+	}
+	end, ok2, err := parseRangeID(endArg, false)
+	if err != nil {
+		return err
+	}
+
+	e, err := getStream(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil || !ok1 || !ok2 || count == 0 {
+		c.w.array(0)
+		return nil
+	}
+
+	var entries []streamEntry
+	for _, se := range e.stream.entries {
+		if !se.id.less(start) && !end.less(se.id) {
+			entries = append(entries, se)
+		}
+	}
+	if rev {
+		for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+			entries[i], entries[j] = entries[j], entries[i]
+		}
+	}
+	if count > 0 && int64(len(entries)) > count {
+		entries = entries[:count]
+	}
+	c.w.writeEntries(entries)
+	return nil
+}
+
+// cmdXDel XDEL key id [id ...]
+func cmdXDel(c *conn, args []string) error {
+	ids := make([]streamID, 0, len(args)-2)
+	for _, arg := range args[2:] {
+		id, err := parseStreamID(arg, 0)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+
+	d := c.db()
+	e, err := getStream(d, args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.int(0)
+		return nil
+	}
+
+	var n int64
+	s := e.stream
+	for _, id := range ids {
+		if i, ok := s.find(id); ok {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			n++
+		}
+	}
+	if n > 0 {
+		d.modified(args[1])
+	}
+	c.w.int(n)
+	return nil
+}
+
+// cmdXTrim XTRIM key MAXLEN|MINID [=|~] threshold [LIMIT count]
+func cmdXTrim(c *conn, args []string) error {
+	maxLen, minID, n, err := trimArgs(args[2:])
+	if err != nil {
+		return err
+	}
+	if n != len(args)-2 {
+		return errSyntax
+	}
+
+	d := c.db()
+	e, err := getStream(d, args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.int(0)
+		return nil
+	}
+	removed := e.stream.trim(maxLen, minID)
+	if removed > 0 {
+		d.modified(args[1])
+	}
+	c.w.int(removed)
+	return nil
+}
+
+// cmdXGroup XGROUP CREATE|DESTROY|CREATECONSUMER|DELCONSUMER|SETID key group ...
+func cmdXGroup(c *conn, args []string) error {
+	sub := strings.ToLower(args[1])
+	arity := map[string]int{"create": 5, "destroy": 4, "createconsumer": 5, "delconsumer": 5, "setid": 5}[sub]
+	if arity == 0 {
+		return fmt.Errorf("ERR unknown subcommand '%s'. Try XGROUP HELP.", args[1])
+	}
+	if len(args) < arity {
+		return fmt.Errorf("ERR wrong number of arguments for 'xgroup|%s' command", sub)
+	}
+
+	d := c.db()
+	key, name := args[2], args[3]
+	e, err := getStream(d, key)
+	if err != nil {
+		return err
+	}
+
+	if sub == "create" {
+		mkStream := false
+		for i := 5; i < len(args); i++ {
+			switch {
+			case strings.EqualFold(args[i], "mkstream"):
+				mkStream = true
+			case strings.EqualFold(args[i], "entriesread") && i+1 < len(args):
+				i++
+			default:
+				return errSyntax
+			}
+		}
+		if e == nil {
+			if !mkStream {
+				return errors.New("ERR The XGROUP subcommand requires the key to exist. Note that for CREATE you may want to use the MKSTREAM option to create an empty stream automatically.")
+			}
+			e = newEntry(kindStream)
+			d.set(key, e)
+		}
+		if _, ok := e.stream.groups[name]; ok {
+			return errBusyGroup
+		}
+		id, err := groupStartID(e.stream, args[4])
+		if err != nil {
+			return err
+		}
+		e.stream.groups[name] = &streamGroup{
+			lastID:    id,
+			pending:   make(map[streamID]*pendingEntry),
+			consumers: make(map[string]time.Time),
+		}
+		d.touch(key)
+		c.w.ok()
+		return nil
+	}
+
+	if e == nil {
+		return errors.New("ERR The XGROUP subcommand requires the key to exist. Note that for CREATE you may want to use the MKSTREAM option to create an empty stream automatically.")
+	}
+	if sub == "destroy" {
+		_, ok := e.stream.groups[name]
+		delete(e.stream.groups, name)
+		if ok {
+			d.modified(key)
+		}
+		c.w.bool(ok)
+		return nil
+	}
+
+	g, ok := e.stream.groups[name]
+	if !ok {
+		return fmt.Errorf("NOGROUP No such consumer group '%s' for key name '%s'", name, key)
+	}
+	switch sub {
+	case "createconsumer":
+		_, exists := g.consumers[args[4]]
+		if !exists {
+			g.consumers[args[4]] = c.server.clock.Now()
+		}
+		c.w.bool(!exists)
+	case "delconsumer":
+		var n int64
+		for id, p := range g.pending {
+			if p.consumer == args[4] {
+				delete(g.pending, id)
+				n++
+			}
+		}
+		delete(g.consumers, args[4])
+		c.w.int(n)
+	case "setid":
+		id, err := groupStartID(e.stream, args[4])
+		if err != nil {
+			return err
+		}
+		g.lastID = id
+		c.w.ok()
+	}
+	d.touch(key)
+	return nil
+}
+
+// groupStartID 解析消费组的起始ID，$ 表示流的最后一条消息
+func groupStartID(s *stream, arg string) (streamID, error) {
+	if arg == "$" {
+		return s.lastID, nil
+	}
+	return parseStreamID(arg, 0)
+}
+
+// readArgs XREAD/XREADGROUP 的公共参数
+type readArgs struct {
+	count   int64
+	block   bool
+	until   time.Time
+	noAck   bool
+	keys    []string
+	ids     []string
+	grouped bool
+}
+
+// parseReadArgs 解析 [COUNT count] [BLOCK milliseconds] [NOACK] STREAMS key [key ...] id [id ...]
+func parseReadArgs(name string, args []string, grouped bool) (*readArgs, error) {
+	r := &readArgs{grouped: grouped}
+	for i := 0; i < len(args); i++ {
+		opt := strings.ToLower(args[i])
+		switch {
+		case opt == "count" && i+1 < len(args):
+			count, err := parseInt(args[i+1])
+			if err != nil {
+				return nil, err
+			}
+			if count > 0 {
+				r.count = count
+			}
+			i++
+		case opt == "block" && i+1 < len(args):
+			ms, err := parseInt(args[i+1])
+			if err != nil {
+				return nil, errors.New("ERR timeout is not an integer or out of range")
+			}
+			if ms < 0 {
+				return nil, errNegativeTime
+			}
+			r.block = true
+			if ms > 0 {
+				r.until = time.Now().Add(time.Duration(ms) * time.Millisecond)
+			}
+			i++
+		case opt == "noack" && grouped:
+			r.noAck = true
+		case opt == "streams":
+			rest := args[i+1:]
+			if len(rest) == 0 || len(rest)%2 != 0 {
+				if grouped {
+					return nil, fmt.Errorf("ERR Unbalanced '%s' list of streams: for each stream key an ID or '>' must be specified.", name)
+				}
+				return nil, fmt.Errorf("ERR Unbalanced '%s' list of streams: for each stream key an ID or '$' must be specified.", name)
+			}
+			r.keys, r.ids = rest[:len(rest)/2], rest[len(rest)/2:]
+			return r, nil
+		default:
+			return nil, errSyntax
+		}
+	}
+	return nil, errSyntax
+}
+
+// cmdXRead XREAD [COUNT count] [BLOCK milliseconds] STREAMS key [key ...] id [id ...]
+func cmdXRead(c *conn, args []string) error {
+	r, err := parseReadArgs("xread", args[1:], false)
+	if err != nil {
+		return err
+	}
+
+	// $ 在命令开始时解析为流的最后一条消息
+	d := c.db()
+	ids := make([]streamID, len(r.keys))
+	for i, key := range r.keys {
+		e, err := getStream(d, key)
+		if err != nil {
+			return err
+		}
+		if r.ids[i] == "$" {
+			if e != nil {
+				ids[i] = e.stream.lastID
+			}
+			continue
+		}
+		if ids[i], err = parseStreamID(r.ids[i], 0); err != nil {
+			return err
+		}
+	}
+
+	for {
+		type result struct {
+			key     string
+			entries []streamEntry
+		}
+		var results []result
+		d := c.db()
+		for i, key := range r.keys {
+			e, err := getStream(d, key)
+			if err != nil {
+				return err
+			}
+			if e == nil {
+				continue
+			}
+			if entries := e.stream.after(ids[i], r.count); len(entries) > 0 {
+				results = append(results, result{key: key, entries: entries})
+			}
+		}
+
+		if len(results) > 0 {
+			c.w.array(len(results))
+			for _, res := range results {
+				c.w.array(2)
+				c.w.bulk(res.key)
+				c.w.writeEntries(res.entries)
+			}
+			return nil
+		}
+		if !r.block || !c.wait(r.until) {
+			c.w.nullArray()
+			return nil
+		}
+	}
+}
+
+// cmdXReadGroup XREADGROUP GROUP group consumer [COUNT count] [BLOCK milliseconds] [NOACK] STREAMS key [key ...] id [id ...]
+// > 读取从未投递给消费组的新消息并加入待处理列表，其他ID读取该消费者待处理列表中的历史消息
+func cmdXReadGroup(c *conn, args []string) error {
+	if !strings.EqualFold(args[1], "group") {
+		return errSyntax
+	}
+	group, consumer := args[2], args[3]
+	r, err := parseReadArgs("xreadgroup", args[4:], true)
+	if err != nil {
+		return err
+	}
+
+	history := make([]*streamID, len(r.keys))
+	for i, id := range r.ids {
+		if id == ">" {
+			continue
+		}
+		parsed, err := parseStreamID(id, 0)
+		if err != nil {
+			return err
+		}
+		history[i] = &parsed
+	}
+
+	for {
+		type result struct {
+			key     string
+			entries []streamEntry
+			deleted []streamID // 历史消息中已被删除的消息ID
+		}
+		var results []result
+		d := c.db()
+		now := c.server.clock.Now()
+		for i, key := range r.keys {
+			e, err := getStream(d, key)
+			if err != nil {
+				return err
+			}
+			var s *stream
+			if e != nil {
+				s = e.stream
+			}
+			g, err := s.group(key, group)
+			if err != nil {
+				return fmt.Errorf("NOGROUP No such key '%s' or consumer group '%s' in XREADGROUP with GROUP option", key, group)
+			}
+			g.consumers[consumer] = now
+
+			if history[i] != nil {
+				res := result{key: key}
+				start, ok := history[i].next()
+				if ok {
+					for _, id := range g.pendingIDs(start, maxStreamID, consumer) {
+						if r.count > 0 && int64(len(res.entries)) >= r.count {
+							break
+						}
+						se, found := s.lookup(id)
+						if !found {
+							se = streamEntry{id: id}
+						}
+						res.entries = append(res.entries, se)
+					}
+				}
+				results = append(results, res)
+				continue
+			}
+
+			entries := s.after(g.lastID, r.count)
+			if len(entries) == 0 {
+				continue
+			}
+			g.lastID = entries[len(entries)-1].id
+			if !r.noAck {
+				for _, se := range entries {
+					g.pending[se.id] = &pendingEntry{consumer: consumer, delivered: now, count: 1}
+				}
+			}
+			d.touch(key)
+			results = append(results, result{key: key, entries: entries})
+		}
+
+		if len(results) > 0 {
+			c.w.array(len(results))
+			for _, res := range results {
+				c.w.array(2)
+				c.w.bulk(res.key)
+				c.w.array(len(res.entries))
+				for _, se := range res.entries {
+					if se.fields == nil {
+						// 已删除的消息：[id, nil]
+						c.w.array(2)
+						c.w.bulk(se.id.String())
+						c.w.nullArray()
+						continue
+					}
+					c.w.writeEntry(se)
+				}
+			}
+			return nil
+		}
+		if !r.block || !c.wait(r.until) {
+			c.w.nullArray()
+			return nil
+		}
+	}
+}
+
+// cmdXAck XACK key group id [id ...]
+func cmdXAck(c *conn, args []string) error {
+	ids := make([]streamID, 0, len(args)-3)
+	for _, arg := range args[3:] {
+		id, err := parseStreamID(arg, 0)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+
+	d := c.db()
+	e, err := getStream(d, args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.int(0)
+		return nil
+	}
+	g, ok := e.stream.groups[args[2]]
+	if !ok {
+		c.w.int(0)
+		return nil
+	}
+
+	var n int64
+	for _, id := range ids {
+		if _, ok := g.pending[id]; ok {
+			delete(g.pending, id)
+			n++
+		}
+	}
+	if n > 0 {
+		d.touch(args[1])
+	}
+	c.w.int(n)
+	return nil
+}
+
+// cmdXPending XPENDING key group [[IDLE min-idle-time] start end count [consumer]]
+func cmdXPending(c *conn, args []string) error {
+	e, err := getStream(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	var s *stream
+	if e != nil {
+		s = e.stream
+	}
+	g, err := s.group(args[1], args[2])
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 3 {
+		ids := g.pendingIDs(streamID{}, maxStreamID, "")
+		if len(ids) == 0 {
+			c.w.array(4)
+			c.w.int(0)
+			c.w.null()
+			c.w.null()
+			c.w.nullArray()
+			return nil
+		}
+
+		counts := make(map[string]int64)
+		for _, p := range g.pending {
+			counts[p.consumer]++
+		}
+		consumers := make([]string, 0, len(counts))
+		for name := range counts {
+			consumers = append(consumers, name)
+		}
+		sort.Strings(consumers)
+
+		c.w.array(4)
+		c.w.int(int64(len(ids)))
+		c.w.bulk(ids[0].String())
+		c.w.bulk(ids[len(ids)-1].String())
+		c.w.array(len(consumers))
+		for _, name := range consumers {
+			c.w.strings([]string{name, strconv.FormatInt(counts[name], 10)})
+		}
+		return nil
+	}
+
+	rest := args[3:]
+	var minIdle time.Duration
+	if strings.EqualFold(rest[0], "idle") {
+		if len(rest) < 2 {
+			return errSyntax
+		}
+		ms, err := parseInt(rest[1])
+		if err != nil {
+			return err
+		}
+		minIdle = time.Duration(ms) * time.Millisecond
+		rest = rest[2:]
+	}
+	if len(rest) != 3 && len(rest) != 4 {
+		return errSyntax
+	}
+	start, ok1, err := parseRangeID(rest[0], true)
+	if err != nil {
+		return err
+	}
+	end, ok2, err := parseRangeID(rest[1], false)
+	if err != nil {
+		return err
+	}
+	count, err := parseInt(rest[2])
+	if err != nil {
+		return err
+	}
+	var consumer string
+	if len(rest) == 4 {
+		consumer = rest[3]
+	}
+	if !ok1 || !ok2 || count <= 0 {
+		c.w.array(0)
+		return nil
+	}
+
+	now := c.server.clock.Now()
+	type item struct {
+		id streamID
+		p  *pendingEntry
+	}
+	var items []item
+	for _, id := range g.pendingIDs(start, end, consumer) {
+		if int64(len(items)) >= count {
+			break
+		}
+		p := g.pending[id]
+		if now.Sub(p.delivered) < minIdle {
+			continue
+		}
+		items = append(items, item{id: id, p: p})
+	}
+
+	c.w.array(len(items))
+	for _, it := range items {
+		c.w.array(4)
+		c.w.bulk(it.id.String())
+		c.w.bulk(it.p.consumer)
+		c.w.int(now.Sub(it.p.delivered).Milliseconds())
+		c.w.int(it.p.count)
+	}
+	return nil
+}
+
+// cmdXClaim XCLAIM key group consumer min-idle-time id [id ...] [IDLE ms] [TIME unix-time-milliseconds] [RETRYCOUNT count] [FORCE] [JUSTID] [LASTID lastid]
+func cmdXClaim(c *conn, args []string) error {
+	key, group, consumer := args[1], args[2], args[3]
+	minIdleMs, err := parseInt(args[4])
+	if err != nil {
+		return errors.New("ERR Invalid min-idle-time argument for XCLAIM")
+	}
+
+	var ids []streamID
+	i := 5
+	for ; i < len(args); i++ {
+		id, err := parseStreamID(args[i], 0)
+		if err != nil {
+			break
+		}
+		ids = append(ids, id)
+	}
+
+	now := c.server.clock.Now()
+	delivered := now
+	retryCount := int64(-1)
+	force, justID := false, false
+	for ; i < len(args); i++ {
+		opt := strings.ToLower(args[i])
+		switch {
+		case opt == "force":
+			force = true
+		case opt == "justid":
+			justID = true
+		case (opt == "idle" || opt == "time" || opt == "retrycount" || opt == "lastid") && i+1 < len(args):
+			i++
+			if opt == "lastid" {
+				if _, err := parseStreamID(args[i], 0); err != nil {
+					return err
+				}
+				continue
+			}
+			v, err := parseInt(args[i])
+			if err != nil {
+				return err
+			}
+			switch opt {
+			case "idle":
+				delivered = now.Add(-time.Duration(v) * time.Millisecond)
+			case "time":
+				delivered = time.UnixMilli(v)
+			case "retrycount":
+				retryCount = v
+			}
+		default:
+			return fmt.Errorf("ERR Unrecognized XCLAIM option '%s'", args[i])
+		}
+	}
+
+	d := c.db()
+	e, err := getStream(d, key)
+	if err != nil {
+		return err
+	}
+	var s *stream
+	if e != nil {
+		s = e.stream
+	}
+	g, err := s.group(key, group)
+	if err != nil {
+		return err
+	}
+	g.consumers[consumer] = now
+
+	minIdle := time.Duration(minIdleMs) * time.Millisecond
+	var claimed []streamEntry
+	for _, id := range ids {
+		se, exists := s.lookup(id)
+		p, ok := g.pending[id]
+		if !ok {
+			if !force || !exists {
+				continue
+			}
+			p = &pendingEntry{}
+			g.pending[id] = p
+		} else if !exists {
+			// 消息已被删除，从待处理列表移除
+			delete(g.pending, id)
+			continue
+		} else if now.Sub(p.delivered) < minIdle {
+			continue
+		}
+
+		p.consumer = consumer
+		p.delivered = delivered
+		if retryCount >= 0 {
+			p.count = retryCount
+		} else if !justID {
+			p.count++
+		}
+		claimed = append(claimed, se)
+	}
+	d.touch(key)
+
+	if justID {
+		c.w.array(len(claimed))
+		for _, se := range claimed {
+			c.w.bulk(se.id.String())
+		}
+		return nil
+	}
+	c.w.writeEntries(claimed)
+	return nil
+}
+
+// cmdXAutoClaim XAUTOCLAIM key group consumer min-idle-time start [COUNT count] [JUSTID]
+// 回复为 [下次扫描的起始ID, 认领的消息, 已删除的消息ID]（Redis 7 格式）
+func cmdXAutoClaim(c *conn, args []string) error {
+	key, group, consumer := args[1], args[2], args[3]
+	minIdleMs, err := parseInt(args[4])
+	if err != nil {
+		return errors.New("ERR Invalid min-idle-time argument for XAUTOCLAIM")
+	}
+	start, _, err := parseRangeID(args[5], true)
+	if err != nil {
+		return err
+	}
+
+	count := int64(100)
+	justID := false
+	for i := 6; i < len(args); i++ {
+		switch opt := strings.ToLower(args[i]); {
+		case opt == "count" && i+1 < len(args):
+			if count, err = parseInt(args[i+1]); err != nil {
+				return err
+			}
+			if count < 1 {
+				return errors.New("ERR COUNT must be > 0")
+			}
+			i++
+		case opt == "justid":
+			justID = true
+		default:
+			return errSyntax
+		}
+	}
+
+	d := c.db()
+	e, err := getStream(d, key)
+	if err != nil {
+		return err
+	}
+	var s *stream
+	if e != nil {
+		s = e.stream
+	}
+	g, err := s.group(key, group)
+	if err != nil {
+		return err
+	}
+	now := c.server.clock.Now()
+	g.consumers[consumer] = now
+
+	minIdle := time.Duration(minIdleMs) * time.Millisecond
+	ids := g.pendingIDs(start, maxStreamID, "")
+	// 与 Redis 一致最多扫描 count*10 条待处理消息
+	attempts := count * 10
+	var claimed []streamEntry
+	var deleted []streamID
+	next := streamID{}
+	for _, id := range ids {
+		if int64(len(claimed)) >= count || attempts == 0 {
+			next = id
+			break
+		}
+		attempts--
+
+		p := g.pending[id]
+		se, exists := s.lookup(id)
+		if !exists {
+			delete(g.pending, id)
+			deleted = append(deleted, id)
+			continue
+		}
+		if now.Sub(p.delivered) < minIdle {
+			continue
+		}
+		p.consumer = consumer
+		p.delivered = now
+		if !justID {
+			p.count++
+		}
+		claimed = append(claimed, se)
+	}
+	d.touch(key)
+
+	c.w.array(3)
+	c.w.bulk(next.String())
+	if justID {
+		c.w.array(len(claimed))
+		for _, se := range claimed {
+			c.w.bulk(se.id.String())
+		}
+	} else {
+		c.w.writeEntries(claimed)
+	}
+	c.w.array(len(deleted))
+	for _, id := range deleted {
+		c.w.bulk(id.String())
+	}
+	return nil
+}
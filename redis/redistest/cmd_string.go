@@ -0,0 +1,516 @@
+package redistest
+
+import (
+	"errors"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stringCommands 字符串命令
+var stringCommands = map[string]command{
+	"get":         {fn: cmdGet, arity: 2},
+	"set":         {fn: cmdSet, arity: -3},
+	"setex":       {fn: cmdSetEx, arity: 4},
+	"psetex":      {fn: cmdSetEx, arity: 4},
+	"setnx":       {fn: cmdSetNX, arity: 3},
+	"getset":      {fn: cmdGetSet, arity: 3},
+	"getdel":      {fn: cmdGetDel, arity: 2},
+	"append":      {fn: cmdAppend, arity: 3},
+	"strlen":      {fn: cmdStrlen, arity: 2},
+	"getrange":    {fn: cmdGetRange, arity: 4},
+	"setrange":    {fn: cmdSetRange, arity: 4},
+	"incr":        {fn: cmdIncr, arity: 2},
+	"decr":        {fn: cmdIncr, arity: 2},
+	"incrby":      {fn: cmdIncr, arity: 3},
+	"decrby":      {fn: cmdIncr, arity: 3},
+	"incrbyfloat": {fn: cmdIncrByFloat, arity: 3},
+	"mget":        {fn: cmdMGet, arity: -2},
+	"mset":        {fn: cmdMSet, arity: -3},
+	"msetnx":      {fn: cmdMSet, arity: -3},
+	"setbit":      {fn: cmdSetBit, arity: 4},
+	"getbit":      {fn: cmdGetBit, arity: 3},
+	"bitcount":    {fn: cmdBitCount, arity: -2},
+}
+
+// getString 获取字符串类型的键值
+func getString(d *db, key string) (*entry, error) {
+	return d.getKind(key, kindString)
+}
+
+// setString 设置字符串值，清除过期时间
+func setString(d *db, key, value string) {
+	e := newEntry(kindString)
+	e.str = value
+	d.set(key, e)
+}
+
+// cmdGet GET key
+func cmdGet(c *conn, args []string) error {
+	e, err := getString(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.null()
+		return nil
+	}
+	c.w.bulk(e.str)
+	return nil
+}
+
+// cmdSet SET key value [NX|XX] [GET] [EX seconds|PX milliseconds|EXAT timestamp|PXAT timestamp|KEEPTTL]
+func cmdSet(c *conn, args []string) error {
+	var (
+		nx, xx, get, keepTTL bool
+		expireAt             time.Time
+	)
+	now := c.server.clock.Now()
+	for i := 3; i < len(args); i++ {
+		opt := strings.ToLower(args[i])
+		switch opt {
+		case "nx":
+			nx = true
+		case "xx":
+			xx = true
+		case "get":
+			get = true
+		case "keepttl":
+			keepTTL = true
+		case "ex", "px", "exat", "pxat":
+			if i+1 >= len(args) || !expireAt.IsZero() {
+				return errSyntax
+			}
+			i++
+			value, err := parseInt(args[i])
+			if err != nil {
+				return err
+			}
+			if value <= 0 {
+				return errors.New("ERR invalid expire time in 'set' command")
+			}
+			switch opt {
+			case "ex":
+				expireAt = now.Add(time.Duration(value) * time.Second)
+			case "px":
+				expireAt = now.Add(time.Duration(value) * time.Millisecond)
+			case "exat":
+				expireAt = time.Unix(value, 0)
+			default:
+				expireAt = time.UnixMilli(value)
+			}
+		default:
+			return errSyntax
+		}
+	}
+	if (nx && xx) || (keepTTL && !expireAt.IsZero()) {
+		return errSyntax
+	}
+
+	d := c.db()
+	old, err := getString(d, args[1])
+	if get && err != nil {
+		return err
+	}
+	exists := d.get(args[1]) != nil
+
+	if (nx && exists) || (xx && !exists) {
+		if get && old != nil {
+			c.w.bulk(old.str)
+		} else {
+			c.w.null()
+		}
+		return nil
+	}
+
+	e := newEntry(kindString)
+	e.str = args[2]
+	e.expireAt = expireAt
+	if keepTTL && old != nil {
+		e.expireAt = old.expireAt
+	}
+	d.set(args[1], e)
+
+	switch {
+	case !get:
+		c.w.ok()
+	case old != nil:
+		c.w.bulk(old.str)
+	default:
+		c.w.null()
+	}
+	return nil
+}
+
+// cmdSetEx SETEX/PSETEX key ttl value
+func cmdSetEx(c *conn, args []string) error {
+	ttl, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		return errors.New("ERR invalid expire time in '" + strings.ToLower(args[0]) + "' command")
+	}
+	unit := time.Second
+	if strings.EqualFold(args[0], "psetex") {
+		unit = time.Millisecond
+	}
+
+	e := newEntry(kindString)
+	e.str = args[3]
+	e.expireAt = c.server.clock.Now().Add(time.Duration(ttl) * unit)
+	c.db().set(args[1], e)
+	c.w.ok()
+	return nil
+}
+
+// cmdSetNX SETNX key value
+func cmdSetNX(c *conn, args []string) error {
+	d := c.db()
+	if d.get(args[1]) != nil {
+		c.w.int(0)
+		return nil
+	}
+	setString(d, args[1], args[2])
+	c.w.int(1)
+	return nil
+}
+
+// cmdGetSet GETSET key value
+func cmdGetSet(c *conn, args []string) error {
+	d := c.db()
+	old, err := getString(d, args[1])
+	if err != nil {
+		return err
+	}
+	setString(d, args[1], args[2])
+	if old == nil {
+		c.w.null()
+	} else {
+		c.w.bulk(old.str)
+	}
+	return nil
+}
+
+// cmdGetDel GETDEL key
+func cmdGetDel(c *conn, args []string) error {
+	d := c.db()
+	e, err := getString(d, args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.null()
+		return nil
+	}
+	d.remove(args[1])
+	c.w.bulk(e.str)
+	return nil
+}
+
+// cmdAppend APPEND key value
+func cmdAppend(c *conn, args []string) error {
+	d := c.db()
+	e, err := d.getOrCreate(args[1], kindString)
+	if err != nil {
+		return err
+	}
+	e.str += args[2]
+	d.modified(args[1])
+	c.w.int(int64(len(e.str)))
+	return nil
+}
+
+// cmdStrlen STRLEN key
+func cmdStrlen(c *conn, args []string) error {
+	e, err := getString(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.int(0)
+		return nil
+	}
+	c.w.int(int64(len(e.str)))
+	return nil
+}
+
+// cmdGetRange GETRANGE key start end
+func cmdGetRange(c *conn, args []string) error {
+	start, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+	stop, err := parseInt(args[3])
+	if err != nil {
+		return err
+	}
+	e, err := getString(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.bulk("")
+		return nil
+	}
+
+	s, t, ok := normalizeRange(start, stop, int64(len(e.str)))
+	if !ok {
+		c.w.bulk("")
+		return nil
+	}
+	c.w.bulk(e.str[s : t+1])
+	return nil
+}
+
+// cmdSetRange SETRANGE key offset value
+func cmdSetRange(c *conn, args []string) error {
+	offset, err := parseInt(args[2])
+	if err != nil {
+		return err
+	}
+	if offset < 0 || offset > 512<<20 {
+		return errOutOfRange
+	}
+
+	d := c.db()
+	e, err := getString(d, args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil && args[3] == "" {
+		c.w.int(0)
+		return nil
+	}
+	if e == nil {
+		e, _ = d.getOrCreate(args[1], kindString)
+	}
+
+	buf := []byte(e.str)
+	if end := int(offset) + len(args[3]); end > len(buf) {
+		buf = append(buf, make([]byte, end-len(buf))...)
+	}
+	copy(buf[offset:], args[3])
+	e.str = string(buf)
+	d.modified(args[1])
+	c.w.int(int64(len(e.str)))
+	return nil
+}
+
+// cmdIncr INCR/DECR/INCRBY/DECRBY key [increment]
+func cmdIncr(c *conn, args []string) error {
+	delta := int64(1)
+	if len(args) > 2 {
+		var err error
+		if delta, err = parseInt(args[2]); err != nil {
+			return err
+		}
+	}
+	if strings.HasPrefix(strings.ToLower(args[0]), "decr") {
+		if delta == math.MinInt64 {
+			return errors.New("ERR decrement would overflow")
+		}
+		delta = -delta
+	}
+
+	d := c.db()
+	e, err := getString(d, args[1])
+	if err != nil {
+		return err
+	}
+	var value int64
+	if e != nil {
+		if value, err = parseInt(e.str); err != nil {
+			return err
+		}
+	}
+	if (delta > 0 && value > math.MaxInt64-delta) || (delta < 0 && value < math.MinInt64-delta) {
+		return errors.New("ERR increment or decrement would overflow")
+	}
+	value += delta
+
+	if e == nil {
+		e, _ = d.getOrCreate(args[1], kindString)
+	}
+	e.str = strconv.FormatInt(value, 10)
+	d.modified(args[1])
+	c.w.int(value)
+	return nil
+}
+
+// cmdIncrByFloat INCRBYFLOAT key increment
+func cmdIncrByFloat(c *conn, args []string) error {
+	delta, err := parseFloat(args[2])
+	if err != nil {
+		return err
+	}
+
+	d := c.db()
+	e, err := getString(d, args[1])
+	if err != nil {
+		return err
+	}
+	var value float64
+	if e != nil {
+		if value, err = parseFloat(e.str); err != nil {
+			return err
+		}
+	}
+	value += delta
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return errors.New("ERR increment would produce NaN or Infinity")
+	}
+
+	if e == nil {
+		e, _ = d.getOrCreate(args[1], kindString)
+	}
+	e.str = formatFloat(value)
+	d.modified(args[1])
+	c.w.bulk(e.str)
+	return nil
+}
+
+// cmdMGet MGET key [key ...]，非字符串类型的键返回 nil
+func cmdMGet(c *conn, args []string) error {
+	d := c.db()
+	c.w.array(len(args) - 1)
+	for _, key := range args[1:] {
+		e := d.get(key)
+		if e == nil || e.kind != kindString {
+			c.w.null()
+			continue
+		}
+		c.w.bulk(e.str)
+	}
+	return nil
+}
+
+// cmdMSet MSET/MSETNX key value [key value ...]
+func cmdMSet(c *conn, args []string) error {
+	if len(args)%2 != 1 {
+		return wrongArgs(args[0])
+	}
+
+	d := c.db()
+	nx := strings.EqualFold(args[0], "msetnx")
+	if nx {
+		for i := 1; i < len(args); i += 2 {
+			if d.get(args[i]) != nil {
+				c.w.int(0)
+				return nil
+			}
+		}
+	}
+
+	for i := 1; i < len(args); i += 2 {
+		setString(d, args[i], args[i+1])
+	}
+	if nx {
+		c.w.int(1)
+	} else {
+		c.w.ok()
+	}
+	return nil
+}
+
+// parseBitOffset 解析位偏移量，范围为 [0, 2^32)
+func parseBitOffset(s string) (int64, error) {
+	offset, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || offset < 0 || offset >= 1<<32 {
+		return 0, errors.New("ERR bit offset is not an integer or out of range")
+	}
+	return offset, nil
+}
+
+// cmdSetBit SETBIT key offset value，位 0 为第一个字节的最高位
+func cmdSetBit(c *conn, args []string) error {
+	offset, err := parseBitOffset(args[2])
+	if err != nil {
+		return err
+	}
+	if args[3] != "0" && args[3] != "1" {
+		return errors.New("ERR bit is not an integer or out of range")
+	}
+
+	d := c.db()
+	e, err := d.getOrCreate(args[1], kindString)
+	if err != nil {
+		return err
+	}
+	buf := []byte(e.str)
+	index := int(offset / 8)
+	if index >= len(buf) {
+		buf = append(buf, make([]byte, index+1-len(buf))...)
+	}
+	mask := byte(1) << (7 - offset%8)
+	old := buf[index]&mask != 0
+	if args[3] == "1" {
+		buf[index] |= mask
+	} else {
+		buf[index] &^= mask
+	}
+	e.str = string(buf)
+	d.modified(args[1])
+	c.w.bool(old)
+	return nil
+}
+
+// cmdGetBit GETBIT key offset
+func cmdGetBit(c *conn, args []string) error {
+	offset, err := parseBitOffset(args[2])
+	if err != nil {
+		return err
+	}
+	e, err := getString(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil || int(offset/8) >= len(e.str) {
+		c.w.int(0)
+		return nil
+	}
+	c.w.bool(e.str[offset/8]&(byte(1)<<(7-offset%8)) != 0)
+	return nil
+}
+
+// cmdBitCount BITCOUNT key [start end]，区间以字节为单位
+func cmdBitCount(c *conn, args []string) error {
+	if len(args) != 2 && len(args) != 4 {
+		return errSyntax
+	}
+	e, err := getString(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.int(0)
+		return nil
+	}
+
+	data := e.str
+	if len(args) == 4 {
+		start, err := parseInt(args[2])
+		if err != nil {
+			return err
+		}
+		stop, err := parseInt(args[3])
+		if err != nil {
+			return err
+		}
+		s, t, ok := normalizeRange(start, stop, int64(len(data)))
+		if !ok {
+			c.w.int(0)
+			return nil
+		}
+		data = data[s : t+1]
+	}
+
+	var n int64
+	for i := 0; i < len(data); i++ {
+		n += int64(bits.OnesCount8(data[i]))
+	}
+	c.w.int(n)
+	return nil
+}
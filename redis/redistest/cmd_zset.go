@@ -0,0 +1,828 @@
+package redistest
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// zsetCommands 有序集合命令
+var zsetCommands = map[string]command{
+	"zadd":             {fn: cmdZAdd, arity: -4},
+	"zcard":            {fn: cmdZCard, arity: 2},
+	"zcount":           {fn: cmdZCount, arity: 4},
+	"zincrby":          {fn: cmdZIncrBy, arity: 4},
+	"zscore":           {fn: cmdZScore, arity: 3},
+	"zmscore":          {fn: cmdZMScore, arity: -3},
+	"zrank":            {fn: cmdZRank, arity: -3},
+	"zrevrank":         {fn: cmdZRank, arity: -3},
+	"zrange":           {fn: cmdZRange, arity: -4},
+	"zrevrange":        {fn: cmdZRange, arity: -4},
+	"zrangebyscore":    {fn: cmdZRange, arity: -4},
+	"zrevrangebyscore": {fn: cmdZRange, arity: -4},
+	"zrangebylex":      {fn: cmdZRange, arity: -4},
+	"zrevrangebylex":   {fn: cmdZRange, arity: -4},
+	"zlexcount":        {fn: cmdZLexCount, arity: 4},
+	"zrem":             {fn: cmdZRem, arity: -3},
+	"zremrangebyrank":  {fn: cmdZRemRange, arity: 4},
+	"zremrangebyscore": {fn: cmdZRemRange, arity: 4},
+	"zremrangebylex":   {fn: cmdZRemRange, arity: 4},
+	"zpopmin":          {fn: cmdZPop, arity: -2},
+	"zpopmax":          {fn: cmdZPop, arity: -2},
+	"zunionstore":      {fn: cmdZStore, arity: -4},
+	"zinterstore":      {fn: cmdZStore, arity: -4},
+	"zscan":            {fn: cmdZScan, arity: -3},
+}
+
+// zmember 有序集合成员
+type zmember struct {
+	member string
+	score  float64
+}
+
+// getZSet 获取有序集合类型的键值
+func getZSet(d *db, key string) (*entry, error) {
+	return d.getKind(key, kindZSet)
+}
+
+// sorted 获取按分数升序、分数相同时按成员字典序排列的成员
+func (e *entry) sorted() []zmember {
+	members := make([]zmember, 0, len(e.zset))
+	for member, score := range e.zset {
+		members = append(members, zmember{member: member, score: score})
+	}
+	slices.SortFunc(members, func(a, b zmember) int {
+		switch {
+		case a.score < b.score:
+			return -1
+		case a.score > b.score:
+			return 1
+		default:
+			return strings.Compare(a.member, b.member)
+		}
+	})
+	return members
+}
+
+// scoreBound 分数区间的边界
+type scoreBound struct {
+	value     float64
+	exclusive bool
+}
+
+// parseScoreBound 解析分数边界，支持 ( 开区间和 ±inf
+func parseScoreBound(s string) (scoreBound, error) {
+	var b scoreBound
+	if strings.HasPrefix(s, "(") {
+		b.exclusive = true
+		s = s[1:]
+	}
+	v, err := parseFloat(s)
+	if err != nil {
+		return b, errMinMaxFloat
+	}
+	b.value = v
+	return b, nil
+}
+
+// aboveMin 判断分数是否满足下界
+func (b scoreBound) aboveMin(score float64) bool {
+	if b.exclusive {
+		return score > b.value
+	}
+	return score >= b.value
+}
+
+// belowMax 判断分数是否满足上界
+func (b scoreBound) belowMax(score float64) bool {
+	if b.exclusive {
+		return score < b.value
+	}
+	return score <= b.value
+}
+
+// lexBound 字典序区间的边界
+type lexBound struct {
+	value     string
+	exclusive bool
+	inf       int // -1 为 -，1 为 +
+}
+
+// parseLexBound 解析字典序边界：[ 闭区间、( 开区间、- 最小、+ 最大
+func parseLexBound(s string) (lexBound, error) {
+	switch {
+	case s == "-":
+		return lexBound{inf: -1}, nil
+	case s == "+":
+		return lexBound{inf: 1}, nil
+	case strings.HasPrefix(s, "["):
+		return lexBound{value: s[1:]}, nil
+	case strings.HasPrefix(s, "("):
+		return lexBound{value: s[1:], exclusive: true}, nil
+	default:
+		return lexBound{}, errMinMaxLex
+	}
+}
+
+// aboveMin 判断成员是否满足下界
+func (b lexBound) aboveMin(member string) bool {
+	switch {
+	case b.inf < 0:
+		return true
+	case b.inf > 0:
+		return false
+	case b.exclusive:
+		return member > b.value
+	default:
+		return member >= b.value
+	}
+}
+
+// belowMax 判断成员是否满足上界
+func (b lexBound) belowMax(member string) bool {
+	switch {
+	case b.inf > 0:
+		return true
+	case b.inf < 0:
+		return false
+	case b.exclusive:
+		return member < b.value
+	default:
+		return member <= b.value
+	}
+}
+
+// cmdZAdd ZADD key [NX|XX] [GT|LT] [CH] [INCR] score member [score member ...]
+func cmdZAdd(c *conn, args []string) error {
+	var nx, xx, gt, lt, ch, incr bool
+	i := 2
+loop:
+	for ; i < len(args); i++ {
+		switch strings.ToLower(args[i]) {
+		case "nx":
+			nx = true
+		case "xx":
+			xx = true
+		case "gt":
+			gt = true
+		case "lt":
+			lt = true
+		case "ch":
+			ch = true
+		case "incr":
+			incr = true
+		default:
+			break loop
+		}
+	}
+
+	pairs := args[i:]
+	if len(pairs) == 0 || len(pairs)%2 != 0 {
+		return errSyntax
+	}
+	if nx && xx {
+		return errors.New("ERR XX and NX options at the same time are not compatible")
+	}
+	if (gt && lt) || (nx && (gt || lt)) {
+		return errors.New("ERR GT, LT, and/or NX options at the same time are not compatible")
+	}
+	if incr && len(pairs) > 2 {
+		return errors.New("ERR INCR option supports a single increment-element pair")
+	}
+
+	scores := make([]float64, len(pairs)/2)
+	for j := range scores {
+		score, err := parseFloat(pairs[j*2])
+		if err != nil {
+			return err
+		}
+		scores[j] = score
+	}
+
+	d := c.db()
+	e, err := getZSet(d, args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		if xx {
+			if incr {
+				c.w.null()
+			} else {
+				c.w.int(0)
+			}
+			return nil
+		}
+		e, _ = d.getOrCreate(args[1], kindZSet)
+	}
+
+	var added, changed int64
+	var result float64
+	skipped := false
+	for j, score := range scores {
+		member := pairs[j*2+1]
+		old, exists := e.zset[member]
+		if (nx && exists) || (xx && !exists) {
+			skipped = true
+			continue
+		}
+		if incr && exists {
+			score += old
+			if math.IsNaN(score) {
+				d.modified(args[1])
+				return errors.New("ERR resulting score is not a number (NaN)")
+			}
+		}
+		if exists && ((gt && score <= old) || (lt && score >= old)) {
+			skipped = true
+			continue
+		}
+
+		result = score
+		e.zset[member] = score
+		switch {
+		case !exists:
+			added++
+		case score != old:
+			changed++
+		}
+	}
+	d.modified(args[1])
+
+	switch {
+	case incr && skipped:
+		c.w.null()
+	case incr:
+		c.w.bulk(formatFloat(result))
+	case ch:
+		c.w.int(added + changed)
+	default:
+		c.w.int(added)
+	}
+	return nil
+}
+
+// cmdZCard ZCARD key
+func cmdZCard(c *conn, args []string) error {
+	e, err := getZSet(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.int(0)
+		return nil
+	}
+	c.w.int(int64(len(e.zset)))
+	return nil
+}
+
+// cmdZCount ZCOUNT key min max
+func cmdZCount(c *conn, args []string) error {
+	lo, err := parseScoreBound(args[2])
+	if err != nil {
+		return err
+	}
+	hi, err := parseScoreBound(args[3])
+	if err != nil {
+		return err
+	}
+	e, err := getZSet(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+
+	var n int64
+	if e != nil {
+		for _, score := range e.zset {
+			if lo.aboveMin(score) && hi.belowMax(score) {
+				n++
+			}
+		}
+	}
+	c.w.int(n)
+	return nil
+}
+
+// cmdZLexCount ZLEXCOUNT key min max
+func cmdZLexCount(c *conn, args []string) error {
+	lo, err := parseLexBound(args[2])
+	if err != nil {
+		return err
+	}
+	hi, err := parseLexBound(args[3])
+	if err != nil {
+		return err
+	}
+	e, err := getZSet(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+
+	var n int64
+	if e != nil {
+		for member := range e.zset {
+			if lo.aboveMin(member) && hi.belowMax(member) {
+				n++
+			}
+		}
+	}
+	c.w.int(n)
+	return nil
+}
+
+// cmdZIncrBy ZINCRBY key increment member
+func cmdZIncrBy(c *conn, args []string) error {
+	delta, err := parseFloat(args[2])
+	if err != nil {
+		return err
+	}
+	d := c.db()
+	e, err := d.getOrCreate(args[1], kindZSet)
+	if err != nil {
+		return err
+	}
+	score := e.zset[args[3]] + delta
+	if math.IsNaN(score) {
+		d.modified(args[1])
+		return errors.New("ERR resulting score is not a number (NaN)")
+	}
+	e.zset[args[3]] = score
+	d.modified(args[1])
+	c.w.bulk(formatFloat(score))
+	return nil
+}
+
+// cmdZScore ZSCORE key member
+func cmdZScore(c *conn, args []string) error {
+	e, err := getZSet(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.null()
+		return nil
+	}
+	score, ok := e.zset[args[2]]
+	if !ok {
+		c.w.null()
+		return nil
+	}
+	c.w.bulk(formatFloat(score))
+	return nil
+}
+
+// cmdZMScore ZMSCORE key member [member ...]
+func cmdZMScore(c *conn, args []string) error {
+	e, err := getZSet(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	c.w.array(len(args) - 2)
+	for _, member := range args[2:] {
+		var score float64
+		ok := false
+		if e != nil {
+			score, ok = e.zset[member]
+		}
+		if ok {
+			c.w.bulk(formatFloat(score))
+		} else {
+			c.w.null()
+		}
+	}
+	return nil
+}
+
+// cmdZRank ZRANK/ZREVRANK key member [WITHSCORE]
+func cmdZRank(c *conn, args []string) error {
+	withScore := false
+	switch {
+	case len(args) == 4 && strings.EqualFold(args[3], "withscore"):
+		withScore = true
+	case len(args) != 3:
+		return errSyntax
+	}
+
+	e, err := getZSet(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.null()
+		return nil
+	}
+	if _, ok := e.zset[args[2]]; !ok {
+		c.w.null()
+		return nil
+	}
+
+	members := e.sorted()
+	if strings.EqualFold(args[0], "zrevrank") {
+		slices.Reverse(members)
+	}
+	rank := slices.IndexFunc(members, func(m zmember) bool { return m.member == args[2] })
+	if withScore {
+		c.w.array(2)
+		c.w.int(int64(rank))
+		c.w.bulk(formatFloat(members[rank].score))
+		return nil
+	}
+	c.w.int(int64(rank))
+	return nil
+}
+
+// zrangeSpec ZRANGE 系列命令的查询条件
+type zrangeSpec struct {
+	by         string // rank、score、lex
+	rev        bool
+	start      string
+	stop       string
+	withScores bool
+	limit      bool
+	offset     int64
+	count      int64
+}
+
+// parseZRange 将 ZRANGE 及其旧式变体统一解析为查询条件
+func parseZRange(args []string) (zrangeSpec, error) {
+	spec := zrangeSpec{by: "rank", start: args[2], stop: args[3], count: -1}
+	name := strings.ToLower(args[0])
+	switch name {
+	case "zrevrange":
+		spec.rev = true
+	case "zrangebyscore", "zrevrangebyscore":
+		spec.by = "score"
+	case "zrangebylex", "zrevrangebylex":
+		spec.by = "lex"
+	}
+	// 旧式 ZREVRANGEBY* 的参数为 max min
+	if strings.HasPrefix(name, "zrevrangeby") {
+		spec.rev = true
+		spec.start, spec.stop = spec.stop, spec.start
+	}
+
+	for i := 4; i < len(args); i++ {
+		switch strings.ToLower(args[i]) {
+		case "withscores":
+			if spec.by == "lex" {
+				return spec, errSyntax
+			}
+			spec.withScores = true
+		case "limit":
+			if i+2 >= len(args) {
+				return spec, errSyntax
+			}
+			var err error
+			if spec.offset, err = parseInt(args[i+1]); err != nil {
+				return spec, err
+			}
+			if spec.count, err = parseInt(args[i+2]); err != nil {
+				return spec, err
+			}
+			spec.limit = true
+			i += 2
+		case "byscore":
+			if name != "zrange" {
+				return spec, errSyntax
+			}
+			spec.by = "score"
+		case "bylex":
+			if name != "zrange" {
+				return spec, errSyntax
+			}
+			spec.by = "lex"
+		case "rev":
+			if name != "zrange" {
+				return spec, errSyntax
+			}
+			spec.rev = true
+		default:
+			return spec, errSyntax
+		}
+	}
+
+	// ZRANGE ... BYSCORE|BYLEX REV 的参数为 max min
+	if name == "zrange" && spec.rev && spec.by != "rank" {
+		spec.start, spec.stop = spec.stop, spec.start
+	}
+	if spec.limit && spec.by == "rank" {
+		return spec, errors.New("ERR syntax error, LIMIT is only supported in combination with either BYSCORE or BYLEX")
+	}
+	return spec, nil
+}
+
+// selectRange 按查询条件从有序集合中选取成员
+func selectRange(e *entry, spec zrangeSpec) ([]zmember, error) {
+	members := e.sorted()
+
+	switch spec.by {
+	case "rank":
+		start, err := parseInt(spec.start)
+		if err != nil {
+			return nil, err
+		}
+		stop, err := parseInt(spec.stop)
+		if err != nil {
+			return nil, err
+		}
+		if spec.rev {
+			slices.Reverse(members)
+		}
+		s, t, ok := normalizeRange(start, stop, int64(len(members)))
+		if !ok {
+			return nil, nil
+		}
+		return members[s : t+1], nil
+	case "score":
+		lo, err := parseScoreBound(spec.start)
+		if err != nil {
+			return nil, err
+		}
+		hi, err := parseScoreBound(spec.stop)
+		if err != nil {
+			return nil, err
+		}
+		members = slices.DeleteFunc(members, func(m zmember) bool {
+			return !lo.aboveMin(m.score) || !hi.belowMax(m.score)
+		})
+	default:
+		lo, err := parseLexBound(spec.start)
+		if err != nil {
+			return nil, err
+		}
+		hi, err := parseLexBound(spec.stop)
+		if err != nil {
+			return nil, err
+		}
+		members = slices.DeleteFunc(members, func(m zmember) bool {
+			return !lo.aboveMin(m.member) || !hi.belowMax(m.member)
+		})
+	}
+
+	if spec.rev {
+		slices.Reverse(members)
+	}
+	if spec.limit {
+		if spec.offset < 0 || spec.offset >= int64(len(members)) {
+			return nil, nil
+		}
+		members = members[spec.offset:]
+		if spec.count >= 0 && spec.count < int64(len(members)) {
+			members = members[:spec.count]
+		}
+	}
+	return members, nil
+}
+
+// members 写入成员列表，withScores 时每个成员后附带分数
+func (w *writer) members(members []zmember, withScores bool) {
+	if !withScores {
+		w.array(len(members))
+		for _, m := range members {
+			w.bulk(m.member)
+		}
+		return
+	}
+	w.array(len(members) * 2)
+	for _, m := range members {
+		w.bulk(m.member)
+		w.bulk(formatFloat(m.score))
+	}
+}
+
+// cmdZRange ZRANGE/ZREVRANGE/ZRANGEBYSCORE/ZREVRANGEBYSCORE/ZRANGEBYLEX/ZREVRANGEBYLEX
+func cmdZRange(c *conn, args []string) error {
+	spec, err := parseZRange(args)
+	if err != nil {
+		return err
+	}
+	e, err := getZSet(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.strings(nil)
+		return nil
+	}
+	members, err := selectRange(e, spec)
+	if err != nil {
+		return err
+	}
+	c.w.members(members, spec.withScores)
+	return nil
+}
+
+// cmdZRem ZREM key member [member ...]
+func cmdZRem(c *conn, args []string) error {
+	d := c.db()
+	e, err := getZSet(d, args[1])
+	if err != nil {
+		return err
+	}
+	var n int64
+	if e != nil {
+		for _, member := range args[2:] {
+			if _, ok := e.zset[member]; ok {
+				delete(e.zset, member)
+				n++
+			}
+		}
+	}
+	if n > 0 {
+		d.modified(args[1])
+	}
+	c.w.int(n)
+	return nil
+}
+
+// cmdZRemRange ZREMRANGEBYRANK/ZREMRANGEBYSCORE/ZREMRANGEBYLEX key min max
+func cmdZRemRange(c *conn, args []string) error {
+	spec := zrangeSpec{by: strings.TrimPrefix(strings.ToLower(args[0]), "zremrangeby"), start: args[2], stop: args[3]}
+	d := c.db()
+	e, err := getZSet(d, args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		if _, err := selectRange(newEntry(kindZSet), spec); err != nil {
+			return err
+		}
+		c.w.int(0)
+		return nil
+	}
+
+	members, err := selectRange(e, spec)
+	if err != nil {
+		return err
+	}
+	for _, m := range members {
+		delete(e.zset, m.member)
+	}
+	if len(members) > 0 {
+		d.modified(args[1])
+	}
+	c.w.int(int64(len(members)))
+	return nil
+}
+
+// cmdZPop ZPOPMIN/ZPOPMAX key [count]
+func cmdZPop(c *conn, args []string) error {
+	if len(args) > 3 {
+		return errSyntax
+	}
+	count := int64(1)
+	if len(args) == 3 {
+		var err error
+		if count, err = parseInt(args[2]); err != nil || count < 0 {
+			return errors.New("ERR value is out of range, must be positive")
+		}
+	}
+
+	d := c.db()
+	e, err := getZSet(d, args[1])
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		c.w.strings(nil)
+		return nil
+	}
+
+	members := e.sorted()
+	if strings.EqualFold(args[0], "zpopmax") {
+		slices.Reverse(members)
+	}
+	members = members[:min(int(count), len(members))]
+	for _, m := range members {
+		delete(e.zset, m.member)
+	}
+	d.modified(args[1])
+	c.w.members(members, true)
+	return nil
+}
+
+// cmdZStore ZUNIONSTORE/ZINTERSTORE destination numkeys key [key ...] [WEIGHTS weight ...] [AGGREGATE SUM|MIN|MAX]
+// 源键可以是集合，成员分数视为 1
+func cmdZStore(c *conn, args []string) error {
+	numKeys, err := strconv.Atoi(args[2])
+	if err != nil || numKeys < 1 {
+		return errors.New("ERR at least 1 input key is needed for '" + strings.ToLower(args[0]) + "' command")
+	}
+	if 3+numKeys > len(args) {
+		return errSyntax
+	}
+	keys := args[3 : 3+numKeys]
+
+	weights := make([]float64, numKeys)
+	for i := range weights {
+		weights[i] = 1
+	}
+	aggregate := "sum"
+	for i := 3 + numKeys; i < len(args); i++ {
+		switch strings.ToLower(args[i]) {
+		case "weights":
+			if i+numKeys >= len(args) {
+				return errSyntax
+			}
+			for j := range weights {
+				if weights[j], err = parseFloat(args[i+1+j]); err != nil {
+					return errors.New("ERR weight value is not a float")
+				}
+			}
+			i += numKeys
+		case "aggregate":
+			if i+1 >= len(args) {
+				return errSyntax
+			}
+			aggregate = strings.ToLower(args[i+1])
+			if aggregate != "sum" && aggregate != "min" && aggregate != "max" {
+				return errSyntax
+			}
+			i++
+		default:
+			return errSyntax
+		}
+	}
+
+	d := c.db()
+	sources := make([]map[string]float64, numKeys)
+	for i, key := range keys {
+		e := d.get(key)
+		switch {
+		case e == nil:
+			sources[i] = map[string]float64{}
+		case e.kind == kindZSet:
+			sources[i] = e.zset
+		case e.kind == kindSet:
+			sources[i] = make(map[string]float64, len(e.set))
+			for member := range e.set {
+				sources[i][member] = 1
+			}
+		default:
+			return errWrongType
+		}
+	}
+
+	union := strings.EqualFold(args[0], "zunionstore")
+	result := make(map[string]float64)
+	counts := make(map[string]int)
+	for i, source := range sources {
+		for member, score := range source {
+			score *= weights[i]
+			if math.IsNaN(score) {
+				score = 0
+			}
+			old, ok := result[member]
+			counts[member]++
+			if !ok {
+				result[member] = score
+				continue
+			}
+			switch aggregate {
+			case "min":
+				result[member] = min(old, score)
+			case "max":
+				result[member] = max(old, score)
+			default:
+				if result[member] = old + score; math.IsNaN(result[member]) {
+					result[member] = 0
+				}
+			}
+		}
+	}
+	if !union {
+		for member, n := range counts {
+			if n < numKeys {
+				delete(result, member)
+			}
+		}
+	}
+
+	if len(result) == 0 {
+		d.remove(args[1])
+	} else {
+		e := newEntry(kindZSet)
+		e.zset = result
+		d.set(args[1], e)
+	}
+	c.w.int(int64(len(result)))
+	return nil
+}
+
+// cmdZScan ZSCAN key cursor [MATCH pattern] [COUNT count]
+func cmdZScan(c *conn, args []string) error {
+	e, err := getZSet(c.db(), args[1])
+	if err != nil {
+		return err
+	}
+	var members, scores []string
+	if e != nil {
+		for _, m := range e.sorted() {
+			members = append(members, m.member)
+			scores = append(scores, formatFloat(m.score))
+		}
+	}
+	return c.scanReply(args[2], args[3:], members, scores)
+}
@@ -0,0 +1,40 @@
+package redistest_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nilchaosky/go-nexus/redis"
+	"github.com/nilchaosky/go-nexus/redis/redistest"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Example 演示通过时钟控制键的过期
+func Example() {
+	ctx := context.Background()
+	server, err := redistest.NewServer()
+	if err != nil {
+		panic(err)
+	}
+	defer server.Close()
+
+	client := redis.NewClient(goredis.NewClient(&goredis.Options{
+		Addr:            server.Addr(),
+		Protocol:        2,
+		DisableIdentity: true,
+	}))
+	defer client.Close()
+
+	_ = client.SetEX(ctx, "key", "value", time.Minute)
+	value, _ := client.Get(ctx, "key")
+	fmt.Println(value)
+
+	server.Clock().Advance(time.Minute)
+	_, err = client.Get(ctx, "key")
+	fmt.Println(redis.IsNotFound(err))
+
+	// Output:
+	// value
+	// true
+}
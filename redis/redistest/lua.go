@@ -0,0 +1,897 @@
+package redistest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 本文件实现 EVAL 使用的 Lua 5.1 子集的词法与语法分析
+// 支持完整的表达式与语句语法（局部变量、闭包、可变参数、数值与泛型 for、repeat 等），
+// 不支持 goto 和元表
+
+// luaTokenKind 词法单元类型
+type luaTokenKind int
+
+const (
+	tokEOF luaTokenKind = iota
+	tokName
+	tokNumber
+	tokString
+	tokKeyword
+	tokSymbol
+)
+
+// luaKeywords Lua 关键字
+var luaKeywords = map[string]bool{
+	"and": true, "break": true, "do": true, "else": true, "elseif": true, "end": true,
+	"false": true, "for": true, "function": true, "if": true, "in": true, "local": true,
+	"nil": true, "not": true, "or": true, "repeat": true, "return": true, "then": true,
+	"true": true, "until": true, "while": true,
+}
+
+// luaToken 词法单元
+type luaToken struct {
+	kind luaTokenKind
+	text string  // 名称、关键字、符号或字符串内容
+	num  float64 // 数值
+	line int
+}
+
+// luaSyntaxError 语法错误
+type luaSyntaxError struct {
+	line int
+	msg  string
+}
+
+// Error 返回与 Lua 一致的错误信息
+func (e *luaSyntaxError) Error() string {
+	return fmt.Sprintf("user_script:%d: %s", e.line, e.msg)
+}
+
+// luaLexer 词法分析器
+type luaLexer struct {
+	src  string
+	pos  int
+	line int
+}
+
+// errorf 抛出语法错误
+func (l *luaLexer) errorf(format string, args ...interface{}) {
+	panic(&luaSyntaxError{line: l.line, msg: fmt.Sprintf(format, args...)})
+}
+
+// tokenize 将源码切分为词法单元
+func (l *luaLexer) tokenize() []luaToken {
+	var tokens []luaToken
+	for {
+		tok := l.next()
+		tokens = append(tokens, tok)
+		if tok.kind == tokEOF {
+			return tokens
+		}
+	}
+}
+
+// next 读取下一个词法单元
+func (l *luaLexer) next() luaToken {
+	l.skipSpace()
+	if l.pos >= len(l.src) {
+		return luaToken{kind: tokEOF, line: l.line}
+	}
+
+	c := l.src[l.pos]
+	line := l.line
+	switch {
+	case isLuaNameStart(c):
+		start := l.pos
+		for l.pos < len(l.src) && isLuaNameChar(l.src[l.pos]) {
+			l.pos++
+		}
+		word := l.src[start:l.pos]
+		if luaKeywords[word] {
+			return luaToken{kind: tokKeyword, text: word, line: line}
+		}
+		return luaToken{kind: tokName, text: word, line: line}
+	case isDigit(c) || (c == '.' && l.pos+1 < len(l.src) && isDigit(l.src[l.pos+1])):
+		return l.number()
+	case c == '"' || c == '\'':
+		return luaToken{kind: tokString, text: l.quoted(c), line: line}
+	case c == '[' && l.longBracketLevel() >= 0:
+		return luaToken{kind: tokString, text: l.longString(), line: line}
+	}
+
+	for _, sym := range []string{"...", "..", "==", "~=", "<=", ">="} {
+		if strings.HasPrefix(l.src[l.pos:], sym) {
+			l.pos += len(sym)
+			return luaToken{kind: tokSymbol, text: sym, line: line}
+		}
+	}
+	if strings.IndexByte("+-*/%^#<>=(){}[];:,.", c) >= 0 {
+		l.pos++
+		return luaToken{kind: tokSymbol, text: string(c), line: line}
+	}
+	l.errorf("unexpected symbol near '%c'", c)
+	return luaToken{}
+}
+
+// skipSpace 跳过空白与注释
+func (l *luaLexer) skipSpace() {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\n':
+			l.line++
+			l.pos++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			l.pos++
+		case strings.HasPrefix(l.src[l.pos:], "--"):
+			l.pos += 2
+			if l.pos < len(l.src) && l.src[l.pos] == '[' && l.longBracketLevel() >= 0 {
+				l.longString()
+				continue
+			}
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		case c == '#' && l.pos == 0 && strings.HasPrefix(l.src, "#!"):
+			// 首行的 shebang
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// number 读取数值（十进制、十六进制与科学计数法）
+func (l *luaLexer) number() luaToken {
+	start := l.pos
+	if strings.HasPrefix(l.src[l.pos:], "0x") || strings.HasPrefix(l.src[l.pos:], "0X") {
+		l.pos += 2
+		for l.pos < len(l.src) && isHexDigit(l.src[l.pos]) {
+			l.pos++
+		}
+	} else {
+		for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || l.src[l.pos] == '.') {
+			l.pos++
+		}
+		if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+			l.pos++
+			if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+				l.pos++
+			}
+			for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+				l.pos++
+			}
+		}
+	}
+	for l.pos < len(l.src) && isLuaNameChar(l.src[l.pos]) {
+		l.pos++
+	}
+
+	text := l.src[start:l.pos]
+	v, ok := luaParseNumber(text)
+	if !ok {
+		l.errorf("malformed number near '%s'", text)
+	}
+	return luaToken{kind: tokNumber, num: v, text: text, line: l.line}
+}
+
+// quoted 读取单引号或双引号字符串，处理转义
+func (l *luaLexer) quoted(quote byte) string {
+	l.pos++
+	var b strings.Builder
+	for {
+		if l.pos >= len(l.src) || l.src[l.pos] == '\n' {
+			l.errorf("unfinished string")
+		}
+		c := l.src[l.pos]
+		if c == quote {
+			l.pos++
+			return b.String()
+		}
+		if c != '\\' {
+			b.WriteByte(c)
+			l.pos++
+			continue
+		}
+
+		l.pos++
+		if l.pos >= len(l.src) {
+			l.errorf("unfinished string")
+		}
+		c = l.src[l.pos]
+		switch c {
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'v':
+			b.WriteByte('\v')
+		case '\n':
+			b.WriteByte('\n')
+			l.line++
+		default:
+			if !isDigit(c) {
+				b.WriteByte(c)
+				break
+			}
+			// \ddd 十进制字符码
+			n := 0
+			for i := 0; i < 3 && l.pos < len(l.src) && isDigit(l.src[l.pos]); i++ {
+				n = n*10 + int(l.src[l.pos]-'0')
+				l.pos++
+			}
+			if n > 255 {
+				l.errorf("escape sequence too large")
+			}
+			b.WriteByte(byte(n))
+			continue
+		}
+		l.pos++
+	}
+}
+
+// longBracketLevel 获取当前位置长括号 [==[ 的等号数量，不是长括号时返回-1
+func (l *luaLexer) longBracketLevel() int {
+	i := l.pos + 1
+	for i < len(l.src) && l.src[i] == '=' {
+		i++
+	}
+	if i < len(l.src) && l.src[i] == '[' {
+		return i - l.pos - 1
+	}
+	return -1
+}
+
+// longString 读取长括号字符串 [[...]]，忽略紧随开括号的换行
+func (l *luaLexer) longString() string {
+	level := l.longBracketLevel()
+	l.pos += level + 2
+	if strings.HasPrefix(l.src[l.pos:], "\r\n") {
+		l.pos += 2
+		l.line++
+	} else if l.pos < len(l.src) && l.src[l.pos] == '\n' {
+		l.pos++
+		l.line++
+	}
+
+	closing := "]" + strings.Repeat("=", level) + "]"
+	end := strings.Index(l.src[l.pos:], closing)
+	if end < 0 {
+		l.errorf("unfinished long string")
+	}
+	s := l.src[l.pos : l.pos+end]
+	l.line += strings.Count(s, "\n")
+	l.pos += end + len(closing)
+	return s
+}
+
+// isLuaNameStart 判断字符能否作为名称的首字符
+func isLuaNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isLuaNameChar 判断字符能否出现在名称中
+func isLuaNameChar(c byte) bool {
+	return isLuaNameStart(c) || isDigit(c)
+}
+
+// isDigit 判断是否为十进制数字
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isHexDigit 判断是否为十六进制数字
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// luaParseNumber 按 Lua 规则解析数值字符串（允许首尾空白和十六进制整数）
+func luaParseNumber(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	neg := false
+	body := s
+	if body[0] == '-' || body[0] == '+' {
+		neg = body[0] == '-'
+		body = body[1:]
+	}
+	if strings.HasPrefix(body, "0x") || strings.HasPrefix(body, "0X") {
+		n, err := strconv.ParseUint(body[2:], 16, 64)
+		if err != nil {
+			return 0, false
+		}
+		if neg {
+			return -float64(n), true
+		}
+		return float64(n), true
+	}
+	// 不接受 inf、nan 等非数字写法
+	for i := 0; i < len(body); i++ {
+		if c := body[i]; !isDigit(c) && c != '.' && c != 'e' && c != 'E' && c != '+' && c != '-' {
+			return 0, false
+		}
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// luaBlock 语句块
+type luaBlock struct {
+	stmts []luaStmt
+}
+
+// luaStmt 语句及其所在行
+type luaStmt struct {
+	line int
+	node interface{}
+}
+
+// 语句节点
+type (
+	stmtLocal struct {
+		names []string
+		exprs []luaExpr
+	}
+	stmtAssign struct {
+		targets []luaExpr
+		exprs   []luaExpr
+	}
+	stmtCall struct {
+		call luaExpr
+	}
+	stmtDo struct {
+		body *luaBlock
+	}
+	stmtWhile struct {
+		cond luaExpr
+		body *luaBlock
+	}
+	stmtRepeat struct {
+		body *luaBlock
+		cond luaExpr
+	}
+	stmtIf struct {
+		conds    []luaExpr
+		blocks   []*luaBlock
+		elseBody *luaBlock
+	}
+	stmtNumFor struct {
+		name              string
+		start, stop, step luaExpr
+		body              *luaBlock
+	}
+	stmtGenFor struct {
+		names []string
+		exprs []luaExpr
+		body  *luaBlock
+	}
+	stmtLocalFunction struct {
+		name string
+		fn   *luaProto
+	}
+	stmtReturn struct {
+		exprs []luaExpr
+	}
+	stmtBreak struct{}
+)
+
+// luaExpr 表达式节点
+type luaExpr interface{}
+
+// 表达式节点
+type (
+	exprNil      struct{}
+	exprTrue     struct{}
+	exprFalse    struct{}
+	exprVararg   struct{}
+	exprNumber   struct{ v float64 }
+	exprString   struct{ v string }
+	exprName     struct{ name string }
+	exprFunction struct{ proto *luaProto }
+	exprParen    struct{ e luaExpr }
+	exprIndex    struct {
+		obj, key luaExpr
+	}
+	exprCall struct {
+		fn   luaExpr
+		args []luaExpr
+	}
+	exprMethod struct {
+		obj  luaExpr
+		name string
+		args []luaExpr
+	}
+	exprTable struct {
+		fields []tableField
+	}
+	exprBinary struct {
+		op   string
+		l, r luaExpr
+	}
+	exprUnary struct {
+		op string
+		e  luaExpr
+	}
+)
+
+// tableField 表构造器的字段，key 为nil时为数组元素
+type tableField struct {
+	key   luaExpr
+	value luaExpr
+}
+
+// luaProto 函数原型
+type luaProto struct {
+	params []string
+	vararg bool
+	body   *luaBlock
+}
+
+// luaParser 语法分析器
+type luaParser struct {
+	tokens []luaToken
+	pos    int
+}
+
+// compileLua 编译脚本为主函数原型（可变参数函数）
+func compileLua(src string) (proto *luaProto, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			se, ok := r.(*luaSyntaxError)
+			if !ok {
+				panic(r)
+			}
+			err = se
+		}
+	}()
+
+	lexer := &luaLexer{src: src, line: 1}
+	p := &luaParser{tokens: lexer.tokenize()}
+	body := p.block()
+	if tok := p.peek(); tok.kind != tokEOF {
+		p.errorf("'<eof>' expected near '%s'", tok.text)
+	}
+	return &luaProto{vararg: true, body: body}, nil
+}
+
+// errorf 抛出语法错误
+func (p *luaParser) errorf(format string, args ...interface{}) {
+	panic(&luaSyntaxError{line: p.peek().line, msg: fmt.Sprintf(format, args...)})
+}
+
+// peek 查看当前词法单元
+func (p *luaParser) peek() luaToken {
+	return p.tokens[p.pos]
+}
+
+// advance 读取当前词法单元并前进
+func (p *luaParser) advance() luaToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// check 判断当前词法单元是否为指定关键字或符号
+func (p *luaParser) check(text string) bool {
+	tok := p.peek()
+	return (tok.kind == tokKeyword || tok.kind == tokSymbol) && tok.text == text
+}
+
+// accept 当前词法单元为指定关键字或符号时前进并返回true
+func (p *luaParser) accept(text string) bool {
+	if p.check(text) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// expect 要求当前词法单元为指定关键字或符号
+func (p *luaParser) expect(text string) {
+	if !p.accept(text) {
+		p.errorf("'%s' expected near '%s'", text, p.describe())
+	}
+}
+
+// name 要求当前词法单元为名称
+func (p *luaParser) name() string {
+	tok := p.peek()
+	if tok.kind != tokName {
+		p.errorf("<name> expected near '%s'", p.describe())
+	}
+	p.pos++
+	return tok.text
+}
+
+// describe 描述当前词法单元，用于错误信息
+func (p *luaParser) describe() string {
+	tok := p.peek()
+	switch tok.kind {
+	case tokEOF:
+		return "<eof>"
+	case tokNumber:
+		return tok.text
+	default:
+		return tok.text
+	}
+}
+
+// blockEnd 判断是否到达语句块结尾
+func (p *luaParser) blockEnd() bool {
+	tok := p.peek()
+	if tok.kind == tokEOF {
+		return true
+	}
+	if tok.kind != tokKeyword {
+		return false
+	}
+	switch tok.text {
+	case "end", "else", "elseif", "until":
+		return true
+	}
+	return false
+}
+
+// block 解析语句块，return 和 break 必须是块的最后一条语句
+func (p *luaParser) block() *luaBlock {
+	b := &luaBlock{}
+	for !p.blockEnd() {
+		line := p.peek().line
+		if p.accept("return") {
+			var exprs []luaExpr
+			if !p.blockEnd() && !p.check(";") {
+				exprs = p.exprList()
+			}
+			p.accept(";")
+			b.stmts = append(b.stmts, luaStmt{line: line, node: &stmtReturn{exprs: exprs}})
+			if !p.blockEnd() {
+				p.errorf("'end' expected near '%s'", p.describe())
+			}
+			return b
+		}
+		if p.accept("break") {
+			p.accept(";")
+			b.stmts = append(b.stmts, luaStmt{line: line, node: &stmtBreak{}})
+			if !p.blockEnd() {
+				p.errorf("'end' expected near '%s'", p.describe())
+			}
+			return b
+		}
+		if node := p.statement(); node != nil {
+			b.stmts = append(b.stmts, luaStmt{line: line, node: node})
+		}
+		p.accept(";")
+	}
+	return b
+}
+
+// statement 解析单条语句
+func (p *luaParser) statement() interface{} {
+	switch {
+	case p.accept("do"):
+		body := p.block()
+		p.expect("end")
+		return &stmtDo{body: body}
+	case p.accept("while"):
+		cond := p.expr(0)
+		p.expect("do")
+		body := p.block()
+		p.expect("end")
+		return &stmtWhile{cond: cond, body: body}
+	case p.accept("repeat"):
+		body := p.block()
+		p.expect("until")
+		return &stmtRepeat{body: body, cond: p.expr(0)}
+	case p.accept("if"):
+		s := &stmtIf{}
+		for {
+			s.conds = append(s.conds, p.expr(0))
+			p.expect("then")
+			s.blocks = append(s.blocks, p.block())
+			if !p.accept("elseif") {
+				break
+			}
+		}
+		if p.accept("else") {
+			s.elseBody = p.block()
+		}
+		p.expect("end")
+		return s
+	case p.accept("for"):
+		return p.forStatement()
+	case p.accept("function"):
+		return p.functionStatement()
+	case p.accept("local"):
+		if p.accept("function") {
+			name := p.name()
+			return &stmtLocalFunction{name: name, fn: p.funcBody(false)}
+		}
+		s := &stmtLocal{names: []string{p.name()}}
+		for p.accept(",") {
+			s.names = append(s.names, p.name())
+		}
+		if p.accept("=") {
+			s.exprs = p.exprList()
+		}
+		return s
+	}
+
+	// 赋值或函数调用
+	e := p.suffixedExpr()
+	if p.check("=") || p.check(",") {
+		targets := []luaExpr{e}
+		for p.accept(",") {
+			targets = append(targets, p.suffixedExpr())
+		}
+		for _, t := range targets {
+			switch t.(type) {
+			case *exprName, *exprIndex:
+			default:
+				p.errorf("syntax error near '%s'", p.describe())
+			}
+		}
+		p.expect("=")
+		return &stmtAssign{targets: targets, exprs: p.exprList()}
+	}
+	switch e.(type) {
+	case *exprCall, *exprMethod:
+		return &stmtCall{call: e}
+	}
+	p.errorf("syntax error near '%s'", p.describe())
+	return nil
+}
+
+// forStatement 解析数值 for 或泛型 for
+func (p *luaParser) forStatement() interface{} {
+	first := p.name()
+	if p.accept("=") {
+		s := &stmtNumFor{name: first, start: p.expr(0)}
+		p.expect(",")
+		s.stop = p.expr(0)
+		if p.accept(",") {
+			s.step = p.expr(0)
+		}
+		p.expect("do")
+		s.body = p.block()
+		p.expect("end")
+		return s
+	}
+
+	s := &stmtGenFor{names: []string{first}}
+	for p.accept(",") {
+		s.names = append(s.names, p.name())
+	}
+	p.expect("in")
+	s.exprs = p.exprList()
+	p.expect("do")
+	s.body = p.block()
+	p.expect("end")
+	return s
+}
+
+// functionStatement 解析 function a.b.c:m() ... end，转换为赋值语句
+func (p *luaParser) functionStatement() interface{} {
+	var target luaExpr = &exprName{name: p.name()}
+	method := false
+	for {
+		if p.accept(".") {
+			target = &exprIndex{obj: target, key: &exprString{v: p.name()}}
+			continue
+		}
+		if p.accept(":") {
+			target = &exprIndex{obj: target, key: &exprString{v: p.name()}}
+			method = true
+		}
+		break
+	}
+	fn := p.funcBody(method)
+	return &stmtAssign{targets: []luaExpr{target}, exprs: []luaExpr{&exprFunction{proto: fn}}}
+}
+
+// funcBody 解析参数列表和函数体，method 为true时添加隐式参数 self
+func (p *luaParser) funcBody(method bool) *luaProto {
+	proto := &luaProto{}
+	if method {
+		proto.params = append(proto.params, "self")
+	}
+	p.expect("(")
+	if !p.check(")") {
+		for {
+			if p.accept("...") {
+				proto.vararg = true
+				break
+			}
+			proto.params = append(proto.params, p.name())
+			if !p.accept(",") {
+				break
+			}
+		}
+	}
+	p.expect(")")
+	proto.body = p.block()
+	p.expect("end")
+	return proto
+}
+
+// exprList 解析逗号分隔的表达式列表
+func (p *luaParser) exprList() []luaExpr {
+	exprs := []luaExpr{p.expr(0)}
+	for p.accept(",") {
+		exprs = append(exprs, p.expr(0))
+	}
+	return exprs
+}
+
+// luaBinaryPriority 二元运算符的左右优先级（右结合的运算符右优先级较低）
+var luaBinaryPriority = map[string][2]int{
+	"or": {1, 1}, "and": {2, 2},
+	"<": {3, 3}, ">": {3, 3}, "<=": {3, 3}, ">=": {3, 3}, "~=": {3, 3}, "==": {3, 3},
+	"..": {5, 4},
+	"+":  {6, 6}, "-": {6, 6},
+	"*": {7, 7}, "/": {7, 7}, "%": {7, 7},
+	"^": {10, 9},
+}
+
+// luaUnaryPriority 一元运算符优先级
+const luaUnaryPriority = 8
+
+// expr 按优先级解析表达式，只处理左优先级高于 limit 的运算符
+func (p *luaParser) expr(limit int) luaExpr {
+	var left luaExpr
+	if tok := p.peek(); (tok.kind == tokKeyword && tok.text == "not") || (tok.kind == tokSymbol && (tok.text == "-" || tok.text == "#")) {
+		p.pos++
+		left = &exprUnary{op: tok.text, e: p.expr(luaUnaryPriority)}
+	} else {
+		left = p.simpleExpr()
+	}
+
+	for {
+		tok := p.peek()
+		if tok.kind != tokKeyword && tok.kind != tokSymbol {
+			return left
+		}
+		prio, ok := luaBinaryPriority[tok.text]
+		if !ok || prio[0] <= limit {
+			return left
+		}
+		p.pos++
+		right := p.expr(prio[1])
+		left = &exprBinary{op: tok.text, l: left, r: right}
+	}
+}
+
+// simpleExpr 解析字面量、函数、表构造器或后缀表达式
+func (p *luaParser) simpleExpr() luaExpr {
+	tok := p.peek()
+	switch tok.kind {
+	case tokNumber:
+		p.pos++
+		return &exprNumber{v: tok.num}
+	case tokString:
+		p.pos++
+		return &exprString{v: tok.text}
+	case tokKeyword:
+		switch tok.text {
+		case "nil":
+			p.pos++
+			return &exprNil{}
+		case "true":
+			p.pos++
+			return &exprTrue{}
+		case "false":
+			p.pos++
+			return &exprFalse{}
+		case "function":
+			p.pos++
+			return &exprFunction{proto: p.funcBody(false)}
+		}
+	case tokSymbol:
+		switch tok.text {
+		case "...":
+			p.pos++
+			return &exprVararg{}
+		case "{":
+			return p.tableConstructor()
+		}
+	}
+	return p.suffixedExpr()
+}
+
+// primaryExpr 解析名称或括号表达式
+func (p *luaParser) primaryExpr() luaExpr {
+	if p.accept("(") {
+		e := p.expr(0)
+		p.expect(")")
+		return &exprParen{e: e}
+	}
+	if p.peek().kind == tokName {
+		return &exprName{name: p.name()}
+	}
+	p.errorf("unexpected symbol near '%s'", p.describe())
+	return nil
+}
+
+// suffixedExpr 解析带索引、方法调用和函数调用后缀的表达式
+func (p *luaParser) suffixedExpr() luaExpr {
+	e := p.primaryExpr()
+	for {
+		switch {
+		case p.accept("."):
+			e = &exprIndex{obj: e, key: &exprString{v: p.name()}}
+		case p.accept("["):
+			key := p.expr(0)
+			p.expect("]")
+			e = &exprIndex{obj: e, key: key}
+		case p.accept(":"):
+			name := p.name()
+			e = &exprMethod{obj: e, name: name, args: p.callArgs()}
+		case p.check("(") || p.check("{") || p.peek().kind == tokString:
+			e = &exprCall{fn: e, args: p.callArgs()}
+		default:
+			return e
+		}
+	}
+}
+
+// callArgs 解析调用参数：(args)、表构造器或字符串字面量
+func (p *luaParser) callArgs() []luaExpr {
+	if tok := p.peek(); tok.kind == tokString {
+		p.pos++
+		return []luaExpr{&exprString{v: tok.text}}
+	}
+	if p.check("{") {
+		return []luaExpr{p.tableConstructor()}
+	}
+	p.expect("(")
+	if p.accept(")") {
+		return nil
+	}
+	args := p.exprList()
+	p.expect(")")
+	return args
+}
+
+// tableConstructor 解析表构造器 {a, b, k = v, [k] = v}
+func (p *luaParser) tableConstructor() luaExpr {
+	p.expect("{")
+	t := &exprTable{}
+	for !p.check("}") {
+		switch {
+		case p.accept("["):
+			key := p.expr(0)
+			p.expect("]")
+			p.expect("=")
+			t.fields = append(t.fields, tableField{key: key, value: p.expr(0)})
+		case p.peek().kind == tokName && p.tokens[p.pos+1].kind == tokSymbol && p.tokens[p.pos+1].text == "=":
+			key := p.name()
+			p.expect("=")
+			t.fields = append(t.fields, tableField{key: &exprString{v: key}, value: p.expr(0)})
+		default:
+			t.fields = append(t.fields, tableField{value: p.expr(0)})
+		}
+		if !p.accept(",") && !p.accept(";") {
+			break
+		}
+	}
+	p.expect("}")
+	return t
+}
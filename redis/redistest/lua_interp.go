@@ -0,0 +1,833 @@
+package redistest
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// luaValue Lua 值：nil、bool、float64、string、*luaTable、*luaClosure、*luaGoFunction
+type luaValue interface{}
+
+// luaMaxDepth 函数调用的最大嵌套深度
+const luaMaxDepth = 200
+
+// luaError 脚本运行时错误，value 为 error() 抛出的值
+type luaError struct {
+	value luaValue
+}
+
+// luaGoFunction 内置函数
+type luaGoFunction struct {
+	name string
+	fn   func(it *luaInterp, args []luaValue) []luaValue
+}
+
+// luaClosure 脚本定义的函数
+type luaClosure struct {
+	proto *luaProto
+	env   *luaEnv
+}
+
+// luaCell 局部变量的存储单元，闭包共享同一单元
+type luaCell struct {
+	v luaValue
+}
+
+// luaEnv 局部变量作用域链，每个局部变量声明追加一个节点
+type luaEnv struct {
+	name   string
+	cell   *luaCell
+	parent *luaEnv
+}
+
+// lookup 查找局部变量
+func (e *luaEnv) lookup(name string) *luaCell {
+	for ; e != nil; e = e.parent {
+		if e.name == name {
+			return e.cell
+		}
+	}
+	return nil
+}
+
+// define 声明局部变量，返回新的作用域
+func (e *luaEnv) define(name string, v luaValue) *luaEnv {
+	return &luaEnv{name: name, cell: &luaCell{v: v}, parent: e}
+}
+
+// luaTable Lua 表：连续整数键 1..n 存放在数组部分，其他键按插入顺序存放在哈希部分
+type luaTable struct {
+	arr      []luaValue
+	hash     map[luaValue]luaValue
+	keys     []luaValue       // 哈希部分的键（按插入顺序，可能包含已删除的键）
+	index    map[luaValue]int // 键在 keys 中的位置
+	readonly bool             // 只读表（全局变量表和标准库）
+}
+
+// newLuaTable 创建空表
+func newLuaTable() *luaTable {
+	return &luaTable{hash: make(map[luaValue]luaValue), index: make(map[luaValue]int)}
+}
+
+// arrayIndex 判断键是否为数组部分的下标（1..len(arr)+1），返回从0开始的下标
+func (t *luaTable) arrayIndex(key luaValue) (int, bool) {
+	f, ok := key.(float64)
+	if !ok || f != math.Trunc(f) || f < 1 || f > float64(len(t.arr)+1) {
+		return 0, false
+	}
+	return int(f) - 1, true
+}
+
+// get 读取键对应的值
+func (t *luaTable) get(key luaValue) luaValue {
+	if i, ok := t.arrayIndex(key); ok {
+		if i < len(t.arr) {
+			return t.arr[i]
+		}
+		return nil
+	}
+	if key == nil {
+		return nil
+	}
+	return t.hash[key]
+}
+
+// set 写入键值，值为nil时删除
+func (t *luaTable) set(key, value luaValue) {
+	if i, ok := t.arrayIndex(key); ok {
+		switch {
+		case i < len(t.arr):
+			t.arr[i] = value
+			for len(t.arr) > 0 && t.arr[len(t.arr)-1] == nil {
+				t.arr = t.arr[:len(t.arr)-1]
+			}
+		case value != nil:
+			// 追加到数组部分，并迁移哈希部分中随后的连续整数键
+			t.arr = append(t.arr, value)
+			delete(t.hash, key)
+			for {
+				next := float64(len(t.arr) + 1)
+				v, ok := t.hash[next]
+				if !ok {
+					break
+				}
+				t.arr = append(t.arr, v)
+				delete(t.hash, next)
+			}
+		}
+		return
+	}
+
+	if value == nil {
+		delete(t.hash, key)
+		return
+	}
+	if _, ok := t.index[key]; !ok {
+		t.index[key] = len(t.keys)
+		t.keys = append(t.keys, key)
+	}
+	t.hash[key] = value
+}
+
+// length 获取长度（# 运算符），返回数组部分的长度
+func (t *luaTable) length() int {
+	return len(t.arr)
+}
+
+// next 获取键之后的下一个键值对，key 为nil时从头开始；遍历结束时返回 nil 键
+func (t *luaTable) next(key luaValue) (luaValue, luaValue, bool) {
+	start := 0 // 数组部分的起始下标
+	hashStart := 0
+	if key != nil {
+		if i, ok := t.arrayIndex(key); ok && i < len(t.arr) {
+			start = i + 1
+		} else {
+			pos, ok := t.index[key]
+			if !ok {
+				return nil, nil, false
+			}
+			start = len(t.arr)
+			hashStart = pos + 1
+		}
+	}
+
+	for i := start; i < len(t.arr); i++ {
+		if t.arr[i] != nil {
+			return float64(i + 1), t.arr[i], true
+		}
+	}
+	for i := hashStart; i < len(t.keys); i++ {
+		if v, ok := t.hash[t.keys[i]]; ok {
+			return t.keys[i], v, true
+		}
+	}
+	return nil, nil, true
+}
+
+// luaControl 语句执行后的控制流
+type luaControl int
+
+const (
+	ctrlNormal luaControl = iota
+	ctrlBreak
+	ctrlReturn
+)
+
+// luaFrame 函数调用帧
+type luaFrame struct {
+	varargs []luaValue
+}
+
+// luaInterp 脚本解释器，每次执行脚本创建一个
+type luaInterp struct {
+	globals *luaTable
+	line    int // 当前执行的行号，用于错误信息
+	depth   int
+}
+
+// raise 抛出带行号的运行时错误
+func (it *luaInterp) raise(format string, args ...interface{}) {
+	panic(&luaError{value: fmt.Sprintf("user_script:%d: %s", it.line, fmt.Sprintf(format, args...))})
+}
+
+// call 调用函数
+func (it *luaInterp) call(fn luaValue, args []luaValue) []luaValue {
+	switch f := fn.(type) {
+	case *luaGoFunction:
+		return f.fn(it, args)
+	case *luaClosure:
+		it.depth++
+		defer func() { it.depth-- }()
+		if it.depth > luaMaxDepth {
+			it.raise("stack overflow")
+		}
+
+		env := f.env
+		for i, name := range f.proto.params {
+			var v luaValue
+			if i < len(args) {
+				v = args[i]
+			}
+			env = env.define(name, v)
+		}
+		fr := &luaFrame{}
+		if f.proto.vararg && len(args) > len(f.proto.params) {
+			fr.varargs = args[len(f.proto.params):]
+		}
+
+		line := it.line
+		ctrl, values := it.execBlock(f.proto.body, env, fr)
+		it.line = line
+		if ctrl == ctrlReturn {
+			return values
+		}
+		return nil
+	default:
+		it.raise("attempt to call a %s value", luaTypeName(fn))
+		return nil
+	}
+}
+
+// execBlock 执行语句块
+func (it *luaInterp) execBlock(b *luaBlock, env *luaEnv, fr *luaFrame) (luaControl, []luaValue) {
+	for _, s := range b.stmts {
+		it.line = s.line
+		switch n := s.node.(type) {
+		case *stmtLocal:
+			values := it.evalList(n.exprs, env, fr, len(n.names))
+			for i, name := range n.names {
+				env = env.define(name, values[i])
+			}
+		case *stmtLocalFunction:
+			env = env.define(n.name, nil)
+			env.cell.v = &luaClosure{proto: n.fn, env: env}
+		case *stmtAssign:
+			it.assign(n, env, fr)
+		case *stmtCall:
+			it.evalMulti(n.call, env, fr)
+		case *stmtDo:
+			if ctrl, values := it.execBlock(n.body, env, fr); ctrl != ctrlNormal {
+				return ctrl, values
+			}
+		case *stmtWhile:
+			for luaTruthy(it.eval(n.cond, env, fr)) {
+				ctrl, values := it.execBlock(n.body, env, fr)
+				if ctrl == ctrlBreak {
+					break
+				}
+				if ctrl == ctrlReturn {
+					return ctrl, values
+				}
+			}
+		case *stmtRepeat:
+			if ctrl, values := it.execRepeat(n, env, fr); ctrl == ctrlReturn {
+				return ctrl, values
+			}
+		case *stmtIf:
+			body := n.elseBody
+			for i, cond := range n.conds {
+				if luaTruthy(it.eval(cond, env, fr)) {
+					body = n.blocks[i]
+					break
+				}
+			}
+			if body != nil {
+				if ctrl, values := it.execBlock(body, env, fr); ctrl != ctrlNormal {
+					return ctrl, values
+				}
+			}
+		case *stmtNumFor:
+			if ctrl, values := it.execNumFor(n, env, fr); ctrl == ctrlReturn {
+				return ctrl, values
+			}
+		case *stmtGenFor:
+			if ctrl, values := it.execGenFor(n, env, fr); ctrl == ctrlReturn {
+				return ctrl, values
+			}
+		case *stmtReturn:
+			if len(n.exprs) == 1 {
+				// 尾调用直接返回所有结果
+				return ctrlReturn, it.evalMulti(n.exprs[0], env, fr)
+			}
+			return ctrlReturn, it.evalList(n.exprs, env, fr, -1)
+		case *stmtBreak:
+			return ctrlBreak, nil
+		}
+	}
+	return ctrlNormal, nil
+}
+
+// execRepeat 执行 repeat ... until，条件表达式可以访问循环体内的局部变量
+func (it *luaInterp) execRepeat(n *stmtRepeat, env *luaEnv, fr *luaFrame) (luaControl, []luaValue) {
+	for {
+		// 循环体内声明的局部变量对 until 可见，因此逐条执行
+		inner := env
+		for _, s := range n.body.stmts {
+			it.line = s.line
+			if local, ok := s.node.(*stmtLocal); ok {
+				values := it.evalList(local.exprs, inner, fr, len(local.names))
+				for i, name := range local.names {
+					inner = inner.define(name, values[i])
+				}
+				continue
+			}
+			if fn, ok := s.node.(*stmtLocalFunction); ok {
+				inner = inner.define(fn.name, nil)
+				inner.cell.v = &luaClosure{proto: fn.fn, env: inner}
+				continue
+			}
+			ctrl, values := it.execBlock(&luaBlock{stmts: []luaStmt{s}}, inner, fr)
+			if ctrl == ctrlBreak {
+				return ctrlNormal, nil
+			}
+			if ctrl == ctrlReturn {
+				return ctrl, values
+			}
+		}
+		if luaTruthy(it.eval(n.cond, inner, fr)) {
+			return ctrlNormal, nil
+		}
+	}
+}
+
+// execNumFor 执行数值 for 循环
+func (it *luaInterp) execNumFor(n *stmtNumFor, env *luaEnv, fr *luaFrame) (luaControl, []luaValue) {
+	start, ok1 := luaToNumber(it.eval(n.start, env, fr))
+	stop, ok2 := luaToNumber(it.eval(n.stop, env, fr))
+	step, ok3 := 1.0, true
+	if n.step != nil {
+		step, ok3 = luaToNumber(it.eval(n.step, env, fr))
+	}
+	switch {
+	case !ok1:
+		it.raise("'for' initial value must be a number")
+	case !ok2:
+		it.raise("'for' limit must be a number")
+	case !ok3:
+		it.raise("'for' step must be a number")
+	}
+
+	for i := start; (step > 0 && i <= stop) || (step <= 0 && i >= stop); i += step {
+		ctrl, values := it.execBlock(n.body, env.define(n.name, i), fr)
+		if ctrl == ctrlBreak {
+			break
+		}
+		if ctrl == ctrlReturn {
+			return ctrl, values
+		}
+	}
+	return ctrlNormal, nil
+}
+
+// execGenFor 执行泛型 for 循环
+func (it *luaInterp) execGenFor(n *stmtGenFor, env *luaEnv, fr *luaFrame) (luaControl, []luaValue) {
+	init := it.evalList(n.exprs, env, fr, 3)
+	fn, state, control := init[0], init[1], init[2]
+	for {
+		results := it.call(fn, []luaValue{state, control})
+		if len(results) == 0 || results[0] == nil {
+			return ctrlNormal, nil
+		}
+		control = results[0]
+
+		inner := env
+		for i, name := range n.names {
+			var v luaValue
+			if i < len(results) {
+				v = results[i]
+			}
+			inner = inner.define(name, v)
+		}
+		ctrl, values := it.execBlock(n.body, inner, fr)
+		if ctrl == ctrlBreak {
+			return ctrlNormal, nil
+		}
+		if ctrl == ctrlReturn {
+			return ctrl, values
+		}
+	}
+}
+
+// assign 执行赋值语句：先求值所有表达式，再依次赋值
+func (it *luaInterp) assign(n *stmtAssign, env *luaEnv, fr *luaFrame) {
+	type target struct {
+		cell *luaCell
+		obj  luaValue
+		key  luaValue
+		name string
+	}
+	targets := make([]target, len(n.targets))
+	for i, t := range n.targets {
+		switch e := t.(type) {
+		case *exprName:
+			targets[i] = target{cell: env.lookup(e.name), name: e.name}
+		case *exprIndex:
+			targets[i] = target{obj: it.eval(e.obj, env, fr), key: it.eval(e.key, env, fr)}
+		}
+	}
+
+	values := it.evalList(n.exprs, env, fr, len(targets))
+	for i, t := range targets {
+		switch {
+		case t.cell != nil:
+			t.cell.v = values[i]
+		case t.name != "":
+			it.setIndex(it.globals, t.name, values[i])
+		default:
+			it.setIndex(t.obj, t.key, values[i])
+		}
+	}
+}
+
+// setIndex 执行 obj[key] = value
+func (it *luaInterp) setIndex(obj, key, value luaValue) {
+	t, ok := obj.(*luaTable)
+	if !ok {
+		it.raise("attempt to index a %s value", luaTypeName(obj))
+	}
+	if t.readonly {
+		it.raise("Attempt to modify a readonly table")
+	}
+	if key == nil {
+		it.raise("table index is nil")
+	}
+	if f, ok := key.(float64); ok && math.IsNaN(f) {
+		it.raise("table index is NaN")
+	}
+	t.set(key, value)
+}
+
+// index 执行 obj[key]，字符串值的索引查找 string 库
+func (it *luaInterp) index(obj, key luaValue) luaValue {
+	switch o := obj.(type) {
+	case *luaTable:
+		return o.get(key)
+	case string:
+		if lib, ok := it.globals.get("string").(*luaTable); ok {
+			return lib.get(key)
+		}
+		return nil
+	default:
+		it.raise("attempt to index a %s value", luaTypeName(obj))
+		return nil
+	}
+}
+
+// evalList 求值表达式列表，最后一个表达式展开为多个值；want 不为负数时补齐或截断到 want 个值
+func (it *luaInterp) evalList(exprs []luaExpr, env *luaEnv, fr *luaFrame, want int) []luaValue {
+	values := make([]luaValue, 0, len(exprs))
+	for i, e := range exprs {
+		if i == len(exprs)-1 {
+			values = append(values, it.evalMulti(e, env, fr)...)
+		} else {
+			values = append(values, it.eval(e, env, fr))
+		}
+	}
+	if want >= 0 {
+		for len(values) < want {
+			values = append(values, nil)
+		}
+		values = values[:want]
+	}
+	return values
+}
+
+// evalMulti 求值可能返回多个值的表达式（函数调用和 ...）
+func (it *luaInterp) evalMulti(e luaExpr, env *luaEnv, fr *luaFrame) []luaValue {
+	switch n := e.(type) {
+	case *exprCall:
+		fn := it.eval(n.fn, env, fr)
+		args := it.evalList(n.args, env, fr, -1)
+		if luaTypeName(fn) != "function" {
+			it.raise("attempt to call %s (a %s value)", describeExpr(n.fn), luaTypeName(fn))
+		}
+		return it.call(fn, args)
+	case *exprMethod:
+		obj := it.eval(n.obj, env, fr)
+		fn := it.index(obj, n.name)
+		if fn == nil {
+			it.raise("attempt to call method '%s' (a nil value)", n.name)
+		}
+		args := append([]luaValue{obj}, it.evalList(n.args, env, fr, -1)...)
+		return it.call(fn, args)
+	case *exprVararg:
+		return append([]luaValue(nil), fr.varargs...)
+	default:
+		return []luaValue{it.eval(e, env, fr)}
+	}
+}
+
+// describeExpr 描述被调用的表达式，用于错误信息
+func describeExpr(e luaExpr) string {
+	switch n := e.(type) {
+	case *exprName:
+		return "global '" + n.name + "'"
+	case *exprIndex:
+		if key, ok := n.key.(*exprString); ok {
+			return "field '" + key.v + "'"
+		}
+	}
+	return "a value"
+}
+
+// eval 求值表达式（多返回值截断为第一个值）
+func (it *luaInterp) eval(e luaExpr, env *luaEnv, fr *luaFrame) luaValue {
+	switch n := e.(type) {
+	case *exprNil:
+		return nil
+	case *exprTrue:
+		return true
+	case *exprFalse:
+		return false
+	case *exprNumber:
+		return n.v
+	case *exprString:
+		return n.v
+	case *exprVararg:
+		if len(fr.varargs) == 0 {
+			return nil
+		}
+		return fr.varargs[0]
+	case *exprName:
+		if cell := env.lookup(n.name); cell != nil {
+			return cell.v
+		}
+		v := it.globals.get(n.name)
+		if v == nil {
+			it.raise("Script attempted to access nonexistent global variable '%s'", n.name)
+		}
+		return v
+	case *exprParen:
+		return it.eval(n.e, env, fr)
+	case *exprFunction:
+		return &luaClosure{proto: n.proto, env: env}
+	case *exprIndex:
+		return it.index(it.eval(n.obj, env, fr), it.eval(n.key, env, fr))
+	case *exprCall, *exprMethod:
+		values := it.evalMulti(e, env, fr)
+		if len(values) == 0 {
+			return nil
+		}
+		return values[0]
+	case *exprTable:
+		return it.evalTable(n, env, fr)
+	case *exprUnary:
+		return it.evalUnary(n, env, fr)
+	case *exprBinary:
+		return it.evalBinary(n, env, fr)
+	}
+	it.raise("unsupported expression")
+	return nil
+}
+
+// evalTable 求值表构造器，最后一个数组元素展开为多个值
+func (it *luaInterp) evalTable(n *exprTable, env *luaEnv, fr *luaFrame) luaValue {
+	t := newLuaTable()
+	pos := 1
+	for i, f := range n.fields {
+		if f.key != nil {
+			key := it.eval(f.key, env, fr)
+			if key == nil {
+				it.raise("table index is nil")
+			}
+			t.set(key, it.eval(f.value, env, fr))
+			continue
+		}
+		if i == len(n.fields)-1 {
+			for _, v := range it.evalMulti(f.value, env, fr) {
+				t.set(float64(pos), v)
+				pos++
+			}
+			continue
+		}
+		t.set(float64(pos), it.eval(f.value, env, fr))
+		pos++
+	}
+	return t
+}
+
+// evalUnary 求值一元运算
+func (it *luaInterp) evalUnary(n *exprUnary, env *luaEnv, fr *luaFrame) luaValue {
+	v := it.eval(n.e, env, fr)
+	switch n.op {
+	case "not":
+		return !luaTruthy(v)
+	case "-":
+		f, ok := luaToNumber(v)
+		if !ok {
+			it.raise("attempt to perform arithmetic on %s", describeOperand(n.e, v))
+		}
+		return -f
+	default: // #
+		switch o := v.(type) {
+		case string:
+			return float64(len(o))
+		case *luaTable:
+			return float64(o.length())
+		}
+		it.raise("attempt to get length of %s", describeOperand(n.e, v))
+		return nil
+	}
+}
+
+// describeOperand 描述运算数，用于错误信息
+func describeOperand(e luaExpr, v luaValue) string {
+	switch n := e.(type) {
+	case *exprName:
+		return fmt.Sprintf("a %s value (variable '%s')", luaTypeName(v), n.name)
+	case *exprIndex:
+		if key, ok := n.key.(*exprString); ok {
+			return fmt.Sprintf("a %s value (field '%s')", luaTypeName(v), key.v)
+		}
+	}
+	return fmt.Sprintf("a %s value", luaTypeName(v))
+}
+
+// evalBinary 求值二元运算
+func (it *luaInterp) evalBinary(n *exprBinary, env *luaEnv, fr *luaFrame) luaValue {
+	switch n.op {
+	case "and":
+		l := it.eval(n.l, env, fr)
+		if !luaTruthy(l) {
+			return l
+		}
+		return it.eval(n.r, env, fr)
+	case "or":
+		l := it.eval(n.l, env, fr)
+		if luaTruthy(l) {
+			return l
+		}
+		return it.eval(n.r, env, fr)
+	}
+
+	l := it.eval(n.l, env, fr)
+	r := it.eval(n.r, env, fr)
+	switch n.op {
+	case "==":
+		return luaRawEqual(l, r)
+	case "~=":
+		return !luaRawEqual(l, r)
+	case "<", "<=", ">", ">=":
+		return it.compare(n.op, l, r)
+	case "..":
+		ls, ok1 := luaToString(l)
+		rs, ok2 := luaToString(r)
+		if !ok1 {
+			it.raise("attempt to concatenate %s", describeOperand(n.l, l))
+		}
+		if !ok2 {
+			it.raise("attempt to concatenate %s", describeOperand(n.r, r))
+		}
+		return ls + rs
+	}
+
+	a, ok := luaToNumber(l)
+	if !ok {
+		it.raise("attempt to perform arithmetic on %s", describeOperand(n.l, l))
+	}
+	b, ok := luaToNumber(r)
+	if !ok {
+		it.raise("attempt to perform arithmetic on %s", describeOperand(n.r, r))
+	}
+	return luaArith(n.op, a, b)
+}
+
+// luaArith 数值运算
+func luaArith(op string, a, b float64) float64 {
+	switch op {
+	case "+":
+		return a + b
+	case "-":
+		return a - b
+	case "*":
+		return a * b
+	case "/":
+		return a / b
+	case "%":
+		return a - math.Floor(a/b)*b
+	default: // ^
+		return math.Pow(a, b)
+	}
+}
+
+// compare 比较运算：只能比较两个数值或两个字符串
+func (it *luaInterp) compare(op string, l, r luaValue) bool {
+	less := func(a, b luaValue) bool {
+		switch x := a.(type) {
+		case float64:
+			if y, ok := b.(float64); ok {
+				return x < y
+			}
+		case string:
+			if y, ok := b.(string); ok {
+				return x < y
+			}
+		}
+		if luaTypeName(a) == luaTypeName(b) {
+			it.raise("attempt to compare two %s values", luaTypeName(a))
+		}
+		it.raise("attempt to compare %s with %s", luaTypeName(a), luaTypeName(b))
+		return false
+	}
+	switch op {
+	case "<":
+		return less(l, r)
+	case ">":
+		return less(r, l)
+	case "<=":
+		return !less(r, l)
+	default:
+		return !less(l, r)
+	}
+}
+
+// luaTruthy 判断值是否为真（nil 和 false 为假）
+func luaTruthy(v luaValue) bool {
+	if v == nil {
+		return false
+	}
+	if b, ok := v.(bool); ok {
+		return b
+	}
+	return true
+}
+
+// luaRawEqual 判断两个值是否相等（不转换类型）
+func luaRawEqual(a, b luaValue) bool {
+	switch x := a.(type) {
+	case float64:
+		y, ok := b.(float64)
+		return ok && x == y
+	case string:
+		y, ok := b.(string)
+		return ok && x == y
+	}
+	return a == b
+}
+
+// luaTypeName 获取 type() 返回的类型名称
+func luaTypeName(v luaValue) string {
+	switch v.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case *luaTable:
+		return "table"
+	default:
+		return "function"
+	}
+}
+
+// luaToNumber 将数值或可转换的字符串转换为数值
+func luaToNumber(v luaValue) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case string:
+		return luaParseNumber(x)
+	}
+	return 0, false
+}
+
+// luaToString 将字符串或数值转换为字符串（用于连接和库函数参数）
+func luaToString(v luaValue) (string, bool) {
+	switch x := v.(type) {
+	case string:
+		return x, true
+	case float64:
+		return luaFormatNumber(x), true
+	}
+	return "", false
+}
+
+// luaFormatNumber 按 Lua 的 %.14g 格式输出数值
+func luaFormatNumber(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case math.IsNaN(f):
+		return "nan"
+	}
+	return fmt.Sprintf("%.14g", f)
+}
+
+// luaTostring tostring() 的结果
+func luaTostring(v luaValue) string {
+	switch x := v.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return strconv.FormatBool(x)
+	case float64, string:
+		s, _ := luaToString(x)
+		return s
+	case *luaTable:
+		return fmt.Sprintf("table: %p", x)
+	case *luaGoFunction:
+		return "function: builtin: " + x.name
+	default:
+		return fmt.Sprintf("function: %p", x)
+	}
+}
+
+// luaErrorMessage 获取错误值的描述
+func luaErrorMessage(v luaValue) string {
+	if t, ok := v.(*luaTable); ok {
+		if msg, ok := t.get("err").(string); ok {
+			return msg
+		}
+	}
+	if s, ok := luaToString(v); ok {
+		return s
+	}
+	return strings.TrimSpace(luaTostring(v))
+}
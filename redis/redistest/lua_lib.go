@@ -0,0 +1,683 @@
+package redistest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 本文件实现脚本可用的标准库：基础函数、string、table、math 和 cjson
+
+// luaLibrary 创建标准库表（只读），redis 表由调用方添加
+func luaLibrary() map[string]luaValue {
+	return map[string]luaValue{
+		"assert":   goFunc("assert", luaAssert),
+		"error":    goFunc("error", luaErrorFunc),
+		"pcall":    goFunc("pcall", luaPcall),
+		"select":   goFunc("select", luaSelect),
+		"tonumber": goFunc("tonumber", luaTonumber),
+		"tostring": goFunc("tostring", func(it *luaInterp, args []luaValue) []luaValue {
+			checkAny(it, args, 1, "tostring")
+			return []luaValue{luaTostring(args[0])}
+		}),
+		"type": goFunc("type", func(it *luaInterp, args []luaValue) []luaValue {
+			checkAny(it, args, 1, "type")
+			return []luaValue{luaTypeName(args[0])}
+		}),
+		"ipairs": goFunc("ipairs", luaIpairs),
+		"pairs":  goFunc("pairs", luaPairs),
+		"next":   goFunc("next", luaNext),
+		"unpack": goFunc("unpack", luaUnpack),
+		"rawget": goFunc("rawget", func(it *luaInterp, args []luaValue) []luaValue {
+			return []luaValue{checkTable(it, args, 1, "rawget").get(arg(args, 2))}
+		}),
+		"rawequal": goFunc("rawequal", func(_ *luaInterp, args []luaValue) []luaValue {
+			return []luaValue{luaRawEqual(arg(args, 1), arg(args, 2))}
+		}),
+		"rawset": goFunc("rawset", func(it *luaInterp, args []luaValue) []luaValue {
+			t := checkTable(it, args, 1, "rawset")
+			it.setIndex(t, arg(args, 2), arg(args, 3))
+			return []luaValue{t}
+		}),
+		"string": readonlyTable(map[string]luaValue{
+			"byte":   goFunc("byte", luaStringByte),
+			"char":   goFunc("char", luaStringChar),
+			"find":   goFunc("find", func(it *luaInterp, args []luaValue) []luaValue { return luaFind(it, args, true) }),
+			"match":  goFunc("match", func(it *luaInterp, args []luaValue) []luaValue { return luaFind(it, args, false) }),
+			"gmatch": goFunc("gmatch", luaGmatch),
+			"gsub":   goFunc("gsub", luaGsub),
+			"format": goFunc("format", luaStringFormat),
+			"len": goFunc("len", func(it *luaInterp, args []luaValue) []luaValue {
+				return []luaValue{float64(len(checkString(it, args, 1, "len")))}
+			}),
+			"lower": goFunc("lower", func(it *luaInterp, args []luaValue) []luaValue {
+				return []luaValue{strings.ToLower(checkString(it, args, 1, "lower"))}
+			}),
+			"upper": goFunc("upper", func(it *luaInterp, args []luaValue) []luaValue {
+				return []luaValue{strings.ToUpper(checkString(it, args, 1, "upper"))}
+			}),
+			"rep":     goFunc("rep", luaStringRep),
+			"reverse": goFunc("reverse", luaStringReverse),
+			"sub":     goFunc("sub", luaStringSub),
+		}),
+		"table": readonlyTable(map[string]luaValue{
+			"insert": goFunc("insert", luaTableInsert),
+			"remove": goFunc("remove", luaTableRemove),
+			"concat": goFunc("concat", luaTableConcat),
+			"sort":   goFunc("sort", luaTableSort),
+			"getn": goFunc("getn", func(it *luaInterp, args []luaValue) []luaValue {
+				return []luaValue{float64(checkTable(it, args, 1, "getn").length())}
+			}),
+		}),
+		"math": readonlyTable(map[string]luaValue{
+			"abs":   mathFunc("abs", math.Abs),
+			"ceil":  mathFunc("ceil", math.Ceil),
+			"floor": mathFunc("floor", math.Floor),
+			"sqrt":  mathFunc("sqrt", math.Sqrt),
+			"exp":   mathFunc("exp", math.Exp),
+			"log":   mathFunc("log", math.Log),
+			"log10": mathFunc("log10", math.Log10),
+			"fmod": goFunc("fmod", func(it *luaInterp, args []luaValue) []luaValue {
+				return []luaValue{math.Mod(checkNumber(it, args, 1, "fmod"), checkNumber(it, args, 2, "fmod"))}
+			}),
+			"pow": goFunc("pow", func(it *luaInterp, args []luaValue) []luaValue {
+				return []luaValue{math.Pow(checkNumber(it, args, 1, "pow"), checkNumber(it, args, 2, "pow"))}
+			}),
+			"modf": goFunc("modf", func(it *luaInterp, args []luaValue) []luaValue {
+				i, f := math.Modf(checkNumber(it, args, 1, "modf"))
+				return []luaValue{i, f}
+			}),
+			"max":  goFunc("max", func(it *luaInterp, args []luaValue) []luaValue { return luaMinMax(it, args, "max") }),
+			"min":  goFunc("min", func(it *luaInterp, args []luaValue) []luaValue { return luaMinMax(it, args, "min") }),
+			"huge": math.Inf(1),
+			"pi":   math.Pi,
+		}),
+		"cjson": readonlyTable(map[string]luaValue{
+			"encode": goFunc("encode", luaJSONEncode),
+			"decode": goFunc("decode", luaJSONDecode),
+		}),
+	}
+}
+
+// goFunc 创建内置函数
+func goFunc(name string, fn func(it *luaInterp, args []luaValue) []luaValue) *luaGoFunction {
+	return &luaGoFunction{name: name, fn: fn}
+}
+
+// mathFunc 创建单参数的数学函数
+func mathFunc(name string, fn func(float64) float64) *luaGoFunction {
+	return goFunc(name, func(it *luaInterp, args []luaValue) []luaValue {
+		return []luaValue{fn(checkNumber(it, args, 1, name))}
+	})
+}
+
+// readonlyTable 创建只读表
+func readonlyTable(fields map[string]luaValue) *luaTable {
+	t := newLuaTable()
+	for name, v := range fields {
+		t.set(name, v)
+	}
+	t.readonly = true
+	return t
+}
+
+// arg 获取第n个参数（从1开始），不存在时返回nil
+func arg(args []luaValue, n int) luaValue {
+	if n > len(args) {
+		return nil
+	}
+	return args[n-1]
+}
+
+// argError 抛出参数错误
+func argError(it *luaInterp, n int, fname, msg string) {
+	it.raise("bad argument #%d to '%s' (%s)", n, fname, msg)
+}
+
+// checkAny 要求参数存在
+func checkAny(it *luaInterp, args []luaValue, n int, fname string) {
+	if n > len(args) {
+		argError(it, n, fname, "value expected")
+	}
+}
+
+// checkNumber 要求参数为数值（或可转换为数值的字符串）
+func checkNumber(it *luaInterp, args []luaValue, n int, fname string) float64 {
+	v := arg(args, n)
+	f, ok := luaToNumber(v)
+	if !ok {
+		argError(it, n, fname, "number expected, got "+luaArgType(args, n))
+	}
+	return f
+}
+
+// optNumber 获取可选的数值参数
+func optNumber(it *luaInterp, args []luaValue, n int, fname string, def float64) float64 {
+	if arg(args, n) == nil {
+		return def
+	}
+	return checkNumber(it, args, n, fname)
+}
+
+// checkString 要求参数为字符串（或数值）
+func checkString(it *luaInterp, args []luaValue, n int, fname string) string {
+	s, ok := luaToString(arg(args, n))
+	if !ok {
+		argError(it, n, fname, "string expected, got "+luaArgType(args, n))
+	}
+	return s
+}
+
+// checkTable 要求参数为表
+func checkTable(it *luaInterp, args []luaValue, n int, fname string) *luaTable {
+	t, ok := arg(args, n).(*luaTable)
+	if !ok {
+		argError(it, n, fname, "table expected, got "+luaArgType(args, n))
+	}
+	return t
+}
+
+// luaArgType 描述参数类型，缺少参数时为 no value
+func luaArgType(args []luaValue, n int) string {
+	if n > len(args) {
+		return "no value"
+	}
+	return luaTypeName(args[n-1])
+}
+
+// luaAssert assert(v [, message])
+func luaAssert(it *luaInterp, args []luaValue) []luaValue {
+	checkAny(it, args, 1, "assert")
+	if !luaTruthy(args[0]) {
+		msg := "assertion failed!"
+		if len(args) > 1 {
+			msg, _ = luaToString(args[1])
+		}
+		panic(&luaError{value: msg})
+	}
+	return args
+}
+
+// luaErrorFunc error(message [, level])，level 为1（默认）时字符串错误附加行号
+func luaErrorFunc(it *luaInterp, args []luaValue) []luaValue {
+	v := arg(args, 1)
+	level := optNumber(it, args, 2, "error", 1)
+	if s, ok := v.(string); ok && level > 0 {
+		v = fmt.Sprintf("user_script:%d: %s", it.line, s)
+	}
+	panic(&luaError{value: v})
+}
+
+// luaPcall pcall(f, ...)，捕获脚本错误并返回 false 和错误值
+func luaPcall(it *luaInterp, args []luaValue) (results []luaValue) {
+	checkAny(it, args, 1, "pcall")
+	depth, line := it.depth, it.line
+	defer func() {
+		if r := recover(); r != nil {
+			le, ok := r.(*luaError)
+			if !ok {
+				panic(r)
+			}
+			it.depth, it.line = depth, line
+			results = []luaValue{false, le.value}
+		}
+	}()
+	return append([]luaValue{true}, it.call(args[0], args[1:])...)
+}
+
+// luaSelect select(n, ...) 或 select('#', ...)
+func luaSelect(it *luaInterp, args []luaValue) []luaValue {
+	if s, ok := arg(args, 1).(string); ok && s == "#" {
+		return []luaValue{float64(len(args) - 1)}
+	}
+	n := int(checkNumber(it, args, 1, "select"))
+	if n < 0 {
+		n += len(args)
+	}
+	if n < 1 {
+		argError(it, 1, "select", "index out of range")
+	}
+	if n >= len(args) {
+		return nil
+	}
+	return args[n:]
+}
+
+// luaTonumber tonumber(v [, base])，无法转换时返回nil
+func luaTonumber(it *luaInterp, args []luaValue) []luaValue {
+	checkAny(it, args, 1, "tonumber")
+	if len(args) > 1 && args[1] != nil {
+		base := int(checkNumber(it, args, 2, "tonumber"))
+		if base < 2 || base > 36 {
+			argError(it, 2, "tonumber", "base out of range")
+		}
+		s, _ := luaToString(args[0])
+		n, err := strconv.ParseInt(strings.ToLower(strings.TrimSpace(s)), base, 64)
+		if err != nil {
+			return []luaValue{nil}
+		}
+		return []luaValue{float64(n)}
+	}
+	if f, ok := luaToNumber(args[0]); ok {
+		return []luaValue{f}
+	}
+	return []luaValue{nil}
+}
+
+// luaIpairs ipairs(t)
+func luaIpairs(it *luaInterp, args []luaValue) []luaValue {
+	t := checkTable(it, args, 1, "ipairs")
+	iter := goFunc("ipairs_iterator", func(_ *luaInterp, args []luaValue) []luaValue {
+		i := args[1].(float64) + 1
+		v := t.get(i)
+		if v == nil {
+			return []luaValue{nil}
+		}
+		return []luaValue{i, v}
+	})
+	return []luaValue{iter, t, 0.0}
+}
+
+// luaPairs pairs(t)
+func luaPairs(it *luaInterp, args []luaValue) []luaValue {
+	t := checkTable(it, args, 1, "pairs")
+	return []luaValue{it.globals.get("next"), t, nil}
+}
+
+// luaNext next(t [, key])
+func luaNext(it *luaInterp, args []luaValue) []luaValue {
+	t := checkTable(it, args, 1, "next")
+	k, v, ok := t.next(arg(args, 2))
+	if !ok {
+		it.raise("invalid key to 'next'")
+	}
+	if k == nil {
+		return []luaValue{nil}
+	}
+	return []luaValue{k, v}
+}
+
+// luaUnpack unpack(t [, i [, j]])
+func luaUnpack(it *luaInterp, args []luaValue) []luaValue {
+	t := checkTable(it, args, 1, "unpack")
+	i := int(optNumber(it, args, 2, "unpack", 1))
+	j := int(optNumber(it, args, 3, "unpack", float64(t.length())))
+	if j-i >= 8000 {
+		it.raise("too many results to unpack")
+	}
+	var values []luaValue
+	for k := i; k <= j; k++ {
+		values = append(values, t.get(float64(k)))
+	}
+	return values
+}
+
+// luaStringByte string.byte(s [, i [, j]])
+func luaStringByte(it *luaInterp, args []luaValue) []luaValue {
+	s := checkString(it, args, 1, "byte")
+	i := optNumber(it, args, 2, "byte", 1)
+	j := optNumber(it, args, 3, "byte", i)
+	start, end := luaSubRange(int(i), int(j), len(s))
+	var values []luaValue
+	for k := start; k < end; k++ {
+		values = append(values, float64(s[k]))
+	}
+	return values
+}
+
+// luaStringChar string.char(...)
+func luaStringChar(it *luaInterp, args []luaValue) []luaValue {
+	b := make([]byte, len(args))
+	for i := range args {
+		c := checkNumber(it, args, i+1, "char")
+		if c < 0 || c > 255 {
+			argError(it, i+1, "char", "invalid value")
+		}
+		b[i] = byte(c)
+	}
+	return []luaValue{string(b)}
+}
+
+// luaStringRep string.rep(s, n)
+func luaStringRep(it *luaInterp, args []luaValue) []luaValue {
+	s := checkString(it, args, 1, "rep")
+	n := int(checkNumber(it, args, 2, "rep"))
+	if n <= 0 {
+		return []luaValue{""}
+	}
+	if len(s)*n > 512*1024*1024 {
+		it.raise("resulting string too large")
+	}
+	return []luaValue{strings.Repeat(s, n)}
+}
+
+// luaStringReverse string.reverse(s)
+func luaStringReverse(it *luaInterp, args []luaValue) []luaValue {
+	s := []byte(checkString(it, args, 1, "reverse"))
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+	return []luaValue{string(s)}
+}
+
+// luaStringSub string.sub(s, i [, j])
+func luaStringSub(it *luaInterp, args []luaValue) []luaValue {
+	s := checkString(it, args, 1, "sub")
+	i := int(checkNumber(it, args, 2, "sub"))
+	j := int(optNumber(it, args, 3, "sub", -1))
+	start, end := luaSubRange(i, j, len(s))
+	if start >= end {
+		return []luaValue{""}
+	}
+	return []luaValue{s[start:end]}
+}
+
+// luaSubRange 将 Lua 的闭区间 [i, j]（可为负数）转换为 Go 的半开区间
+func luaSubRange(i, j, length int) (int, int) {
+	if i < 0 {
+		i += length + 1
+	}
+	if j < 0 {
+		j += length + 1
+	}
+	if i < 1 {
+		i = 1
+	}
+	if j > length {
+		j = length
+	}
+	if i > j {
+		return 0, 0
+	}
+	return i - 1, j
+}
+
+// luaStringFormat string.format(fmt, ...)，支持 %d %i %u %c %x %X %o %e %E %f %g %G %q %s %%
+func luaStringFormat(it *luaInterp, args []luaValue) []luaValue {
+	format := checkString(it, args, 1, "format")
+	var b strings.Builder
+	n := 1
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c != '%' {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		if i >= len(format) {
+			it.raise("invalid option '%%' to 'format'")
+		}
+		if format[i] == '%' {
+			b.WriteByte('%')
+			continue
+		}
+
+		// 标志、宽度与精度
+		start := i
+		for i < len(format) && strings.IndexByte("-+ #0", format[i]) >= 0 {
+			i++
+		}
+		for i < len(format) && isDigit(format[i]) {
+			i++
+		}
+		if i < len(format) && format[i] == '.' {
+			i++
+			for i < len(format) && isDigit(format[i]) {
+				i++
+			}
+		}
+		if i >= len(format) {
+			it.raise("invalid option '%%%s' to 'format'", format[start:])
+		}
+		spec := "%" + format[start:i]
+		n++
+		switch conv := format[i]; conv {
+		case 'd', 'i':
+			b.WriteString(fmt.Sprintf(spec+"d", int64(checkNumber(it, args, n, "format"))))
+		case 'u':
+			b.WriteString(fmt.Sprintf(spec+"d", uint64(int64(checkNumber(it, args, n, "format")))))
+		case 'c':
+			b.WriteByte(byte(checkNumber(it, args, n, "format")))
+		case 'x', 'X', 'o':
+			b.WriteString(fmt.Sprintf(spec+string(conv), uint64(int64(checkNumber(it, args, n, "format")))))
+		case 'e', 'E', 'f', 'g', 'G':
+			f := checkNumber(it, args, n, "format")
+			switch {
+			case math.IsInf(f, 1):
+				b.WriteString("inf")
+			case math.IsInf(f, -1):
+				b.WriteString("-inf")
+			case math.IsNaN(f):
+				b.WriteString("nan")
+			default:
+				b.WriteString(fmt.Sprintf(spec+string(conv), f))
+			}
+		case 'q':
+			s := checkString(it, args, n, "format")
+			b.WriteString(luaQuote(s))
+		case 's':
+			checkAny(it, args, n, "format")
+			b.WriteString(fmt.Sprintf(spec+"s", luaTostring(args[n-1])))
+		default:
+			it.raise("invalid option '%%%c' to 'format'", conv)
+		}
+	}
+	return []luaValue{b.String()}
+}
+
+// luaQuote 按 %q 格式输出可被 Lua 读回的字符串
+func luaQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\n':
+			b.WriteString("\\\n")
+		case '\r':
+			b.WriteString("\\r")
+		case 0:
+			b.WriteString("\\000")
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// luaTableInsert table.insert(t, [pos,] value)
+func luaTableInsert(it *luaInterp, args []luaValue) []luaValue {
+	t := checkTable(it, args, 1, "insert")
+	n := t.length()
+	switch len(args) {
+	case 2:
+		it.setIndex(t, float64(n+1), args[1])
+	case 3:
+		pos := int(checkNumber(it, args, 2, "insert"))
+		for i := n; i >= pos; i-- {
+			it.setIndex(t, float64(i+1), t.get(float64(i)))
+		}
+		it.setIndex(t, float64(pos), args[2])
+	default:
+		it.raise("wrong number of arguments to 'insert'")
+	}
+	return nil
+}
+
+// luaTableRemove table.remove(t [, pos])
+func luaTableRemove(it *luaInterp, args []luaValue) []luaValue {
+	t := checkTable(it, args, 1, "remove")
+	n := t.length()
+	pos := int(optNumber(it, args, 2, "remove", float64(n)))
+	if n == 0 {
+		return []luaValue{nil}
+	}
+	v := t.get(float64(pos))
+	for i := pos; i < n; i++ {
+		it.setIndex(t, float64(i), t.get(float64(i+1)))
+	}
+	it.setIndex(t, float64(n), nil)
+	return []luaValue{v}
+}
+
+// luaTableConcat table.concat(t [, sep [, i [, j]]])
+func luaTableConcat(it *luaInterp, args []luaValue) []luaValue {
+	t := checkTable(it, args, 1, "concat")
+	sep := ""
+	if arg(args, 2) != nil {
+		sep = checkString(it, args, 2, "concat")
+	}
+	i := int(optNumber(it, args, 3, "concat", 1))
+	j := int(optNumber(it, args, 4, "concat", float64(t.length())))
+	parts := make([]string, 0, j-i+1)
+	for k := i; k <= j; k++ {
+		s, ok := luaToString(t.get(float64(k)))
+		if !ok {
+			it.raise("invalid value (at index %d) in table for 'concat'", k)
+		}
+		parts = append(parts, s)
+	}
+	return []luaValue{strings.Join(parts, sep)}
+}
+
+// luaTableSort table.sort(t [, comp])
+func luaTableSort(it *luaInterp, args []luaValue) []luaValue {
+	t := checkTable(it, args, 1, "sort")
+	comp := arg(args, 2)
+	n := t.length()
+	values := make([]luaValue, n)
+	for i := range values {
+		values[i] = t.get(float64(i + 1))
+	}
+	sort.SliceStable(values, func(i, j int) bool {
+		if comp != nil {
+			results := it.call(comp, []luaValue{values[i], values[j]})
+			return len(results) > 0 && luaTruthy(results[0])
+		}
+		return it.compare("<", values[i], values[j])
+	})
+	for i, v := range values {
+		it.setIndex(t, float64(i+1), v)
+	}
+	return nil
+}
+
+// luaMinMax math.max / math.min
+func luaMinMax(it *luaInterp, args []luaValue, fname string) []luaValue {
+	result := checkNumber(it, args, 1, fname)
+	for i := 2; i <= len(args); i++ {
+		v := checkNumber(it, args, i, fname)
+		if (fname == "max" && v > result) || (fname == "min" && v < result) {
+			result = v
+		}
+	}
+	return []luaValue{result}
+}
+
+// luaJSONEncode cjson.encode(value)：连续整数键 1..n 的表编码为数组，其他表编码为对象
+func luaJSONEncode(it *luaInterp, args []luaValue) []luaValue {
+	checkAny(it, args, 1, "encode")
+	v, err := luaToJSON(args[0], 0)
+	if err != nil {
+		it.raise("Cannot serialise %s", err.Error())
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		it.raise("Cannot serialise: %v", err)
+	}
+	return []luaValue{string(data)}
+}
+
+// luaToJSON 将 Lua 值转换为可 JSON 编码的 Go 值
+func luaToJSON(v luaValue, depth int) (interface{}, error) {
+	if depth > 1000 {
+		return nil, fmt.Errorf("table: excessive nesting")
+	}
+	switch x := v.(type) {
+	case nil:
+		return nil, nil
+	case bool, string:
+		return x, nil
+	case float64:
+		if math.IsInf(x, 0) || math.IsNaN(x) {
+			return nil, fmt.Errorf("number: must not be NaN or Inf")
+		}
+		return json.Number(luaFormatNumber(x)), nil
+	case *luaTable:
+		if len(x.hash) == 0 {
+			if x.length() == 0 {
+				return map[string]interface{}{}, nil
+			}
+			arr := make([]interface{}, x.length())
+			for i := range arr {
+				e, err := luaToJSON(x.arr[i], depth+1)
+				if err != nil {
+					return nil, err
+				}
+				arr[i] = e
+			}
+			return arr, nil
+		}
+		obj := make(map[string]interface{})
+		for k, val, _ := x.next(nil); k != nil; k, val, _ = x.next(k) {
+			key, ok := luaToString(k)
+			if !ok {
+				return nil, fmt.Errorf("table key must be a number or string")
+			}
+			e, err := luaToJSON(val, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = e
+		}
+		return obj, nil
+	default:
+		return nil, fmt.Errorf("%s: type not supported", luaTypeName(v))
+	}
+}
+
+// luaJSONDecode cjson.decode(s)，JSON null 解码为 nil
+func luaJSONDecode(it *luaInterp, args []luaValue) []luaValue {
+	s := checkString(it, args, 1, "decode")
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		it.raise("Expected value but found invalid token")
+	}
+	return []luaValue{jsonToLua(v)}
+}
+
+// jsonToLua 将 JSON 解码结果转换为 Lua 值
+func jsonToLua(v interface{}) luaValue {
+	switch x := v.(type) {
+	case json.Number:
+		f, _ := strconv.ParseFloat(string(x), 64)
+		return f
+	case []interface{}:
+		t := newLuaTable()
+		for i, e := range x {
+			t.set(float64(i+1), jsonToLua(e))
+		}
+		return t
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		t := newLuaTable()
+		for _, k := range keys {
+			t.set(k, jsonToLua(x[k]))
+		}
+		return t
+	default:
+		return x
+	}
+}
@@ -0,0 +1,558 @@
+package redistest
+
+// 本文件移植 Lua 5.1 lstrlib.c 的模式匹配，用于 string.find、match、gmatch 和 gsub
+
+// luaMaxCaptures 最大捕获数量
+const luaMaxCaptures = 32
+
+// capPosition 位置捕获 () 的长度标记
+const capPosition = -2
+
+// capUnfinished 未闭合捕获的长度标记
+const capUnfinished = -1
+
+// luaMatchState 模式匹配状态
+type luaMatchState struct {
+	it       *luaInterp
+	src      string
+	pattern  string
+	level    int
+	captures [luaMaxCaptures]struct {
+		start int
+		len   int
+	}
+}
+
+// classEnd 获取模式中当前字符类之后的位置
+func (ms *luaMatchState) classEnd(p int) int {
+	c := ms.pattern[p]
+	p++
+	if c == '%' {
+		if p >= len(ms.pattern) {
+			ms.it.raise("malformed pattern (ends with '%%')")
+		}
+		return p + 1
+	}
+	if c == '[' {
+		if p < len(ms.pattern) && ms.pattern[p] == '^' {
+			p++
+		}
+		for {
+			// 第一个 ] 视为普通字符
+			if p >= len(ms.pattern) {
+				ms.it.raise("malformed pattern (missing ']')")
+			}
+			c := ms.pattern[p]
+			p++
+			if c == '%' {
+				p++
+			}
+			if p < len(ms.pattern) && ms.pattern[p] == ']' {
+				return p + 1
+			}
+			if p >= len(ms.pattern) {
+				ms.it.raise("malformed pattern (missing ']')")
+			}
+		}
+	}
+	return p
+}
+
+// matchClass 判断字符是否属于 %a、%d 等字符类，大写字母表示取反
+func matchClass(c byte, class byte) bool {
+	var res bool
+	switch class | 0x20 {
+	case 'a':
+		res = isAlpha(c)
+	case 'c':
+		res = c < 32 || c == 127
+	case 'd':
+		res = isDigit(c)
+	case 'l':
+		res = c >= 'a' && c <= 'z'
+	case 'p':
+		res = isPunct(c)
+	case 's':
+		res = c == ' ' || (c >= '\t' && c <= '\r')
+	case 'u':
+		res = c >= 'A' && c <= 'Z'
+	case 'w':
+		res = isAlpha(c) || isDigit(c)
+	case 'x':
+		res = isHexDigit(c)
+	case 'z':
+		res = c == 0
+	default:
+		return class == c
+	}
+	if class >= 'A' && class <= 'Z' {
+		return !res
+	}
+	return res
+}
+
+// isAlpha 判断是否为字母
+func isAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isPunct 判断是否为标点符号
+func isPunct(c byte) bool {
+	return c > 32 && c < 127 && !isAlpha(c) && !isDigit(c)
+}
+
+// matchBracketClass 判断字符是否属于 [...] 字符集，p 指向 [，ec 指向 ]
+func (ms *luaMatchState) matchBracketClass(c byte, p, ec int) bool {
+	negate := false
+	p++
+	if ms.pattern[p] == '^' {
+		negate = true
+		p++
+	}
+	for ; p < ec; p++ {
+		switch {
+		case ms.pattern[p] == '%':
+			p++
+			if matchClass(c, ms.pattern[p]) {
+				return !negate
+			}
+		case p+2 < ec && ms.pattern[p+1] == '-':
+			if ms.pattern[p] <= c && c <= ms.pattern[p+2] {
+				return !negate
+			}
+			p += 2
+		case ms.pattern[p] == c:
+			return !negate
+		}
+	}
+	return negate
+}
+
+// singleMatch 判断 s 位置的字符是否匹配 [p, ep) 表示的单个字符类
+func (ms *luaMatchState) singleMatch(s, p, ep int) bool {
+	if s >= len(ms.src) {
+		return false
+	}
+	c := ms.src[s]
+	switch ms.pattern[p] {
+	case '.':
+		return true
+	case '%':
+		return matchClass(c, ms.pattern[p+1])
+	case '[':
+		return ms.matchBracketClass(c, p, ep-1)
+	default:
+		return ms.pattern[p] == c
+	}
+}
+
+// match 从 s 开始匹配模式 p，返回匹配结束位置，不匹配时返回-1
+func (ms *luaMatchState) match(s, p int) int {
+	for {
+		if p >= len(ms.pattern) {
+			return s
+		}
+		switch ms.pattern[p] {
+		case '(':
+			if p+1 < len(ms.pattern) && ms.pattern[p+1] == ')' {
+				return ms.startCapture(s, p+2, capPosition)
+			}
+			return ms.startCapture(s, p+1, capUnfinished)
+		case ')':
+			return ms.endCapture(s, p+1)
+		case '$':
+			if p+1 == len(ms.pattern) {
+				if s == len(ms.src) {
+					return s
+				}
+				return -1
+			}
+		case '%':
+			if p+1 < len(ms.pattern) {
+				switch next := ms.pattern[p+1]; {
+				case next == 'b':
+					s = ms.matchBalance(s, p+2)
+					if s == -1 {
+						return -1
+					}
+					p += 4
+					continue
+				case next == 'f':
+					p += 2
+					if p >= len(ms.pattern) || ms.pattern[p] != '[' {
+						ms.it.raise("missing '[' after '%%f' in pattern")
+					}
+					ep := ms.classEnd(p)
+					var prev, cur byte
+					if s > 0 {
+						prev = ms.src[s-1]
+					}
+					if s < len(ms.src) {
+						cur = ms.src[s]
+					}
+					if ms.matchBracketClass(prev, p, ep-1) || !ms.matchBracketClass(cur, p, ep-1) {
+						return -1
+					}
+					p = ep
+					continue
+				case isDigit(next):
+					s = ms.matchCapture(s, next)
+					if s == -1 {
+						return -1
+					}
+					p += 2
+					continue
+				}
+			}
+		}
+
+		ep := ms.classEnd(p)
+		matched := ms.singleMatch(s, p, ep)
+		if ep < len(ms.pattern) {
+			switch ms.pattern[ep] {
+			case '?':
+				if matched {
+					if res := ms.match(s+1, ep+1); res != -1 {
+						return res
+					}
+				}
+				p = ep + 1
+				continue
+			case '*':
+				return ms.maxExpand(s, p, ep)
+			case '+':
+				if !matched {
+					return -1
+				}
+				return ms.maxExpand(s+1, p, ep)
+			case '-':
+				return ms.minExpand(s, p, ep)
+			}
+		}
+		if !matched {
+			return -1
+		}
+		s++
+		p = ep
+	}
+}
+
+// maxExpand 贪婪匹配 * 和 +
+func (ms *luaMatchState) maxExpand(s, p, ep int) int {
+	i := 0
+	for ms.singleMatch(s+i, p, ep) {
+		i++
+	}
+	for ; i >= 0; i-- {
+		if res := ms.match(s+i, ep+1); res != -1 {
+			return res
+		}
+	}
+	return -1
+}
+
+// minExpand 非贪婪匹配 -
+func (ms *luaMatchState) minExpand(s, p, ep int) int {
+	for {
+		if res := ms.match(s, ep+1); res != -1 {
+			return res
+		}
+		if !ms.singleMatch(s, p, ep) {
+			return -1
+		}
+		s++
+	}
+}
+
+// startCapture 开始捕获
+func (ms *luaMatchState) startCapture(s, p, what int) int {
+	if ms.level >= luaMaxCaptures {
+		ms.it.raise("too many captures")
+	}
+	ms.captures[ms.level].start = s
+	ms.captures[ms.level].len = what
+	ms.level++
+	res := ms.match(s, p)
+	if res == -1 {
+		ms.level--
+	}
+	return res
+}
+
+// endCapture 结束最近一个未闭合的捕获
+func (ms *luaMatchState) endCapture(s, p int) int {
+	l := -1
+	for i := ms.level - 1; i >= 0; i-- {
+		if ms.captures[i].len == capUnfinished {
+			l = i
+			break
+		}
+	}
+	if l < 0 {
+		ms.it.raise("invalid pattern capture")
+	}
+	ms.captures[l].len = s - ms.captures[l].start
+	res := ms.match(s, p)
+	if res == -1 {
+		ms.captures[l].len = capUnfinished
+	}
+	return res
+}
+
+// matchBalance 匹配 %bxy
+func (ms *luaMatchState) matchBalance(s, p int) int {
+	if p+1 >= len(ms.pattern) {
+		ms.it.raise("missing arguments to '%%b'")
+	}
+	if s >= len(ms.src) || ms.src[s] != ms.pattern[p] {
+		return -1
+	}
+	open, close := ms.pattern[p], ms.pattern[p+1]
+	depth := 1
+	for i := s + 1; i < len(ms.src); i++ {
+		switch ms.src[i] {
+		case close:
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		case open:
+			depth++
+		}
+	}
+	return -1
+}
+
+// matchCapture 匹配反向引用 %1-%9
+func (ms *luaMatchState) matchCapture(s int, c byte) int {
+	l := int(c - '1')
+	if l < 0 || l >= ms.level || ms.captures[l].len == capUnfinished {
+		ms.it.raise("invalid capture index")
+	}
+	capture := ms.src[ms.captures[l].start : ms.captures[l].start+ms.captures[l].len]
+	if len(ms.src)-s >= len(capture) && ms.src[s:s+len(capture)] == capture {
+		return s + len(capture)
+	}
+	return -1
+}
+
+// capture 获取第i个捕获的值，没有捕获时返回整个匹配
+func (ms *luaMatchState) capture(i, s, e int) luaValue {
+	if i >= ms.level {
+		if i == 0 {
+			return ms.src[s:e]
+		}
+		ms.it.raise("invalid capture index")
+	}
+	c := ms.captures[i]
+	if c.len == capUnfinished {
+		ms.it.raise("unfinished capture")
+	}
+	if c.len == capPosition {
+		return float64(c.start + 1)
+	}
+	return ms.src[c.start : c.start+c.len]
+}
+
+// capturesFor 获取所有捕获，wholeIfNone 为true时没有捕获则返回整个匹配
+func (ms *luaMatchState) capturesFor(s, e int, wholeIfNone bool) []luaValue {
+	n := ms.level
+	if n == 0 && wholeIfNone {
+		n = 1
+	}
+	values := make([]luaValue, n)
+	for i := range values {
+		values[i] = ms.capture(i, s, e)
+	}
+	return values
+}
+
+// luaFind 实现 string.find 和 string.match
+func luaFind(it *luaInterp, args []luaValue, find bool) []luaValue {
+	s := checkString(it, args, 1, "find")
+	pattern := checkString(it, args, 2, "find")
+	init := luaStartIndex(optNumber(it, args, 3, "find", 1), len(s))
+	if init > len(s) {
+		init = len(s)
+	}
+
+	plain := len(args) > 3 && luaTruthy(args[3])
+	if find && (plain || !hasSpecials(pattern)) {
+		if i := indexFrom(s, pattern, init); i >= 0 {
+			return []luaValue{float64(i + 1), float64(i + len(pattern))}
+		}
+		return []luaValue{nil}
+	}
+
+	ms := &luaMatchState{it: it, src: s, pattern: pattern}
+	anchor := len(pattern) > 0 && pattern[0] == '^'
+	p := 0
+	if anchor {
+		p = 1
+	}
+	for start := init; start <= len(s); start++ {
+		ms.level = 0
+		if e := ms.match(start, p); e != -1 {
+			if find {
+				return append([]luaValue{float64(start + 1), float64(e)}, ms.capturesFor(start, e, false)...)
+			}
+			return ms.capturesFor(start, e, true)
+		}
+		if anchor {
+			break
+		}
+	}
+	return []luaValue{nil}
+}
+
+// luaStartIndex 将 Lua 的起始位置（可为负数）转换为从0开始的下标
+func luaStartIndex(pos float64, length int) int {
+	i := int(pos)
+	if i < 0 {
+		i += length + 1
+	}
+	if i < 1 {
+		i = 1
+	}
+	return i - 1
+}
+
+// hasSpecials 判断模式是否包含特殊字符
+func hasSpecials(pattern string) bool {
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '^', '$', '*', '+', '?', '.', '(', ')', '[', ']', '%', '-':
+			return true
+		}
+	}
+	return false
+}
+
+// indexFrom 从指定位置查找子串
+func indexFrom(s, sub string, from int) int {
+	for i := from; i+len(sub) <= len(s); i++ {
+		if s[i:i+len(sub)] == sub {
+			return i
+		}
+	}
+	return -1
+}
+
+// luaGmatch 实现 string.gmatch
+func luaGmatch(it *luaInterp, args []luaValue) []luaValue {
+	s := checkString(it, args, 1, "gmatch")
+	pattern := checkString(it, args, 2, "gmatch")
+	pos := 0
+	iter := &luaGoFunction{name: "gmatch_iterator", fn: func(it *luaInterp, _ []luaValue) []luaValue {
+		ms := &luaMatchState{it: it, src: s, pattern: pattern}
+		for ; pos <= len(s); pos++ {
+			ms.level = 0
+			if e := ms.match(pos, 0); e != -1 {
+				start := pos
+				pos = e
+				if e == start {
+					pos++
+				}
+				return ms.capturesFor(start, e, true)
+			}
+		}
+		return []luaValue{nil}
+	}}
+	return []luaValue{iter}
+}
+
+// luaGsub 实现 string.gsub，替换值可以是字符串（支持 %0-%9）、表或函数
+func luaGsub(it *luaInterp, args []luaValue) []luaValue {
+	s := checkString(it, args, 1, "gsub")
+	pattern := checkString(it, args, 2, "gsub")
+	var repl luaValue
+	if len(args) > 2 {
+		repl = args[2]
+	}
+	switch repl.(type) {
+	case float64, string, *luaTable, *luaClosure, *luaGoFunction:
+	default:
+		it.raise("bad argument #3 to 'gsub' (string/function/table expected)")
+	}
+	maxN := -1
+	if len(args) > 3 && args[3] != nil {
+		maxN = int(checkNumber(it, args, 4, "gsub"))
+	}
+
+	anchor := len(pattern) > 0 && pattern[0] == '^'
+	p := 0
+	if anchor {
+		p = 1
+	}
+	ms := &luaMatchState{it: it, src: s, pattern: pattern}
+	var b []byte
+	pos, n := 0, 0
+	for maxN < 0 || n < maxN {
+		ms.level = 0
+		e := ms.match(pos, p)
+		if e != -1 {
+			n++
+			b = append(b, ms.replacement(repl, pos, e)...)
+		}
+		switch {
+		case e != -1 && e > pos:
+			pos = e
+		case pos < len(s):
+			b = append(b, s[pos])
+			pos++
+		default:
+			pos = len(s) + 1
+		}
+		if pos > len(s) || anchor {
+			break
+		}
+	}
+	if pos <= len(s) {
+		b = append(b, s[pos:]...)
+	}
+	return []luaValue{string(b), float64(n)}
+}
+
+// replacement 计算 gsub 的替换内容
+func (ms *luaMatchState) replacement(repl luaValue, s, e int) string {
+	whole := ms.src[s:e]
+	var value luaValue
+	switch r := repl.(type) {
+	case string, float64:
+		str, _ := luaToString(r)
+		var b []byte
+		for i := 0; i < len(str); i++ {
+			if str[i] != '%' || i+1 >= len(str) {
+				b = append(b, str[i])
+				continue
+			}
+			i++
+			switch c := str[i]; {
+			case c == '0':
+				b = append(b, whole...)
+			case isDigit(c):
+				v, _ := luaToString(ms.capture(int(c-'1'), s, e))
+				b = append(b, v...)
+			default:
+				b = append(b, c)
+			}
+		}
+		return string(b)
+	case *luaTable:
+		value = r.get(ms.capture(0, s, e))
+	default:
+		results := ms.it.call(r, ms.capturesFor(s, e, true))
+		if len(results) > 0 {
+			value = results[0]
+		}
+	}
+
+	if !luaTruthy(value) {
+		return whole
+	}
+	str, ok := luaToString(value)
+	if !ok {
+		ms.it.raise("invalid replacement value (a %s)", luaTypeName(value))
+	}
+	return str
+}
@@ -0,0 +1,57 @@
+// Package redistest 提供用于单元测试的内存 Redis 服务器
+//
+// Server 在本地随机端口提供 RESP 协议服务，数据保存在内存中，无需安装 Redis。
+// 通过 NewClient 可直接获得连接到该服务器的 *redis.Client，
+// 其 Generic、String、List、Set、Hash、ZSet、Token 接口均可在测试中使用：
+//
+//	func TestXxx(t *testing.T) {
+//		client, server := redistest.NewClient(t)
+//		_ = client.SetEX(ctx, "key", "value", time.Minute)
+//		server.Clock().Advance(time.Minute) // 键过期
+//	}
+package redistest
+
+import (
+	"testing"
+
+	"github.com/nilchaosky/go-nexus/redis"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+var (
+	_ redis.Generic = (*redis.Client)(nil)
+	_ redis.String  = (*redis.Client)(nil)
+	_ redis.List    = (*redis.Client)(nil)
+	_ redis.Set     = (*redis.Client)(nil)
+	_ redis.Hash    = (*redis.Client)(nil)
+	_ redis.ZSet    = (*redis.Client)(nil)
+	_ redis.Token   = (*redis.Client)(nil)
+)
+
+// Run 启动测试服务器，测试结束时自动关闭
+func Run(tb testing.TB) *Server {
+	tb.Helper()
+
+	s, err := NewServer()
+	if err != nil {
+		tb.Fatalf("启动测试Redis服务器失败: %v", err)
+	}
+	tb.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+// NewClient 启动测试服务器并返回连接到该服务器的客户端，测试结束时自动关闭
+func NewClient(tb testing.TB, opts ...redis.ClientOption) (*redis.Client, *Server) {
+	tb.Helper()
+
+	s := Run(tb)
+	rdb := goredis.NewClient(&goredis.Options{
+		Addr: s.Addr(),
+		// 测试服务器只支持 RESP2，且不处理 CLIENT SETINFO
+		Protocol:        2,
+		DisableIdentity: true,
+	})
+	client := redis.NewClient(rdb, opts...)
+	tb.Cleanup(func() { _ = client.Close() })
+	return client, s
+}
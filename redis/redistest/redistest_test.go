@@ -0,0 +1,480 @@
+package redistest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nilchaosky/go-nexus/redis"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// TestClockExpire 测试时钟驱动的键过期
+func TestClockExpire(t *testing.T) {
+	ctx := context.Background()
+	client, server := NewClient(t)
+
+	if err := client.SetEX(ctx, "session", "v", 10*time.Second); err != nil {
+		t.Fatalf("SetEX失败: %v", err)
+	}
+	ttl, err := client.TTL(ctx, "session")
+	if err != nil || ttl != 10*time.Second {
+		t.Fatalf("期望TTL为10s，实际: %v, %v", ttl, err)
+	}
+
+	// 时钟不前进时键不会过期
+	time.Sleep(20 * time.Millisecond)
+	if _, err := client.Get(ctx, "session"); err != nil {
+		t.Fatalf("期望键仍存在，实际: %v", err)
+	}
+
+	server.Clock().Advance(10 * time.Second)
+	if _, err := client.Get(ctx, "session"); !redis.IsNotFound(err) {
+		t.Fatalf("期望键已过期，实际: %v", err)
+	}
+	if n, _ := client.Exists(ctx, "session"); n != 0 {
+		t.Errorf("期望键不存在，实际数量: %d", n)
+	}
+}
+
+// TestBlockingPop 测试阻塞弹出在其他连接写入后返回
+func TestBlockingPop(t *testing.T) {
+	ctx := context.Background()
+	client, _ := NewClient(t)
+
+	result := make(chan []string, 1)
+	go func() {
+		values, err := client.BLPop(ctx, 5*time.Second, "jobs")
+		if err != nil {
+			t.Errorf("BLPop失败: %v", err)
+		}
+		result <- values
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if _, err := client.RPush(ctx, "jobs", "a"); err != nil {
+		t.Fatalf("RPush失败: %v", err)
+	}
+
+	select {
+	case values := <-result:
+		if len(values) != 2 || values[0] != "jobs" || values[1] != "a" {
+			t.Errorf("期望 [jobs a]，实际: %v", values)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("阻塞弹出未被唤醒")
+	}
+
+	// 超时后返回 ErrNotFound
+	start := time.Now()
+	if _, err := client.BRPop(ctx, 100*time.Millisecond, "jobs"); !redis.IsNotFound(err) {
+		t.Errorf("期望超时返回ErrNotFound，实际: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("阻塞弹出过早返回: %v", elapsed)
+	}
+}
+
+// TestWrongType 测试类型不匹配返回 WRONGTYPE 错误
+func TestWrongType(t *testing.T) {
+	ctx := context.Background()
+	client, _ := NewClient(t)
+
+	if _, err := client.LPush(ctx, "list", "a"); err != nil {
+		t.Fatalf("LPush失败: %v", err)
+	}
+	_, err := client.Get(ctx, "list")
+	if err == nil || !strings.HasPrefix(err.Error(), "WRONGTYPE") {
+		t.Errorf("期望WRONGTYPE错误，实际: %v", err)
+	}
+}
+
+// TestWatchConflict 测试 WATCH 的键被修改时 EXEC 失败
+func TestWatchConflict(t *testing.T) {
+	ctx := context.Background()
+	client, _ := NewClient(t)
+	rdb := client.GetRawClient()
+
+	err := rdb.Watch(ctx, func(tx *goredis.Tx) error {
+		// 其他连接修改被监视的键
+		if err := rdb.Set(ctx, "counter", "1", 0).Err(); err != nil {
+			return err
+		}
+		_, err := tx.TxPipelined(ctx, func(p goredis.Pipeliner) error {
+			p.Incr(ctx, "counter")
+			return nil
+		})
+		return err
+	}, "counter")
+	if err != goredis.TxFailedErr {
+		t.Fatalf("期望TxFailedErr，实际: %v", err)
+	}
+
+	value, _ := client.Get(ctx, "counter")
+	if value != "1" {
+		t.Errorf("期望事务未执行，实际值: %s", value)
+	}
+}
+
+// TestScan 测试 SCAN 按游标遍历所有键
+func TestScan(t *testing.T) {
+	ctx := context.Background()
+	client, _ := NewClient(t)
+
+	for i := 0; i < 25; i++ {
+		if err := client.Set(ctx, "user:"+string(rune('a'+i)), i); err != nil {
+			t.Fatalf("Set失败: %v", err)
+		}
+	}
+	_ = client.Set(ctx, "other", 1)
+
+	var keys []string
+	var cursor uint64
+	for {
+		page, next, err := client.Scan(ctx, cursor, "user:*", 10)
+		if err != nil {
+			t.Fatalf("Scan失败: %v", err)
+		}
+		keys = append(keys, page...)
+		if cursor = next; cursor == 0 {
+			break
+		}
+	}
+	if len(keys) != 25 {
+		t.Errorf("期望25个键，实际: %d", len(keys))
+	}
+}
+
+// TestGlobMatch 测试键名模式匹配
+func TestGlobMatch(t *testing.T) {
+	cases := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"*", "anything", true},
+		{"user:*", "user:1", true},
+		{"user:*", "order:1", false},
+		{"h?llo", "hello", true},
+		{"h[ae]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-c]llo", "hbllo", true},
+		{`user\*`, "user*", true},
+		{`user\*`, "user1", false},
+	}
+	for _, tc := range cases {
+		if got := globMatch(tc.pattern, tc.s); got != tc.want {
+			t.Errorf("globMatch(%q, %q) = %v，期望 %v", tc.pattern, tc.s, got, tc.want)
+		}
+	}
+}
+
+// TestPubSub 测试频道订阅与模式订阅的消息推送
+func TestPubSub(t *testing.T) {
+	ctx := context.Background()
+	client, _ := NewClient(t)
+	rdb := client.GetRawClient()
+
+	pubsub := rdb.Subscribe(ctx, "news")
+	defer pubsub.Close()
+	if _, err := pubsub.Receive(ctx); err != nil {
+		t.Fatalf("订阅失败: %v", err)
+	}
+	if err := pubsub.PSubscribe(ctx, "news.*"); err != nil {
+		t.Fatalf("模式订阅失败: %v", err)
+	}
+	if err := pubsub.Ping(ctx); err != nil {
+		t.Fatalf("订阅状态下PING失败: %v", err)
+	}
+
+	// 等待模式订阅确认与PONG
+	for i := 0; i < 2; i++ {
+		if _, err := pubsub.ReceiveTimeout(ctx, time.Second); err != nil {
+			t.Fatalf("接收订阅确认失败: %v", err)
+		}
+	}
+
+	if n, err := rdb.Publish(ctx, "news", "a").Result(); err != nil || n != 1 {
+		t.Fatalf("期望1个订阅者，实际: %d, %v", n, err)
+	}
+	if n, _ := rdb.Publish(ctx, "news.sport", "b").Result(); n != 1 {
+		t.Errorf("期望模式订阅收到消息，实际: %d", n)
+	}
+
+	msg, err := pubsub.ReceiveMessage(ctx)
+	if err != nil || msg.Channel != "news" || msg.Payload != "a" {
+		t.Fatalf("频道消息不符: %+v, %v", msg, err)
+	}
+	msg, err = pubsub.ReceiveMessage(ctx)
+	if err != nil || msg.Pattern != "news.*" || msg.Channel != "news.sport" || msg.Payload != "b" {
+		t.Fatalf("模式消息不符: %+v, %v", msg, err)
+	}
+
+	// 关闭订阅后不再计入订阅者
+	_ = pubsub.Close()
+	time.Sleep(20 * time.Millisecond)
+	if n, _ := rdb.Publish(ctx, "news", "c").Result(); n != 0 {
+		t.Errorf("期望没有订阅者，实际: %d", n)
+	}
+}
+
+// TestStreamGroup 测试流消费组的读取、待处理列表、空闲认领与确认
+func TestStreamGroup(t *testing.T) {
+	ctx := context.Background()
+	client, server := NewClient(t)
+	rdb := client.GetRawClient()
+
+	if err := rdb.XGroupCreateMkStream(ctx, "events", "g", "0").Err(); err != nil {
+		t.Fatalf("创建消费组失败: %v", err)
+	}
+	if err := rdb.XGroupCreate(ctx, "events", "g", "0").Err(); err == nil || !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		t.Errorf("期望BUSYGROUP错误，实际: %v", err)
+	}
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		id, err := rdb.XAdd(ctx, &goredis.XAddArgs{Stream: "events", Values: []string{"n", string(rune('a' + i))}}).Result()
+		if err != nil {
+			t.Fatalf("XAdd失败: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	if ids[0] >= ids[1] && len(ids[0]) == len(ids[1]) {
+		t.Errorf("期望消息ID递增，实际: %v", ids)
+	}
+
+	streams, err := rdb.XReadGroup(ctx, &goredis.XReadGroupArgs{Group: "g", Consumer: "c1", Streams: []string{"events", ">"}, Count: 2}).Result()
+	if err != nil || len(streams) != 1 || len(streams[0].Messages) != 2 || streams[0].Messages[0].Values["n"] != "a" {
+		t.Fatalf("读取新消息不符: %+v, %v", streams, err)
+	}
+
+	// 空闲时间由时钟驱动
+	server.Clock().Advance(time.Minute)
+	pending, err := rdb.XPendingExt(ctx, &goredis.XPendingExtArgs{Stream: "events", Group: "g", Start: "-", End: "+", Count: 10}).Result()
+	if err != nil || len(pending) != 2 || pending[0].Consumer != "c1" || pending[0].Idle != time.Minute || pending[0].RetryCount != 1 {
+		t.Fatalf("待处理列表不符: %+v, %v", pending, err)
+	}
+
+	claimed, next, err := rdb.XAutoClaim(ctx, &goredis.XAutoClaimArgs{Stream: "events", Group: "g", Consumer: "c2", MinIdle: 30 * time.Second, Start: "0-0", Count: 10}).Result()
+	if err != nil || len(claimed) != 2 || next != "0-0" {
+		t.Fatalf("认领结果不符: %+v, %s, %v", claimed, next, err)
+	}
+	pending, _ = rdb.XPendingExt(ctx, &goredis.XPendingExtArgs{Stream: "events", Group: "g", Start: ids[0], End: ids[0], Count: 1, Consumer: "c2"}).Result()
+	if len(pending) != 1 || pending[0].RetryCount != 2 || pending[0].Idle != 0 {
+		t.Errorf("期望认领后投递次数为2且空闲时间清零，实际: %+v", pending)
+	}
+
+	// 刚认领的消息未达到空闲时间，不会被再次认领
+	claimed, _, _ = rdb.XAutoClaim(ctx, &goredis.XAutoClaimArgs{Stream: "events", Group: "g", Consumer: "c1", MinIdle: 30 * time.Second, Start: "0-0"}).Result()
+	if len(claimed) != 0 {
+		t.Errorf("期望没有可认领的消息，实际: %+v", claimed)
+	}
+
+	if n, err := rdb.XAck(ctx, "events", "g", ids[0], ids[1], ids[2]).Result(); err != nil || n != 2 {
+		t.Errorf("期望确认2条消息，实际: %d, %v", n, err)
+	}
+	summary, err := rdb.XPending(ctx, "events", "g").Result()
+	if err != nil || summary.Count != 0 {
+		t.Errorf("期望待处理列表为空，实际: %+v, %v", summary, err)
+	}
+
+	if _, err := rdb.XReadGroup(ctx, &goredis.XReadGroupArgs{Group: "missing", Consumer: "c1", Streams: []string{"events", ">"}}).Result(); err == nil || !strings.HasPrefix(err.Error(), "NOGROUP") {
+		t.Errorf("期望NOGROUP错误，实际: %v", err)
+	}
+}
+
+// TestStreamBlockingRead 测试阻塞读取在其他连接写入后返回
+func TestStreamBlockingRead(t *testing.T) {
+	ctx := context.Background()
+	client, _ := NewClient(t)
+	rdb := client.GetRawClient()
+
+	if err := rdb.XGroupCreateMkStream(ctx, "events", "g", "$").Err(); err != nil {
+		t.Fatalf("创建消费组失败: %v", err)
+	}
+
+	result := make(chan []goredis.XStream, 1)
+	go func() {
+		streams, err := rdb.XReadGroup(ctx, &goredis.XReadGroupArgs{Group: "g", Consumer: "c", Streams: []string{"events", ">"}, Block: 5 * time.Second}).Result()
+		if err != nil {
+			t.Errorf("XReadGroup失败: %v", err)
+		}
+		result <- streams
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if err := rdb.XAdd(ctx, &goredis.XAddArgs{Stream: "events", Values: []string{"n", "a"}}).Err(); err != nil {
+		t.Fatalf("XAdd失败: %v", err)
+	}
+
+	select {
+	case streams := <-result:
+		if len(streams) != 1 || len(streams[0].Messages) != 1 || streams[0].Messages[0].Values["n"] != "a" {
+			t.Errorf("读取结果不符: %+v", streams)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("阻塞读取未被唤醒")
+	}
+
+	// 超时后返回 redis.Nil
+	_, err := rdb.XReadGroup(ctx, &goredis.XReadGroupArgs{Group: "g", Consumer: "c", Streams: []string{"events", ">"}, Block: 100 * time.Millisecond}).Result()
+	if err != goredis.Nil {
+		t.Errorf("期望超时返回redis.Nil，实际: %v", err)
+	}
+}
+
+// TestStreamTrim 测试写入时按长度裁剪与范围查询
+func TestStreamTrim(t *testing.T) {
+	ctx := context.Background()
+	client, _ := NewClient(t)
+	rdb := client.GetRawClient()
+
+	for i := 1; i <= 5; i++ {
+		id := "1-" + string(rune('0'+i))
+		if err := rdb.XAdd(ctx, &goredis.XAddArgs{Stream: "s", ID: id, MaxLen: 3, Values: []string{"i", id}}).Err(); err != nil {
+			t.Fatalf("XAdd失败: %v", err)
+		}
+	}
+	if err := rdb.XAdd(ctx, &goredis.XAddArgs{Stream: "s", ID: "1-1", Values: []string{"i", "x"}}).Err(); err == nil {
+		t.Error("期望ID小于最后一条消息时报错")
+	}
+
+	msgs, err := rdb.XRange(ctx, "s", "-", "+").Result()
+	if err != nil || len(msgs) != 3 || msgs[0].ID != "1-3" {
+		t.Fatalf("期望保留最新3条消息，实际: %+v, %v", msgs, err)
+	}
+	msgs, _ = rdb.XRevRangeN(ctx, "s", "(1-5", "-", 1).Result()
+	if len(msgs) != 1 || msgs[0].ID != "1-4" {
+		t.Errorf("期望开区间倒序返回1-4，实际: %+v", msgs)
+	}
+	if typ, _ := rdb.Type(ctx, "s").Result(); typ != "stream" {
+		t.Errorf("期望类型为stream，实际: %s", typ)
+	}
+}
+
+// TestEval 测试脚本执行、redis.call 的回复转换和脚本缓存
+func TestEval(t *testing.T) {
+	ctx := context.Background()
+	client, _ := NewClient(t)
+	rdb := client.GetRawClient()
+
+	script := goredis.NewScript(`
+local n = redis.call('INCRBY', KEYS[1], ARGV[1])
+redis.call('SET', KEYS[2], string.format('%d-%s', n, ARGV[2]))
+local list = {}
+for i = 1, 3 do
+	table.insert(list, tostring(i * n))
+end
+return {n, redis.call('GET', KEYS[2]), redis.call('GET', 'missing'), list, 2.9}
+`)
+	result, err := script.Run(ctx, rdb, []string{"counter", "label"}, 5, "x").Slice()
+	if err != nil {
+		t.Fatalf("脚本执行失败: %v", err)
+	}
+	if len(result) != 5 || result[0] != int64(5) || result[1] != "5-x" || result[2] != nil || result[4] != int64(2) {
+		t.Fatalf("脚本返回值不符: %#v", result)
+	}
+	if list, ok := result[3].([]interface{}); !ok || len(list) != 3 || list[2] != "15" {
+		t.Errorf("嵌套数组不符: %#v", result[3])
+	}
+
+	// 首次使用 EVALSHA 时脚本已由 EVAL 缓存
+	exists, err := script.Exists(ctx, rdb).Result()
+	if err != nil || len(exists) != 1 || !exists[0] {
+		t.Errorf("期望脚本已缓存，实际: %v, %v", exists, err)
+	}
+	if err := rdb.EvalSha(ctx, "0000000000000000000000000000000000000000", nil).Err(); err == nil || !strings.HasPrefix(err.Error(), "NOSCRIPT") {
+		t.Errorf("期望NOSCRIPT错误，实际: %v", err)
+	}
+	if err := rdb.ScriptFlush(ctx).Err(); err != nil {
+		t.Fatalf("ScriptFlush失败: %v", err)
+	}
+	if result, err := script.Run(ctx, rdb, []string{"counter", "label"}, 1, "y").Slice(); err != nil || result[0] != int64(6) {
+		t.Errorf("清空缓存后期望回退到EVAL，实际: %v, %v", result, err)
+	}
+}
+
+// TestEvalErrors 测试脚本错误：编译错误、redis.call 错误、pcall 和 error_reply
+func TestEvalErrors(t *testing.T) {
+	ctx := context.Background()
+	client, _ := NewClient(t)
+	rdb := client.GetRawClient()
+
+	if err := rdb.RPush(ctx, "list", "a").Err(); err != nil {
+		t.Fatalf("RPush失败: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		script string
+		prefix string
+	}{
+		{"编译错误", "return (", "ERR Error compiling script"},
+		{"命令错误", "return redis.call('GET', KEYS[1])", "WRONGTYPE"},
+		{"未知命令", "return redis.call('NOPE')", "ERR Unknown Redis command"},
+		{"全局变量", "x = 1", "ERR user_script:1: Attempt to modify a readonly table"},
+		{"error", "error('boom')", "ERR user_script:1: boom"},
+		{"error_reply", "return redis.error_reply('MY failure')", "MY failure"},
+	}
+	for _, tt := range tests {
+		err := rdb.Eval(ctx, tt.script, []string{"list"}).Err()
+		if err == nil || !strings.HasPrefix(err.Error(), tt.prefix) {
+			t.Errorf("%s: 期望错误前缀 %q，实际: %v", tt.name, tt.prefix, err)
+		}
+	}
+
+	// pcall 捕获错误后脚本继续执行
+	result, err := rdb.Eval(ctx, `
+local reply = redis.pcall('GET', KEYS[1])
+local ok, e = pcall(function() return redis.call('GET', KEYS[1]) end)
+return {reply.err:match('^(%u+)'), tostring(ok), e.err:sub(1, 9)}
+`, []string{"list"}).StringSlice()
+	if err != nil {
+		t.Fatalf("脚本执行失败: %v", err)
+	}
+	if strings.Join(result, ",") != "WRONGTYPE,false,WRONGTYPE" {
+		t.Errorf("pcall结果不符: %v", result)
+	}
+
+	if err := rdb.Eval(ctx, "return 1", []string{"a", "b"}, 1).Err(); err != nil {
+		t.Errorf("期望执行成功，实际: %v", err)
+	}
+	if err := rdb.Do(ctx, "EVAL", "return 1", 3, "a").Err(); err == nil || !strings.Contains(err.Error(), "greater than") {
+		t.Errorf("期望键数量错误，实际: %v", err)
+	}
+}
+
+// TestLuaLibrary 测试脚本中常用的标准库函数
+func TestLuaLibrary(t *testing.T) {
+	ctx := context.Background()
+	client, _ := NewClient(t)
+	rdb := client.GetRawClient()
+
+	tests := []struct {
+		script string
+		want   string
+	}{
+		{"return tostring(tonumber('10') + 0.5)", "10.5"},
+		{"return tostring(tonumber('x'))", "nil"},
+		{"return string.format('%.17g', 0.1)", "0.10000000000000001"},
+		{"return string.format('%5.2f|%-3s|%x', 3.14159, 'a', 255)", " 3.14|a  |ff"},
+		{"return string.match('job:42:retry', '^job:(%d+):(%a+)$')", "42"},
+		{"return (string.gsub('a-b-c', '-', '+'))", "a+b+c"},
+		{"return table.concat({1, 2, 3}, ',')", "1,2,3"},
+		{"local t = {3, 1, 2}; table.sort(t, function(a, b) return a > b end); return table.concat(t)", "321"},
+		{"return tostring(math.max(1, 5, 3) + math.floor(2.7))", "7"},
+		{"local s = 0; for _, v in ipairs({1, 2, 3}) do s = s + v end; return tostring(s)", "6"},
+		{"local t = {}; for k in pairs({a = 1, b = 2}) do t[#t + 1] = k end; table.sort(t); return table.concat(t)", "ab"},
+		{"return cjson.encode({1, 'a', true})", `[1,"a",true]`},
+		{"return cjson.decode('{\"n\":[1,2]}').n[2] .. ''", "2"},
+		{"local s = ''; for w in ('a b c'):gmatch('%a') do s = w .. s end; return s", "cba"},
+		{"return select('#', 1, nil, 3) .. ''", "3"},
+		{"local ok, err = pcall(error, {code = 1}); return tostring(ok) .. err.code", "false1"},
+	}
+	for _, tt := range tests {
+		got, err := rdb.Eval(ctx, tt.script, nil).Text()
+		if err != nil || got != tt.want {
+			t.Errorf("%s: 期望 %q，实际: %q, %v", tt.script, tt.want, got, err)
+		}
+	}
+}
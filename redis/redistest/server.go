@@ -0,0 +1,388 @@
+package redistest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server 基于内存键空间的 RESP 测试服务器
+// 支持字符串、列表、集合、哈希、有序集合、流（含消费组）的常用命令，以及事务（MULTI/EXEC/WATCH）、阻塞弹出、发布订阅和 Lua 脚本（EVAL/EVALSHA/SCRIPT）；
+// 脚本由内置的 Lua 5.1 子集解释器执行（不支持元表、协程和 goto），提供 string、table、math、cjson 库；
+// EVAL_RO/EVALSHA_RO 不检查写命令；流的近似裁剪（~）按精确裁剪执行
+// 键的过期时间、流消息的自动ID和待处理消息的空闲时间由可控时钟 Clock 驱动，阻塞命令的超时使用真实时间
+type Server struct {
+	mu       sync.Mutex // 保护键空间和订阅关系，命令逐条串行执行
+	store    *store
+	channels subscriptions // 频道订阅
+	patterns subscriptions // 模式订阅
+	clock    *Clock
+	scripts  map[string]*luaProto // 按 SHA1 缓存的脚本
+	listener net.Listener
+	closed   chan struct{}
+	connsMu  sync.Mutex
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+}
+
+// NewServer 在本地随机端口启动测试服务器，时钟从当前时间开始
+func NewServer() (*Server, error) {
+	return Listen("127.0.0.1:0")
+}
+
+// Listen 在指定地址启动测试服务器，时钟从当前时间开始
+// 可用于在同一地址重新启动服务器，模拟 Redis 故障恢复
+func Listen(addr string) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	clock := NewClock(time.Now())
+	s := &Server{
+		store:    newStore(clock),
+		channels: make(subscriptions),
+		patterns: make(subscriptions),
+		clock:    clock,
+		scripts:  make(map[string]*luaProto),
+		listener: listener,
+		closed:   make(chan struct{}),
+		conns:    make(map[net.Conn]struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+
+	return s, nil
+}
+
+// Addr 获取服务器监听地址（host:port）
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Clock 获取驱动键过期时间的时钟
+func (s *Server) Clock() *Clock {
+	return s.clock
+}
+
+// FlushAll 清空所有数据库
+func (s *Server) FlushAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store.flush()
+}
+
+// Close 关闭服务器及所有连接
+func (s *Server) Close() error {
+	select {
+	case <-s.closed:
+		return nil
+	default:
+	}
+	close(s.closed)
+	err := s.listener.Close()
+
+	s.connsMu.Lock()
+	for c := range s.conns {
+		_ = c.Close()
+	}
+	s.connsMu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+// serve 接受连接
+func (s *Server) serve() {
+	defer s.wg.Done()
+
+	for {
+		nc, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.connsMu.Lock()
+		s.conns[nc] = struct{}{}
+		s.connsMu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer func() {
+				s.connsMu.Lock()
+				delete(s.conns, nc)
+				s.connsMu.Unlock()
+				_ = nc.Close()
+			}()
+			newConn(s, nc).serve()
+		}()
+	}
+}
+
+// watchKey 被 WATCH 的键
+type watchKey struct {
+	db  int
+	key string
+}
+
+// conn 客户端连接
+type conn struct {
+	server   *Server
+	r        *bufio.Reader
+	w        *writer
+	dbIndex  int
+	multi    bool                // 处于 MULTI 中
+	dirty    bool                // MULTI 中有命令入队失败
+	queued   [][]string          // MULTI 中入队的命令
+	watched  map[watchKey]uint64 // WATCH 的键及其版本
+	inExec   bool                // 正在执行 EXEC，阻塞命令不等待
+	channels map[string]struct{} // 订阅的频道
+	patterns map[string]struct{} // 订阅的模式
+}
+
+// newConn 创建连接
+func newConn(s *Server, nc net.Conn) *conn {
+	return &conn{
+		server: s,
+		r:      bufio.NewReader(nc),
+		w:      &writer{w: bufio.NewWriter(nc)},
+	}
+}
+
+// serve 逐条读取并执行命令，连接关闭时取消所有订阅
+func (c *conn) serve() {
+	defer func() {
+		c.server.mu.Lock()
+		c.unsubscribeAll()
+		c.server.mu.Unlock()
+	}()
+
+	for {
+		args, err := readCommand(c.r)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				c.w.error(fmt.Errorf("ERR Protocol error: %v", err))
+				_ = c.w.flush()
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		quit := c.handle(args)
+		// 管道中的后续命令已在缓冲区时延迟刷新，减少系统调用
+		if c.r.Buffered() == 0 || quit {
+			if err := c.w.flush(); err != nil {
+				return
+			}
+		}
+		if quit {
+			return
+		}
+	}
+}
+
+// db 获取当前数据库
+func (c *conn) db() *db {
+	return c.server.store.dbs[c.dbIndex]
+}
+
+// handle 执行单条命令，返回是否关闭连接
+func (c *conn) handle(args []string) bool {
+	name := strings.ToLower(args[0])
+	cmd, ok := commands[name]
+	if !ok {
+		c.dirty = c.multi
+		c.w.error(fmt.Errorf("ERR unknown command '%s'", args[0]))
+		return false
+	}
+	if (cmd.arity > 0 && len(args) != cmd.arity) || (cmd.arity < 0 && len(args) < -cmd.arity) {
+		c.dirty = c.multi
+		c.w.error(fmt.Errorf("ERR wrong number of arguments for '%s' command", name))
+		return false
+	}
+
+	if c.subscribed() && !subscribeAllowed[name] {
+		c.w.error(subscribeModeError(name))
+		return false
+	}
+
+	if c.multi && !cmd.control {
+		c.queued = append(c.queued, args)
+		c.w.simple("QUEUED")
+		return false
+	}
+
+	c.server.mu.Lock()
+	defer c.server.mu.Unlock()
+
+	if err := cmd.fn(c, args); err != nil {
+		c.w.error(err)
+	}
+	return name == "quit"
+}
+
+// wait 释放锁等待列表或流写入或超时，调用方持有 server.mu；超时或服务器关闭时返回 false
+// deadline 为零值时一直等待
+func (c *conn) wait(deadline time.Time) bool {
+	if c.inExec {
+		return false
+	}
+
+	notify := c.server.store.notify
+	c.server.mu.Unlock()
+	defer c.server.mu.Lock()
+
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-notify:
+		return true
+	case <-timeout:
+		return false
+	case <-c.server.closed:
+		return false
+	}
+}
+
+// readCommand 读取 RESP 数组形式或内联形式的命令
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if line == "" {
+		return nil, nil
+	}
+	if line[0] != '*' {
+		return strings.Fields(line), nil
+	}
+
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid multibulk length")
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if line == "" || line[0] != '$' {
+			return nil, fmt.Errorf("expected '$', got '%s'", line)
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("invalid bulk length")
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+// readLine 读取一行（去除 \r\n）
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// writer RESP2 回复写入器
+// 回复在持有 server.mu 时写入；mu 保护刷新，使其他连接的 PUBLISH 可以向订阅连接推送消息
+type writer struct {
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+// flush 刷新缓冲区
+func (w *writer) flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Flush()
+}
+
+// push 写入推送消息并立即刷新（调用方持有 server.mu）
+func (w *writer) push(values []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.strings(values)
+	_ = w.w.Flush()
+}
+
+// ok 写入 +OK
+func (w *writer) ok() {
+	w.simple("OK")
+}
+
+// simple 写入简单字符串
+func (w *writer) simple(s string) {
+	w.w.WriteString("+" + s + "\r\n")
+}
+
+// error 写入错误，错误信息以错误类型开头（如 ERR、WRONGTYPE）
+func (w *writer) error(err error) {
+	w.w.WriteString("-" + strings.ReplaceAll(err.Error(), "\r\n", " ") + "\r\n")
+}
+
+// int 写入整数
+func (w *writer) int(n int64) {
+	w.w.WriteString(":" + strconv.FormatInt(n, 10) + "\r\n")
+}
+
+// bool 写入 0 或 1
+func (w *writer) bool(b bool) {
+	if b {
+		w.int(1)
+	} else {
+		w.int(0)
+	}
+}
+
+// bulk 写入批量字符串
+func (w *writer) bulk(s string) {
+	w.w.WriteString("$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n")
+}
+
+// null 写入空批量字符串
+func (w *writer) null() {
+	w.w.WriteString("$-1\r\n")
+}
+
+// nullArray 写入空数组
+func (w *writer) nullArray() {
+	w.w.WriteString("*-1\r\n")
+}
+
+// array 写入数组头
+func (w *writer) array(n int) {
+	w.w.WriteString("*" + strconv.Itoa(n) + "\r\n")
+}
+
+// strings 写入字符串数组
+func (w *writer) strings(values []string) {
+	w.array(len(values))
+	for _, v := range values {
+		w.bulk(v)
+	}
+}
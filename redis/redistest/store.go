@@ -0,0 +1,360 @@
+package redistest
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dbCount 数据库数量
+const dbCount = 16
+
+var (
+	errWrongType    = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+	errNotInteger   = errors.New("ERR value is not an integer or out of range")
+	errNotFloat     = errors.New("ERR value is not a valid float")
+	errSyntax       = errors.New("ERR syntax error")
+	errNoSuchKey    = errors.New("ERR no such key")
+	errOutOfRange   = errors.New("ERR index out of range")
+	errInvalidDB    = errors.New("ERR DB index is out of range")
+	errInvalidTTL   = errors.New("ERR invalid expire time")
+	errMinMaxFloat  = errors.New("ERR min or max is not a float")
+	errMinMaxLex    = errors.New("ERR min or max not valid string range item")
+	errBusyKey      = errors.New("BUSYKEY Target key name already exists.")
+	errBadDump      = errors.New("ERR DUMP payload version or checksum are wrong")
+	errNegativeTime = errors.New("ERR timeout is negative")
+)
+
+// kind 值类型
+type kind int
+
+const (
+	kindString kind = iota
+	kindList
+	kindSet
+	kindHash
+	kindZSet
+	kindStream
+)
+
+// String 返回 TYPE 命令使用的类型名称
+func (k kind) String() string {
+	switch k {
+	case kindList:
+		return "list"
+	case kindSet:
+		return "set"
+	case kindHash:
+		return "hash"
+	case kindZSet:
+		return "zset"
+	case kindStream:
+		return "stream"
+	default:
+		return "string"
+	}
+}
+
+// entry 键值
+type entry struct {
+	kind     kind
+	str      string
+	list     []string
+	set      map[string]struct{}
+	hash     map[string]string
+	zset     map[string]float64
+	stream   *stream
+	expireAt time.Time // 过期时间（零值为不过期）
+}
+
+// newEntry 创建指定类型的空值
+func newEntry(k kind) *entry {
+	e := &entry{kind: k}
+	switch k {
+	case kindSet:
+		e.set = make(map[string]struct{})
+	case kindHash:
+		e.hash = make(map[string]string)
+	case kindZSet:
+		e.zset = make(map[string]float64)
+	case kindStream:
+		e.stream = &stream{groups: make(map[string]*streamGroup)}
+	}
+	return e
+}
+
+// empty 判断容器类型的值是否为空（空容器会被删除，流与 Redis 一致不会因为为空而删除）
+func (e *entry) empty() bool {
+	switch e.kind {
+	case kindList:
+		return len(e.list) == 0
+	case kindSet:
+		return len(e.set) == 0
+	case kindHash:
+		return len(e.hash) == 0
+	case kindZSet:
+		return len(e.zset) == 0
+	default:
+		return false
+	}
+}
+
+// blocking 判断写入该类型的值时是否需要唤醒阻塞等待的连接
+func (e *entry) blocking() bool {
+	return e.kind == kindList || e.kind == kindStream
+}
+
+// db 单个数据库
+type db struct {
+	store    *store
+	entries  map[string]*entry
+	versions map[string]uint64 // 键的修改版本，用于 WATCH
+}
+
+// store 内存键空间，所有方法由调用方持有 server.mu
+type store struct {
+	clock   *Clock
+	dbs     []*db
+	version uint64        // 全局修改计数
+	notify  chan struct{} // 列表或流写入时关闭并替换，唤醒阻塞弹出和阻塞读取
+}
+
+// newStore 创建内存键空间
+func newStore(clock *Clock) *store {
+	s := &store{clock: clock, notify: make(chan struct{})}
+	s.dbs = make([]*db, dbCount)
+	for i := range s.dbs {
+		s.dbs[i] = &db{store: s, entries: make(map[string]*entry), versions: make(map[string]uint64)}
+	}
+	return s
+}
+
+// flush 清空所有数据库
+func (s *store) flush() {
+	for _, d := range s.dbs {
+		d.flush()
+	}
+}
+
+// wake 唤醒所有阻塞等待的连接
+func (s *store) wake() {
+	close(s.notify)
+	s.notify = make(chan struct{})
+}
+
+// flush 清空数据库
+func (d *db) flush() {
+	for key := range d.entries {
+		d.touch(key)
+	}
+	d.entries = make(map[string]*entry)
+}
+
+// touch 记录键被修改
+func (d *db) touch(key string) {
+	d.store.version++
+	d.versions[key] = d.store.version
+}
+
+// get 获取未过期的键值，过期的键被删除
+func (d *db) get(key string) *entry {
+	e, ok := d.entries[key]
+	if !ok {
+		return nil
+	}
+	if !e.expireAt.IsZero() && !e.expireAt.After(d.store.clock.Now()) {
+		delete(d.entries, key)
+		d.touch(key)
+		return nil
+	}
+	return e
+}
+
+// getKind 获取指定类型的键值，类型不匹配时返回 WRONGTYPE 错误，不存在时返回nil
+func (d *db) getKind(key string, k kind) (*entry, error) {
+	e := d.get(key)
+	if e != nil && e.kind != k {
+		return nil, errWrongType
+	}
+	return e, nil
+}
+
+// getOrCreate 获取指定类型的键值，不存在时创建
+func (d *db) getOrCreate(key string, k kind) (*entry, error) {
+	e, err := d.getKind(key, k)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		e = newEntry(k)
+		d.entries[key] = e
+	}
+	return e, nil
+}
+
+// set 设置键值并记录修改
+func (d *db) set(key string, e *entry) {
+	d.entries[key] = e
+	d.touch(key)
+	if e.blocking() {
+		d.store.wake()
+	}
+}
+
+// remove 删除键，返回键是否存在
+func (d *db) remove(key string) bool {
+	if d.get(key) == nil {
+		return false
+	}
+	delete(d.entries, key)
+	d.touch(key)
+	return true
+}
+
+// modified 记录键被原地修改，空容器被删除
+func (d *db) modified(key string) {
+	if e, ok := d.entries[key]; ok {
+		if e.empty() {
+			delete(d.entries, key)
+		} else if e.blocking() {
+			d.store.wake()
+		}
+	}
+	d.touch(key)
+}
+
+// keys 获取所有未过期的键（已排序）
+func (d *db) keys() []string {
+	keys := make([]string, 0, len(d.entries))
+	for key := range d.entries {
+		if d.get(key) != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatFloat 按 Redis 格式输出浮点数
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "inf"
+	case math.IsInf(v, -1):
+		return "-inf"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// parseInt 解析整数参数
+func parseInt(s string) (int64, error) {
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, errNotInteger
+	}
+	return v, nil
+}
+
+// parseFloat 解析浮点数参数，支持 inf、+inf、-inf
+func parseFloat(s string) (float64, error) {
+	switch strings.ToLower(s) {
+	case "inf", "+inf":
+		s = "+Inf"
+	case "-inf":
+		s = "-Inf"
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, errNotFloat
+	}
+	return v, nil
+}
+
+// normalizeRange 将可为负数的起止下标转换为 [start, stop] 闭区间，区间为空时返回 false
+func normalizeRange(start, stop, length int64) (int64, int64, bool) {
+	if start < 0 {
+		start += length
+	}
+	if stop < 0 {
+		stop += length
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= length {
+		stop = length - 1
+	}
+	if start > stop || start >= length {
+		return 0, 0, false
+	}
+	return start, stop, true
+}
+
+// globMatch 按 Redis 匹配规则判断字符串是否匹配模式，支持 *、?、[...] 和 \ 转义
+func globMatch(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			pattern = strings.TrimLeft(pattern, "*")
+			if pattern == "" {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if globMatch(pattern, s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if s == "" {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+		case '[':
+			end := strings.IndexByte(pattern[1:], ']')
+			if end < 0 || s == "" {
+				return false
+			}
+			class := pattern[1 : end+1]
+			negate := strings.HasPrefix(class, "^")
+			if negate {
+				class = class[1:]
+			}
+			if classMatch(class, s[0]) == negate {
+				return false
+			}
+			pattern, s = pattern[end+2:], s[1:]
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if s == "" || s[0] != pattern[0] {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+		}
+	}
+	return s == ""
+}
+
+// classMatch 判断字符是否属于字符类（支持 a-z 区间）
+func classMatch(class string, c byte) bool {
+	for i := 0; i < len(class); i++ {
+		if i+2 < len(class) && class[i+1] == '-' {
+			if class[i] <= c && c <= class[i+2] {
+				return true
+			}
+			i += 2
+			continue
+		}
+		if class[i] == c {
+			return true
+		}
+	}
+	return false
+}
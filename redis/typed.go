@@ -0,0 +1,238 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ElementError 批量结果中单个元素的反序列化错误
+type ElementError struct {
+	Index int    // 元素下标（哈希表结果为-1）
+	Field string // 哈希表字段或键名（列表结果为空）
+	Err   error  // 原始错误
+}
+
+// Error 返回错误信息
+func (e *ElementError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("元素[%s]反序列化失败: %v", e.Field, e.Err)
+	}
+	return fmt.Sprintf("元素[%d]反序列化失败: %v", e.Index, e.Err)
+}
+
+// Unwrap 返回原始错误
+func (e *ElementError) Unwrap() error {
+	return e.Err
+}
+
+// ZMember 有序集合成员及其分数
+type ZMember[T any] struct {
+	Member T
+	Score  float64
+}
+
+// GetAs 获取键值并反序列化为T
+// 键不存在时返回 ErrNotFound
+func GetAs[T any](ctx context.Context, c *Client, key string) (T, error) {
+	var zero T
+
+	data, err := wrapResult(c.UniversalClient.Get(ctx, c.key(key)).Result())
+	if err != nil {
+		return zero, err
+	}
+
+	return decodeAs[T](c, data)
+}
+
+// MGetAs 批量获取键值并反序列化为T，结果以键名索引，不存在的键不包含在结果中
+// 反序列化失败的键记录为 ElementError，通过 errors.Join 合并返回，成功的键仍包含在结果中
+func MGetAs[T any](ctx context.Context, c *Client, keys ...string) (map[string]T, error) {
+	values, err := c.UniversalClient.MGet(ctx, c.keys(keys)...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]T, len(values))
+	var errs []error
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+
+		str, ok := value.(string)
+		if !ok {
+			errs = append(errs, &ElementError{Index: i, Field: keys[i], Err: fmt.Errorf("不支持的值类型: %T", value)})
+			continue
+		}
+
+		item, err := decodeAs[T](c, str)
+		if err != nil {
+			errs = append(errs, &ElementError{Index: i, Field: keys[i], Err: err})
+			continue
+		}
+		results[keys[i]] = item
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// LRangeAs 获取列表中指定范围的元素并反序列化为T切片
+// 反序列化失败的元素位置保留零值，并记录为 ElementError 通过 errors.Join 合并返回
+func LRangeAs[T any](ctx context.Context, c *Client, key string, start, end int64) ([]T, error) {
+	values, err := c.UniversalClient.LRange(ctx, c.key(key), start, end).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeSliceAs[T](c, values)
+}
+
+// HGetAllAs 获取哈希表中所有字段并反序列化为以字段名索引的T映射
+// 反序列化失败的字段记录为 ElementError，通过 errors.Join 合并返回，成功的字段仍包含在结果中
+func HGetAllAs[T any](ctx context.Context, c *Client, key string) (map[string]T, error) {
+	values, err := c.UniversalClient.HGetAll(ctx, c.key(key)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]T, len(values))
+	var errs []error
+	for field, value := range values {
+		item, err := decodeAs[T](c, value)
+		if err != nil {
+			errs = append(errs, &ElementError{Index: -1, Field: field, Err: err})
+			continue
+		}
+		results[field] = item
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// ZRangeWithScoresAs 获取有序集合中指定范围的成员及其分数，成员反序列化为T
+// 反序列化失败的成员位置保留零值，并记录为 ElementError 通过 errors.Join 合并返回
+func ZRangeWithScoresAs[T any](ctx context.Context, c *Client, key string, start, end int64) ([]ZMember[T], error) {
+	values, err := c.UniversalClient.ZRangeWithScores(ctx, c.key(key), start, end).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeZMembersAs[T](c, values)
+}
+
+// ZRevRangeWithScoresAs 获取有序集合中指定范围的成员及其分数（按分数从高到低排序），成员反序列化为T
+// 反序列化失败的成员位置保留零值，并记录为 ElementError 通过 errors.Join 合并返回
+func ZRevRangeWithScoresAs[T any](ctx context.Context, c *Client, key string, start, end int64) ([]ZMember[T], error) {
+	values, err := c.UniversalClient.ZRevRangeWithScores(ctx, c.key(key), start, end).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeZMembersAs[T](c, values)
+}
+
+// SMembersAs 获取集合所有成员并反序列化为T切片
+// 反序列化失败的成员位置保留零值，并记录为 ElementError 通过 errors.Join 合并返回
+func SMembersAs[T any](ctx context.Context, c *Client, key string) ([]T, error) {
+	values, err := c.UniversalClient.SMembers(ctx, c.key(key)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeSliceAs[T](c, values)
+}
+
+// decodeSliceAs 反序列化字符串切片，失败的元素保留零值
+func decodeSliceAs[T any](c *Client, values []string) ([]T, error) {
+	results := make([]T, len(values))
+	var errs []error
+	for i, value := range values {
+		item, err := decodeAs[T](c, value)
+		if err != nil {
+			errs = append(errs, &ElementError{Index: i, Err: err})
+			continue
+		}
+		results[i] = item
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// decodeZMembersAs 反序列化有序集合成员，失败的成员保留零值
+func decodeZMembersAs[T any](c *Client, values []redis.Z) ([]ZMember[T], error) {
+	results := make([]ZMember[T], len(values))
+	var errs []error
+	for i, value := range values {
+		results[i].Score = value.Score
+
+		str, ok := value.Member.(string)
+		if !ok {
+			errs = append(errs, &ElementError{Index: i, Err: fmt.Errorf("不支持的成员类型: %T", value.Member)})
+			continue
+		}
+
+		item, err := decodeAs[T](c, str)
+		if err != nil {
+			errs = append(errs, &ElementError{Index: i, Err: err})
+			continue
+		}
+		results[i].Member = item
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// decodeAs 将字符串反序列化为T
+// T 为 string 或 []byte 时直接返回原始数据；T 为指针类型时自动分配内存
+func decodeAs[T any](c *Client, data string) (T, error) {
+	var result T
+
+	switch v := any(&result).(type) {
+	case *string:
+		*v = data
+		return result, nil
+	case *[]byte:
+		*v = []byte(data)
+		return result, nil
+	}
+
+	if data == "" {
+		return result, ErrEmptyValue
+	}
+
+	rt := reflect.TypeOf(result)
+	if rt != nil && rt.Kind() == reflect.Ptr {
+		elem := reflect.New(rt.Elem())
+		if err := c.decode([]byte(data), elem.Interface()); err != nil {
+			return result, err
+		}
+		return elem.Interface().(T), nil
+	}
+
+	if err := c.decode([]byte(data), &result); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// encodeAs 将T编码为字符串，与 decodeAs 对应
+// T 为 string 或 []byte 时直接使用原始数据，其他类型使用客户端序列化器编码
+func encodeAs[T any](c *Client, value T) (string, error) {
+	switch v := any(value).(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	}
+
+	data, err := c.marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("序列化失败: %w", err)
+	}
+	return string(data), nil
+}